@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/storrealbac/mpm/internal/jar"
+	"github.com/storrealbac/mpm/internal/utils"
+)
+
+// paperBasedServers can load paper-plugin.yml plugins
+var paperBasedServers = []string{"paper", "folia", "purpur", "pufferfish", "leaf", "leaves", "canvas"}
+
+// checkCompatibility returns why an installed plugin can't load on the server:
+// an api-version newer than the Minecraft version, a paper-plugin.yml on a
+// non-Paper server, or a hard dependency that no other installed jar provides
+func checkCompatibility(desc *jar.Descriptor, serverType, minecraftVersion string, jars []installedJar) []string {
+	var problems []string
+	serverType = strings.ToLower(serverType)
+
+	if desc.Source == "paper-plugin.yml" {
+		if serverType != "" && !containsString(paperBasedServers, serverType) {
+			problems = append(problems, fmt.Sprintf("paper-plugin.yml requires a Paper based server, not %s", serverType))
+		}
+		if desc.APIVersion == "" {
+			problems = append(problems, "paper-plugin.yml does not declare the required api-version")
+		}
+	}
+
+	if desc.APIVersion != "" && minecraftVersion != "" && utils.CompareVersions(desc.APIVersion, minecraftVersion) > 0 {
+		problems = append(problems, fmt.Sprintf("requires api-version %s, server is %s", desc.APIVersion, minecraftVersion))
+	}
+
+	for _, dep := range desc.Depend {
+		if !dependencyInstalled(dep, desc, jars) {
+			problems = append(problems, fmt.Sprintf("missing dependency %s", dep))
+		}
+	}
+
+	return problems
+}
+
+// dependencyInstalled reports whether another jar declares or provides the dependency
+func dependencyInstalled(dep string, self *jar.Descriptor, jars []installedJar) bool {
+	for _, installed := range jars {
+		other := installed.Descriptor
+		if other == nil || other == self {
+			continue
+		}
+		if strings.EqualFold(other.Name, dep) || strings.EqualFold(other.ID, dep) {
+			return true
+		}
+		for _, provided := range other.Provides {
+			if strings.EqualFold(provided, dep) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/storrealbac/mpm/internal/jar"
+)
+
+func TestCheckCompatibility(t *testing.T) {
+	vault := &jar.Descriptor{Name: "Vault", Source: "plugin.yml"}
+	provider := &jar.Descriptor{Name: "VaultUnlocked", Provides: []string{"Vault"}, Source: "plugin.yml"}
+	proxyLib := &jar.Descriptor{ID: "protocolize", Name: "Protocolize", Source: "velocity-plugin.json"}
+
+	tests := []struct {
+		name       string
+		desc       *jar.Descriptor
+		serverType string
+		mcVersion  string
+		jars       []*jar.Descriptor
+		want       []string
+	}{
+		{
+			name:       "compatible",
+			desc:       &jar.Descriptor{Name: "Shop", APIVersion: "1.20", Depend: []string{"Vault"}, Source: "plugin.yml"},
+			serverType: "paper",
+			mcVersion:  "1.20.4",
+			jars:       []*jar.Descriptor{vault},
+		},
+		{
+			name:       "api-version newer than the server",
+			desc:       &jar.Descriptor{Name: "Shop", APIVersion: "1.21", Source: "plugin.yml"},
+			serverType: "paper",
+			mcVersion:  "1.20.4",
+			want:       []string{"requires api-version 1.21, server is 1.20.4"},
+		},
+		{
+			name:      "unknown server version isn't checked",
+			desc:      &jar.Descriptor{Name: "Shop", APIVersion: "1.21", Source: "plugin.yml"},
+			mcVersion: "",
+		},
+		{
+			name:       "missing dependency",
+			desc:       &jar.Descriptor{Name: "Shop", Depend: []string{"Vault", "Citizens"}, Source: "plugin.yml"},
+			serverType: "paper",
+			jars:       []*jar.Descriptor{vault},
+			want:       []string{"missing dependency Citizens"},
+		},
+		{
+			name: "dependency provided by another jar",
+			desc: &jar.Descriptor{Name: "Shop", Depend: []string{"vault"}, Source: "plugin.yml"},
+			jars: []*jar.Descriptor{provider},
+		},
+		{
+			name: "velocity dependency by id",
+			desc: &jar.Descriptor{ID: "tab", Name: "TAB", Depend: []string{"protocolize"}, Source: "velocity-plugin.json"},
+			jars: []*jar.Descriptor{proxyLib},
+		},
+		{
+			name:       "paper plugin on spigot",
+			desc:       &jar.Descriptor{Name: "Modern", APIVersion: "1.20", Source: "paper-plugin.yml"},
+			serverType: "Spigot",
+			mcVersion:  "1.20.4",
+			want:       []string{"paper-plugin.yml requires a Paper based server, not spigot"},
+		},
+		{
+			name:       "paper plugin without api-version",
+			desc:       &jar.Descriptor{Name: "Modern", Source: "paper-plugin.yml"},
+			serverType: "purpur",
+			want:       []string{"paper-plugin.yml does not declare the required api-version"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jars := []installedJar{{File: "self.jar", Descriptor: tt.desc}}
+			for _, desc := range tt.jars {
+				jars = append(jars, installedJar{File: desc.Name + ".jar", Descriptor: desc})
+			}
+
+			got := checkCompatibility(tt.desc, tt.serverType, tt.mcVersion, jars)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("checkCompatibility() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// writePluginJar creates a jar named file in dir holding files
+func writePluginJar(t *testing.T, dir, file string, files map[string]string) {
+	t.Helper()
+	out, err := os.Create(filepath.Join(dir, file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+
+	w := zip.NewWriter(out)
+	for name, content := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckCompatibilityDualDescriptors(t *testing.T) {
+	dir := t.TempDir()
+	writePluginJar(t, dir, "modern.jar", map[string]string{
+		"paper-plugin.yml": "name: Modern\nversion: 2.0\nmain: a.Paper\napi-version: '1.21'\n",
+		"plugin.yml":       "name: Modern\nversion: 2.0\nmain: a.Bukkit\napi-version: '1.13'\n",
+	})
+	writePluginJar(t, dir, "universal.jar", map[string]string{
+		"plugin.yml":           "name: Universal\nversion: 1.0\nmain: a.Bukkit\napi-version: '1.21'\ndepend: [Vault]\n",
+		"bungee.yml":           "name: Universal\nversion: 1.0\nmain: a.Bungee\n",
+		"velocity-plugin.json": `{"id": "universal", "version": "1.0", "main": "a.Velocity"}`,
+	})
+
+	tests := []struct {
+		serverType string
+		mcVersion  string
+		want       map[string][]string // problems by jar, none when absent
+	}{
+		{
+			serverType: "paper",
+			mcVersion:  "1.20.4",
+			want: map[string][]string{
+				"modern.jar":    {"requires api-version 1.21, server is 1.20.4"},
+				"universal.jar": {"requires api-version 1.21, server is 1.20.4", "missing dependency Vault"},
+			},
+		},
+		{
+			serverType: "spigot",
+			mcVersion:  "1.20.4",
+			want: map[string][]string{
+				"universal.jar": {"requires api-version 1.21, server is 1.20.4", "missing dependency Vault"},
+			},
+		},
+		{
+			serverType: "velocity",
+			want: map[string][]string{
+				"modern.jar": {"paper-plugin.yml requires a Paper based server, not velocity"},
+			},
+		},
+		{serverType: "bungeecord"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.serverType, func(t *testing.T) {
+			jars := scanPluginsDir(dir, tt.serverType)
+			for _, installed := range jars {
+				if installed.Descriptor == nil {
+					t.Fatalf("%s: %v", installed.File, installed.Err)
+				}
+				got := checkCompatibility(installed.Descriptor, tt.serverType, tt.mcVersion, jars)
+				if !reflect.DeepEqual(got, tt.want[installed.File]) {
+					t.Errorf("%s (%s) = %q, want %q", installed.File, installed.Descriptor.Source, got, tt.want[installed.File])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/storrealbac/mpm/internal/models"
+	"github.com/storrealbac/mpm/internal/server"
+	"github.com/storrealbac/mpm/internal/ui"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the server's configuration files",
+	Long: `Manage the server configuration declared in package.yml.
+
+The properties map in package.yml is merged into server.properties before
+mpm serve and mpm server bootstrap start the server.`,
+}
+
+var configDiffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Show where server.properties differs from package.yml",
+	Long: `Compares every key of the properties map in package.yml with its value in
+server.properties and lists the ones that differ or are missing. The next
+mpm serve writes the declared values.
+
+Exits with an error when any value differs, so it can be used in CI.`,
+	Args: cobra.NoArgs,
+	RunE: runConfigDiff,
+}
+
+func init() {
+	configCmd.AddCommand(configDiffCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+// propertyResult is a declared property compared with server.properties
+type propertyResult struct {
+	Key      string `json:"key" yaml:"key"`
+	Declared string `json:"declared" yaml:"declared"`
+	OnDisk   string `json:"on_disk,omitempty" yaml:"on_disk,omitempty"`
+	Status   string `json:"status" yaml:"status"` // ok, changed, missing or invalid
+	Error    string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// configDiffResult is the structured output of mpm config diff
+type configDiffResult struct {
+	Command    string           `json:"command" yaml:"command"`
+	Success    bool             `json:"success" yaml:"success"`
+	Properties []propertyResult `json:"properties" yaml:"properties"`
+	Errors     []string         `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+func runConfigDiff(cmd *cobra.Command, args []string) error {
+	pkg, err := models.LoadPackageFromFile("package.yml")
+	if err != nil {
+		return fmt.Errorf("could not read package.yml: %w", err)
+	}
+
+	result := configDiffResult{Command: "config diff", Success: true, Properties: []propertyResult{}}
+
+	// Proxies have no server.properties to compare with
+	if pkg.Server.IsProxy() {
+		if ui.IsStructured() {
+			return ui.PrintResult(result)
+		}
+		if len(pkg.Properties) > 0 {
+			ui.PrintWarning("%s has no server.properties, properties are ignored.", pkg.Server.Type)
+		} else {
+			ui.PrintInfo("%s has no server.properties.", pkg.Server.Type)
+		}
+		return nil
+	}
+
+	props, err := server.LoadProperties(server.PropertiesFile)
+	if err != nil {
+		return err
+	}
+
+	table := ui.NewTable("KEY", "PACKAGE.YML", server.PropertiesFile, "STATUS")
+	drift := 0
+
+	for _, key := range propertyKeys(pkg.Properties) {
+		entry := propertyResult{Key: key, Declared: pkg.Properties[key], Status: "ok"}
+		onDisk, found := props.Get(key)
+		entry.OnDisk = onDisk
+
+		switch {
+		case server.CheckProperty(key, entry.Declared) != "":
+			entry.Status = "invalid"
+			entry.Error = server.CheckProperty(key, entry.Declared)
+		case !found:
+			entry.Status = "missing"
+		case onDisk != entry.Declared:
+			entry.Status = "changed"
+		}
+		if entry.Status != "ok" {
+			drift++
+		}
+
+		shown := onDisk
+		if !found {
+			shown = "-"
+		}
+		table.AddRow(key, entry.Declared, shown, ui.CreateStatusBadge(entry.Status))
+		result.Properties = append(result.Properties, entry)
+	}
+
+	if ui.IsStructured() {
+		result.Success = drift == 0
+		for _, entry := range result.Properties {
+			if entry.Error != "" {
+				result.Errors = append(result.Errors, entry.Error)
+			}
+		}
+		if err := ui.PrintResult(result); err != nil {
+			return err
+		}
+		if drift > 0 {
+			return fmt.Errorf("%d properties differ from package.yml", drift)
+		}
+		return nil
+	}
+
+	if len(pkg.Properties) == 0 {
+		ui.PrintInfo("No properties in package.yml.")
+		return nil
+	}
+
+	fmt.Fprintln(ui.Out, table.Render())
+	fmt.Fprintln(ui.Out)
+
+	if drift > 0 {
+		invalid := 0
+		for _, entry := range result.Properties {
+			if entry.Error != "" {
+				ui.PrintError("%s", entry.Error)
+				invalid++
+			}
+		}
+		if invalid > 0 {
+			ui.PrintInfo("Fix the invalid values in package.yml, then run 'mpm serve' to write them.")
+		} else {
+			ui.PrintInfo("Run 'mpm serve' to write the declared values.")
+		}
+		return fmt.Errorf("%d properties differ from package.yml", drift)
+	}
+	ui.PrintSuccess("%s matches package.yml.", server.PropertiesFile)
+	return nil
+}
+
+// propertyKeys returns the keys of the properties map in a stable order
+func propertyKeys(properties map[string]string) []string {
+	keys := make([]string, 0, len(properties))
+	for key := range properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// propertyProblems lists the invalid values in the properties map
+func propertyProblems(properties map[string]string) []string {
+	var problems []string
+	for _, key := range propertyKeys(properties) {
+		if problem := server.CheckProperty(key, properties[key]); problem != "" {
+			problems = append(problems, problem)
+		}
+	}
+	return problems
+}
+
+// applyProperties merges the properties map of package.yml into server.properties.
+// Keys that aren't declared, and comments, are left as they are.
+func applyProperties(pkg *models.Package) error {
+	if len(pkg.Properties) == 0 {
+		return nil
+	}
+	if pkg.Server.IsProxy() {
+		ui.PrintWarning("%s has no server.properties, properties are ignored", pkg.Server.Type)
+		return nil
+	}
+	if problems := propertyProblems(pkg.Properties); len(problems) > 0 {
+		return fmt.Errorf("invalid properties in package.yml: %s", strings.Join(problems, "; "))
+	}
+
+	props, err := server.LoadProperties(server.PropertiesFile)
+	if err != nil {
+		return err
+	}
+	changed := 0
+	for _, key := range propertyKeys(pkg.Properties) {
+		if props.Set(key, pkg.Properties[key]) {
+			changed++
+		}
+	}
+	if changed == 0 {
+		return nil
+	}
+
+	if err := props.Save(server.PropertiesFile); err != nil {
+		return err
+	}
+	ui.PrintInfo("Updated %d value(s) in %s from package.yml", changed, server.PropertiesFile)
+	return nil
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/storrealbac/mpm/internal/models"
+	"github.com/storrealbac/mpm/internal/server"
+)
+
+func TestApplyProperties(t *testing.T) {
+	existing := "#Minecraft server properties\nmotd=A Minecraft Server\npvp=true\n"
+
+	tests := []struct {
+		name       string
+		serverType string
+		properties map[string]string
+		onDisk     string // "" for no server.properties
+		want       string // server.properties afterwards, "" if it must not exist
+		err        string
+	}{
+		{
+			name:       "merges into the existing file",
+			serverType: "paper",
+			properties: map[string]string{"pvp": "false", "max-players": "50"},
+			onDisk:     existing,
+			want:       "#Minecraft server properties\nmotd=A Minecraft Server\npvp=false\nmax-players=50\n",
+		},
+		{
+			name:       "creates the file before the first boot",
+			serverType: "paper",
+			properties: map[string]string{"server-port": "25570"},
+			want:       "server-port=25570\n",
+		},
+		{
+			name:       "nothing declared leaves the file alone",
+			serverType: "paper",
+			onDisk:     existing,
+			want:       existing,
+		},
+		{
+			name:       "invalid values are refused",
+			serverType: "paper",
+			properties: map[string]string{"pvp": "maybe", "server-port": "25570"},
+			onDisk:     existing,
+			want:       existing,
+			err:        "pvp must be true or false",
+		},
+		{
+			name:       "proxies are skipped",
+			serverType: "velocity",
+			properties: map[string]string{"pvp": "false"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chdirTemp(t)
+			if tt.onDisk != "" {
+				writeFiles(t, map[string]string{server.PropertiesFile: tt.onDisk})
+			}
+
+			pkg := &models.Package{Server: models.ServerConfig{Type: tt.serverType}, Properties: tt.properties}
+			err := applyProperties(pkg)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("err = %v, want %q", err, tt.err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := os.ReadFile(server.PropertiesFile)
+			if tt.want == "" {
+				if err == nil {
+					t.Errorf("%s was written: %q", server.PropertiesFile, data)
+				}
+				return
+			}
+			if string(data) != tt.want {
+				t.Errorf("%s = %q, want %q", server.PropertiesFile, data, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunConfigDiff(t *testing.T) {
+	tests := []struct {
+		name    string
+		pkg     string
+		onDisk  string
+		wantErr bool
+	}{
+		{
+			name:   "in sync",
+			pkg:    "server:\n  type: paper\nproperties:\n  pvp: \"false\"\n",
+			onDisk: "pvp=false\n",
+		},
+		{
+			name:    "drift fails",
+			pkg:     "server:\n  type: paper\nproperties:\n  pvp: \"false\"\n  max-players: \"50\"\n",
+			onDisk:  "pvp=true\n",
+			wantErr: true,
+		},
+		{
+			name: "proxies have nothing to compare",
+			pkg:  "server:\n  type: velocity\nproperties:\n  pvp: \"false\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chdirTemp(t)
+			files := map[string]string{"package.yml": "name: test\nversion: 1.0.0\n" + tt.pkg}
+			if tt.onDisk != "" {
+				files[server.PropertiesFile] = tt.onDisk
+			}
+			writeFiles(t, files)
+
+			err := runConfigDiff(configDiffCmd, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/storrealbac/mpm/internal/jar"
+	"github.com/storrealbac/mpm/internal/models"
+	"github.com/storrealbac/mpm/internal/utils"
+)
+
+// pluginKey returns the package-lock key of a package.yml entry
+func pluginKey(p models.Plugin) string {
+	_, key := pluginSourceAndID(p)
+	return key
+}
+
+// pluginSlug returns the project slug of an entry (Hangar IDs are owner/slug)
+func pluginSlug(p models.Plugin) string {
+	if p.ModrinthID != "" {
+		return p.ModrinthID
+	}
+	if _, slug, ok := strings.Cut(p.HangarID, "/"); ok {
+		return slug
+	}
+	if p.HangarID == "" {
+		return "" // Local jars have no slug
+	}
+	return p.HangarID
+}
+
+// findDuplicateEntry returns the entry in plugins that is the same plugin as candidate
+// under a different ID (matching slug or name), and why they match
+func findDuplicateEntry(plugins []models.Plugin, candidate models.Plugin) (*models.Plugin, string) {
+	candidateKey := pluginKey(candidate)
+	candidateSlug := utils.NormalizeName(pluginSlug(candidate))
+	candidateName := utils.NormalizeName(candidate.Name)
+
+	for i := range plugins {
+		existing := &plugins[i]
+		if pluginKey(*existing) == candidateKey {
+			continue // Same entry, not a duplicate
+		}
+		if candidateSlug != "" && utils.NormalizeName(pluginSlug(*existing)) == candidateSlug {
+			return existing, "same slug"
+		}
+		if candidateName != "" && utils.NormalizeName(existing.Name) == candidateName {
+			return existing, "same name"
+		}
+	}
+
+	return nil, ""
+}
+
+// duplicateEntries reports package.yml entries that are the same plugin,
+// by slug, name, or (when installed) the jar's declared name or contents
+func duplicateEntries(plugins []models.Plugin, lockFile *models.PackageLock, dir string) []string {
+	var problems []string
+	index := newInstalledJars(dir, lockFile)
+
+	for i, plugin := range plugins {
+		if dup, reason := findDuplicateEntry(plugins[:i], plugin); dup != nil {
+			problems = append(problems, fmt.Sprintf("%s (%s) duplicates %s (%s): %s", plugin.Name, pluginKey(plugin), dup.Name, pluginKey(*dup), reason))
+			continue
+		}
+
+		file := lockFile.Plugins[pluginKey(plugin)].File
+		if file == "" {
+			continue
+		}
+		for _, other := range plugins[:i] {
+			otherFile := lockFile.Plugins[pluginKey(other)].File
+			if otherFile == "" {
+				continue
+			}
+			if reason := index.sameJar(file, otherFile); reason != "" {
+				problems = append(problems, fmt.Sprintf("%s (%s) duplicates %s (%s): %s", plugin.Name, pluginKey(plugin), other.Name, pluginKey(other), reason))
+				break
+			}
+		}
+	}
+
+	return problems
+}
+
+// installedJars tracks which jar in the plugins directory belongs to which
+// package-lock entry, so a second copy of an already managed plugin is refused
+type installedJars struct {
+	mu     sync.Mutex
+	dir    string
+	files  map[string]string // lock key -> jar filename
+	names  map[string]string // jar filename -> declared plugin name (cache)
+	hashes map[string]string // jar filename -> SHA256 (cache)
+}
+
+// jarIndex guards the plugins directory during installs (nil disables the check)
+var jarIndex *installedJars
+
+func newInstalledJars(dir string, lockFile *models.PackageLock) *installedJars {
+	j := &installedJars{
+		dir:    dir,
+		files:  make(map[string]string),
+		names:  make(map[string]string),
+		hashes: make(map[string]string),
+	}
+	for key, entry := range lockFile.Plugins {
+		if entry.File != "" {
+			j.files[key] = entry.File
+		}
+	}
+	return j
+}
+
+// claim checks that the jar at candidatePath, about to be saved as fileName for lockKey,
+// isn't a plugin already installed by another entry, then records it
+func (j *installedJars) claim(lockKey, candidatePath, fileName string) error {
+	if j == nil {
+		return nil
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	// Cache the candidate's identity under its final name; it isn't on disk there yet
+	j.hashes[fileName] = hashFile(candidatePath, sha256.New)
+	if desc, err := jar.Read(candidatePath); err == nil {
+		j.names[fileName] = desc.Name
+	} else {
+		j.names[fileName] = ""
+	}
+
+	for otherKey, otherFile := range j.files {
+		if otherKey == lockKey {
+			continue
+		}
+		if otherFile == fileName {
+			return fmt.Errorf("%s is already installed by %s", fileName, otherKey)
+		}
+		if reason := j.sameJar(fileName, otherFile); reason != "" {
+			return fmt.Errorf("refusing to install duplicate plugin: %s is the same plugin as %s installed by %s (%s)", fileName, otherFile, otherKey, reason)
+		}
+	}
+
+	j.files[lockKey] = fileName
+	return nil
+}
+
+// sameJar compares two jars in the plugins directory and returns why they are
+// the same plugin, or "" if they aren't
+func (j *installedJars) sameJar(fileA, fileB string) string {
+	hashA, hashB := j.hash(fileA), j.hash(fileB)
+	if hashA != "" && hashA == hashB {
+		return "identical file hash"
+	}
+
+	nameA, nameB := j.declaredName(fileA), j.declaredName(fileB)
+	if nameA != "" && strings.EqualFold(nameA, nameB) {
+		return fmt.Sprintf("both declare plugin name '%s'", nameA)
+	}
+
+	return ""
+}
+
+func (j *installedJars) hash(file string) string {
+	if h, ok := j.hashes[file]; ok {
+		return h
+	}
+	h := hashFile(filepath.Join(j.dir, file), sha256.New)
+	j.hashes[file] = h
+	return h
+}
+
+func (j *installedJars) declaredName(file string) string {
+	if name, ok := j.names[file]; ok {
+		return name
+	}
+	name := ""
+	if desc, err := jar.Read(filepath.Join(j.dir, file)); err == nil {
+		name = desc.Name
+	}
+	j.names[file] = name
+	return name
+}
+
+// hashFile returns the hex digest of a file, or "" if it can't be read
+func hashFile(path string, newHash func() hash.Hash) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	hasher := newHash()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+// findInstalledDuplicate returns the package.yml entry that is the same plugin as
+// a search hit, including the copies the hit was merged with from other sources
+func findInstalledDuplicate(pkg *models.Package, hit searchHit) (*models.Plugin, string) {
+	candidate := models.Plugin{Name: hit.Name}
+	if hit.Source == "hangar" {
+		candidate.HangarID = hit.ID
+	} else {
+		candidate.ModrinthID = hit.ID
+	}
+	if dup, reason := findDuplicateEntry(pkg.Plugins, candidate); dup != nil {
+		return dup, reason
+	}
+
+	for _, other := range hit.AlsoOn {
+		source, id, _ := strings.Cut(other, ":")
+		for i := range pkg.Plugins {
+			existing := &pkg.Plugins[i]
+			if (source == "modrinth" && existing.ModrinthID == id) || (source == "hangar" && existing.HangarID == id) {
+				return existing, "same project on " + source
+			}
+		}
+	}
+
+	return nil, ""
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/storrealbac/mpm/internal/models"
+)
+
+func TestInstalledJarsClaim(t *testing.T) {
+	luckPerms := map[string]string{"plugin.yml": "name: LuckPerms\nversion: 5.4\nmain: a.LuckPerms\n"}
+	luckPermsNext := map[string]string{"plugin.yml": "name: LuckPerms\nversion: 5.5\nmain: a.LuckPerms\n"}
+	viaVersion := map[string]string{"plugin.yml": "name: ViaVersion\nversion: 5.0\nmain: a.ViaVersion\n"}
+
+	type claim struct {
+		key, file string
+		jar       map[string]string // candidate contents, nil for a copy of the installed LuckPerms jar
+		err       string            // substring of the error, "" if the claim succeeds
+	}
+	tests := []struct {
+		name   string
+		claims []claim
+		files  map[string]string // lock key -> jar afterwards
+	}{
+		{
+			name:   "same plugin under a new filename",
+			claims: []claim{{key: "LuckPerms/LuckPerms", file: "LuckPerms-Bukkit-5.5.jar", jar: luckPermsNext, err: "both declare plugin name 'LuckPerms'"}},
+			files:  map[string]string{"luckperms": "LuckPerms-5.4.jar"},
+		},
+		{
+			name:   "identical file under a new filename",
+			claims: []claim{{key: "LuckPerms/LuckPerms", file: "lp.jar", err: "identical file hash"}},
+			files:  map[string]string{"luckperms": "LuckPerms-5.4.jar"},
+		},
+		{
+			name:   "same filename from a different source",
+			claims: []claim{{key: "LuckPerms/LuckPerms", file: "LuckPerms-5.4.jar", jar: viaVersion, err: "LuckPerms-5.4.jar is already installed by luckperms"}},
+			files:  map[string]string{"luckperms": "LuckPerms-5.4.jar"},
+		},
+		{
+			name:   "a new version of a claimed key",
+			claims: []claim{{key: "luckperms", file: "LuckPerms-5.5.jar", jar: luckPermsNext}},
+			files:  map[string]string{"luckperms": "LuckPerms-5.5.jar"},
+		},
+		{
+			name: "re-claiming a key claimed in the same run",
+			claims: []claim{
+				{key: "viaversion", file: "ViaVersion-5.0.jar", jar: viaVersion},
+				{key: "viaversion", file: "ViaVersion-5.0.jar", jar: viaVersion},
+				{key: "ViaVersion/ViaVersion", file: "ViaVersion-5.0.jar", jar: viaVersion, err: "already installed by viaversion"},
+				{key: "ViaVersion/ViaVersion", file: "ViaVersion.jar", jar: viaVersion, err: "installed by viaversion (identical file hash)"},
+			},
+			files: map[string]string{"luckperms": "LuckPerms-5.4.jar", "viaversion": "ViaVersion-5.0.jar"},
+		},
+		{
+			name:   "another plugin",
+			claims: []claim{{key: "viaversion", file: "ViaVersion-5.0.jar", jar: viaVersion}},
+			files:  map[string]string{"luckperms": "LuckPerms-5.4.jar", "viaversion": "ViaVersion-5.0.jar"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writePluginJar(t, dir, "LuckPerms-5.4.jar", luckPerms)
+			lockFile := &models.PackageLock{Plugins: map[string]models.PluginLock{"luckperms": {File: "LuckPerms-5.4.jar"}}}
+			index := newInstalledJars(dir, lockFile)
+
+			for i, c := range tt.claims {
+				// Downloads are claimed from a temporary file outside the plugins directory
+				candidates := t.TempDir()
+				candidate := filepath.Join(candidates, "download.tmp")
+				if c.jar == nil {
+					data, err := os.ReadFile(filepath.Join(dir, "LuckPerms-5.4.jar"))
+					if err != nil {
+						t.Fatal(err)
+					}
+					if err := os.WriteFile(candidate, data, 0644); err != nil {
+						t.Fatal(err)
+					}
+				} else {
+					writePluginJar(t, candidates, "download.tmp", c.jar)
+				}
+
+				err := index.claim(c.key, candidate, c.file)
+				if c.err == "" && err != nil || c.err != "" && (err == nil || !strings.Contains(err.Error(), c.err)) {
+					t.Fatalf("claim %d (%s, %s): err = %v, want %q", i, c.key, c.file, err, c.err)
+				}
+				if err == nil {
+					// The download is moved into place once claimed
+					if err := os.Rename(candidate, filepath.Join(dir, c.file)); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+
+			if !reflect.DeepEqual(index.files, tt.files) {
+				t.Errorf("claimed files = %v, want %v", index.files, tt.files)
+			}
+		})
+	}
+
+	var disabled *installedJars
+	if err := disabled.claim("luckperms", "missing.jar", "LuckPerms.jar"); err != nil {
+		t.Errorf("a nil index refused a jar: %v", err)
+	}
+}
+
+func TestFindDuplicateEntry(t *testing.T) {
+	plugins := []models.Plugin{
+		{Name: "LuckPerms", ModrinthID: "luckperms"},
+		{Name: "Essentials X", HangarID: "EssentialsX/Essentials"},
+		{Name: "Custom", File: "custom.jar"},
+	}
+
+	tests := []struct {
+		name      string
+		candidate models.Plugin
+		want      string // name of the duplicated entry, "" if none
+		reason    string
+	}{
+		{name: "same entry", candidate: models.Plugin{Name: "LuckPerms", ModrinthID: "luckperms"}},
+		{name: "slug across sources", candidate: models.Plugin{Name: "LP", HangarID: "LuckPerms/LuckPerms"}, want: "LuckPerms", reason: "same slug"},
+		{name: "hangar slug on modrinth", candidate: models.Plugin{Name: "EssX", ModrinthID: "essentials"}, want: "Essentials X", reason: "same slug"},
+		{name: "normalized name", candidate: models.Plugin{Name: "essentialsx", ModrinthID: "essentialsx"}, want: "Essentials X", reason: "same name"},
+		{name: "local jar by name", candidate: models.Plugin{Name: "custom", ModrinthID: "custom-plugin"}, want: "Custom", reason: "same name"},
+		{name: "different plugin", candidate: models.Plugin{Name: "ViaVersion", ModrinthID: "viaversion"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dup, reason := findDuplicateEntry(plugins, tt.candidate)
+			got := ""
+			if dup != nil {
+				got = dup.Name
+			}
+			if got != tt.want || reason != tt.reason {
+				t.Errorf("findDuplicateEntry() = %q, %q, want %q, %q", got, reason, tt.want, tt.reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,336 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/storrealbac/mpm/internal/config"
+	"github.com/storrealbac/mpm/internal/jar"
+	"github.com/storrealbac/mpm/internal/models"
+	"github.com/storrealbac/mpm/internal/server"
+	"github.com/storrealbac/mpm/internal/sources"
+	"github.com/storrealbac/mpm/internal/ui"
+)
+
+var importServerJar string
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Create package.yml from an existing server",
+	Long: `Scans plugins/*.jar (mods/*.jar for Fabric and Quilt) and the server jar of an
+existing server and writes package.yml and package-lock.yml for it.
+
+Each jar is looked up on Modrinth by its hash, then on Hangar by the plugin
+name declared in its plugin.yml. Jars that can't be identified are kept as
+local entries (file: ...), which mpm checks but never downloads.
+
+Examples:
+  mpm import
+  mpm import --server-jar paper-1.20.4-499.jar
+  mpm import --yes            # Overwrite an existing package.yml`,
+	RunE: runImport,
+}
+
+func init() {
+	importCmd.Flags().StringVar(&importServerJar, "server-jar", "server.jar", "Server jar used to detect the server type and Minecraft version")
+
+	importCmd.SetUsageTemplate(fmt.Sprintf(`%s
+  {{.UseLine}}
+
+%s
+{{.Flags.FlagUsages | trimTrailingWhitespaces}}
+`,
+		ui.SectionStyle.Render("Usage:"),
+		ui.SectionStyle.Render("Flags:"),
+	))
+
+	rootCmd.AddCommand(importCmd)
+}
+
+// importedJar is a jar found in plugins/ and what it was identified as
+type importedJar struct {
+	installed installedJar
+	sha512    string
+	sha256    string
+	plugin    models.Plugin
+	lock      models.PluginLock
+	source    string // modrinth, hangar or local
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	filename := "package.yml"
+
+	// Don't silently replace an existing configuration
+	if _, err := os.Stat(filename); err == nil {
+		if !isInteractive() && !assumeYes {
+			return fmt.Errorf("%s already exists; use --yes to overwrite it", filename)
+		}
+
+		ui.PrintWarning("File %s already exists.", filename)
+		if !confirm("Overwrite it with the imported configuration?") {
+			ui.PrintInfo("Operation cancelled.")
+			return nil
+		}
+	}
+
+	endpoints, err := config.ResolveEndpoints(nil)
+	if err != nil {
+		return fmt.Errorf("error loading mpm config: %w", err)
+	}
+	modrinthClient := sources.NewModrinthClient(endpoints.Modrinth...)
+	hangarClient := sources.NewHangarClient(endpoints.Hangar...)
+
+	ui.PrintHeader("Importing server...")
+
+	// Detect the server from its jar
+	serverConfig := models.ServerConfig{Build: "latest"}
+	if info, err := jar.DetectServer(importServerJar); err != nil {
+		ui.PrintWarning("Could not detect the server from %s: %v", importServerJar, err)
+		ui.PrintInfo("Set server.type and server.minecraft_version in package.yml by hand.")
+	} else {
+		serverConfig.Type = info.Type
+		serverConfig.MinecraftVersion = info.MinecraftVersion
+		serverConfig.MinMemory = "3G"
+		serverConfig.MaxMemory = "4G"
+		// mpm serve looks for the jar mpm installs; any other name is recorded
+		if filepath.Clean(importServerJar) != server.LaunchFile(info.Type) {
+			serverConfig.Jar = filepath.ToSlash(filepath.Clean(importServerJar))
+		}
+		ui.PrintSuccess("Server: %s %s (%s)", info.Type, info.MinecraftVersion, importServerJar)
+	}
+
+	// A server that already ran has accepted the EULA
+	serverConfig.Eula = server.EULAAccepted(".")
+
+	// Mod loaders keep their jars in mods/
+	contentDir := serverConfig.ContentDir()
+	jars := scanPluginsDir(contentDir, serverConfig.Type)
+	if len(jars) == 0 {
+		ui.PrintWarning("No jars found in %s/", contentDir)
+	}
+
+	imported := make([]*importedJar, 0, len(jars))
+	for _, installed := range jars {
+		path := filepath.Join(contentDir, installed.File)
+		imported = append(imported, &importedJar{
+			installed: installed,
+			sha512:    hashFile(path, sha512.New),
+			sha256:    hashFile(path, sha256.New),
+		})
+	}
+
+	identifyOnModrinth(modrinthClient, imported)
+	for _, item := range imported {
+		if item.source == "" && !serverConfig.IsModLoader() {
+			identifyOnHangar(hangarClient, item, serverConfig.Type)
+		}
+		if item.source == "" {
+			importAsLocal(item)
+		}
+	}
+
+	// Build package.yml and package-lock.yml
+	cwd, _ := os.Getwd()
+	pkg := &models.Package{
+		Name:    filepath.Base(cwd),
+		Version: "1.0.0",
+		Server:  serverConfig,
+		Plugins: []models.Plugin{},
+	}
+	lockFile := &models.PackageLock{Plugins: make(map[string]models.PluginLock)}
+
+	table := ui.NewTable("FILE", "PLUGIN", "SOURCE", "VERSION")
+	result := commandResult{Command: "import", Success: true, Plugins: []pluginResult{}}
+	localCount := 0
+
+	for _, item := range imported {
+		// Two jars of the same project: keep the first, the other is left unmanaged
+		dup, reason := findDuplicateEntry(pkg.Plugins, item.plugin)
+		if dup == nil && containsPlugin(pkg.Plugins, item.plugin) {
+			dup, reason = &item.plugin, "same project"
+		}
+		if dup != nil {
+			ui.PrintWarning("Skipping %s: same plugin as %s (%s)", item.installed.File, dup.Name, reason)
+			result.Plugins = append(result.Plugins, pluginResult{Plugin: item.plugin.Name, File: item.installed.File, Status: "duplicate"})
+			continue
+		}
+
+		pkg.Plugins = append(pkg.Plugins, item.plugin)
+		source, id := pluginSourceAndID(item.plugin)
+		lockFile.Plugins[id] = item.lock
+		if source == "local" {
+			localCount++
+		}
+
+		table.AddRow(item.installed.File, item.plugin.Name, source, item.plugin.Version)
+		result.Plugins = append(result.Plugins, pluginResult{
+			Plugin:  item.plugin.Name,
+			ID:      id,
+			Source:  source,
+			Version: item.plugin.Version,
+			File:    item.installed.File,
+			Hash:    item.lock.Hash,
+			Status:  "imported",
+		})
+	}
+
+	if err := pkg.SaveToFile(filename); err != nil {
+		return fmt.Errorf("error saving %s: %w", filename, err)
+	}
+	if err := lockFile.SaveToFile("package-lock.yml"); err != nil {
+		return fmt.Errorf("error saving package-lock.yml: %w", err)
+	}
+
+	if ui.IsStructured() {
+		return ui.PrintResult(result)
+	}
+
+	fmt.Fprintln(ui.Out, table.Render())
+	fmt.Fprintln(ui.Out)
+
+	ui.PrintSuccess("Imported %d plugins into %s", len(pkg.Plugins), filename)
+	if localCount > 0 {
+		ui.PrintInfo("%d jars could not be identified and were added as local files.", localCount)
+	}
+	return nil
+}
+
+// identifyOnModrinth looks up every jar by its SHA512 with a single bulk request,
+// falling back to one request per jar if the endpoint doesn't support it
+func identifyOnModrinth(client *sources.ModrinthClient, imported []*importedJar) {
+	if len(imported) == 0 {
+		return
+	}
+
+	hashes := make([]string, 0, len(imported))
+	for _, item := range imported {
+		if item.sha512 != "" {
+			hashes = append(hashes, item.sha512)
+		}
+	}
+
+	versions, err := client.GetVersionsFromHashes(hashes, "sha512")
+	if err != nil {
+		ui.PrintWarning("Bulk Modrinth lookup failed (%v), looking up jars one by one", err)
+		versions = make(map[string]sources.ModrinthVersion)
+		for _, hash := range hashes {
+			if version, err := client.GetVersionFromHash(hash, "sha512"); err == nil && version != nil {
+				versions[hash] = *version
+			}
+		}
+	}
+
+	// Resolve project slugs and titles for the matched versions
+	var projectIDs []string
+	for _, version := range versions {
+		if !containsString(projectIDs, version.ProjectID) {
+			projectIDs = append(projectIDs, version.ProjectID)
+		}
+	}
+	projects := make(map[string]sources.ModrinthProject)
+	if len(projectIDs) > 0 {
+		found, err := client.GetProjects(projectIDs)
+		if err != nil {
+			ui.PrintWarning("Could not fetch Modrinth projects: %v", err)
+		}
+		for _, p := range found {
+			projects[p.ID] = p
+		}
+	}
+
+	for _, item := range imported {
+		version, ok := versions[item.sha512]
+		if !ok {
+			continue
+		}
+
+		// Fall back to the raw project ID and the declared name
+		id, name := version.ProjectID, item.installed.File
+		if item.installed.Descriptor != nil && item.installed.Descriptor.Name != "" {
+			name = item.installed.Descriptor.Name
+		}
+		if project, ok := projects[version.ProjectID]; ok {
+			id, name = project.Slug, project.Title
+		}
+
+		item.source = "modrinth"
+		item.plugin = models.Plugin{Name: name, Version: version.VersionNumber, ModrinthID: id}
+		item.lock = models.PluginLock{Name: name, Version: version.VersionNumber, Hash: item.sha512, File: item.installed.File}
+	}
+}
+
+// identifyOnHangar looks up a jar by the plugin name declared in its descriptor,
+// and accepts it when Hangar has a version with the declared version number
+func identifyOnHangar(client *sources.HangarClient, item *importedJar, serverType string) {
+	desc := item.installed.Descriptor
+	if desc == nil || desc.Name == "" {
+		return
+	}
+
+	search, err := client.Search(sources.HangarSearchOptions{Query: desc.Name, ServerType: serverType, Limit: 10})
+	if err != nil {
+		return
+	}
+
+	for _, project := range search.Result {
+		if !strings.EqualFold(project.Name, desc.Name) {
+			continue
+		}
+
+		versions, err := client.GetProjectVersions(project.Namespace.Owner, project.Namespace.Slug, "", "")
+		if err != nil {
+			return
+		}
+		for _, version := range versions {
+			if !strings.EqualFold(version.Name, desc.Version) {
+				continue
+			}
+
+			// Prefer the hash Hangar publishes when it is the same file
+			hash := item.sha256
+			for _, download := range version.Downloads {
+				if download.FileInfo != nil && strings.EqualFold(download.FileInfo.Sha256Hash, item.sha256) {
+					hash = download.FileInfo.Sha256Hash
+				}
+			}
+
+			hangarID := project.Namespace.Owner + "/" + project.Namespace.Slug
+			item.source = "hangar"
+			item.plugin = models.Plugin{Name: project.Name, Version: version.Name, HangarID: hangarID}
+			item.lock = models.PluginLock{Name: project.Name, Version: version.Name, Hash: hash, File: item.installed.File}
+			return
+		}
+	}
+}
+
+// importAsLocal keeps an unidentified jar as a local file entry
+func importAsLocal(item *importedJar) {
+	name := strings.TrimSuffix(item.installed.File, filepath.Ext(item.installed.File))
+	version := ""
+	if desc := item.installed.Descriptor; desc != nil {
+		if desc.Name != "" {
+			name = desc.Name
+		}
+		version = desc.Version
+	}
+
+	item.source = "local"
+	item.plugin = models.Plugin{Name: name, Version: version, File: item.installed.File}
+	item.lock = models.PluginLock{Name: name, Version: version, Hash: item.sha512, File: item.installed.File}
+}
+
+// containsPlugin reports whether an entry with the same source and ID is already listed
+func containsPlugin(plugins []models.Plugin, plugin models.Plugin) bool {
+	source, id := pluginSourceAndID(plugin)
+	for _, p := range plugins {
+		if s, i := pluginSourceAndID(p); s == source && i == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,410 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/storrealbac/mpm/internal/config"
+	"github.com/storrealbac/mpm/internal/models"
+	"github.com/storrealbac/mpm/internal/sources"
+	"github.com/storrealbac/mpm/internal/ui"
+)
+
+var infoSource string
+
+var infoCmd = &cobra.Command{
+	Use:   "info <plugin>",
+	Short: "Show details about a plugin",
+	Long: `Show details about a plugin: description, authors, license, supported platforms,
+game versions, recent versions, dependencies and links.
+
+Use a Modrinth slug (e.g. luckperms) or a Hangar owner/slug (e.g. GeyserMC/Geyser).
+If a package.yml exists, compatibility with its server is checked too.
+
+Examples:
+  mpm info luckperms
+  mpm info ViaVersion/ViaVersion`,
+	Args: cobra.ExactArgs(1),
+	RunE: runInfo,
+}
+
+func init() {
+	infoCmd.Flags().StringVar(&infoSource, "source", "auto", "Plugin source: modrinth, hangar, or auto (owner/slug means Hangar)")
+
+	infoCmd.SetUsageTemplate(fmt.Sprintf(`%s
+  {{.UseLine}}
+
+%s
+{{.Flags.FlagUsages | trimTrailingWhitespaces}}
+`,
+		ui.SectionStyle.Render("Usage:"),
+		ui.SectionStyle.Render("Flags:"),
+	))
+
+	rootCmd.AddCommand(infoCmd)
+}
+
+// pluginInfo is the source-independent view printed by mpm info
+type pluginInfo struct {
+	Source       string              `json:"source" yaml:"source"`
+	ID           string              `json:"id" yaml:"id"`
+	Name         string              `json:"name" yaml:"name"`
+	Description  string              `json:"description" yaml:"description"`
+	Authors      []string            `json:"authors" yaml:"authors"`
+	License      string              `json:"license,omitempty" yaml:"license,omitempty"`
+	Downloads    int                 `json:"downloads" yaml:"downloads"`
+	Platforms    []string            `json:"platforms" yaml:"platforms"`
+	GameVersions []string            `json:"game_versions" yaml:"game_versions"`
+	Versions     []pluginInfoVersion `json:"versions" yaml:"versions"`
+	Dependencies []pluginInfoDep     `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
+	Links        map[string]string   `json:"links,omitempty" yaml:"links,omitempty"`
+	Compatible   *bool               `json:"compatible,omitempty" yaml:"compatible,omitempty"`
+	Server       string              `json:"server,omitempty" yaml:"server,omitempty"`
+}
+
+type pluginInfoVersion struct {
+	Version      string   `json:"version" yaml:"version"`
+	Date         string   `json:"date" yaml:"date"`
+	Platforms    []string `json:"platforms" yaml:"platforms"`
+	GameVersions []string `json:"game_versions" yaml:"game_versions"`
+}
+
+type pluginInfoDep struct {
+	Name     string `json:"name" yaml:"name"`
+	Required bool   `json:"required" yaml:"required"`
+}
+
+// infoRecentVersions is how many versions mpm info lists
+const infoRecentVersions = 5
+
+func runInfo(cmd *cobra.Command, args []string) error {
+	id := args[0]
+
+	pkg, err := models.LoadPackageFromFile("package.yml")
+	if err != nil {
+		pkg = nil
+	}
+
+	endpoints, err := config.ResolveEndpoints(pkg)
+	if err != nil {
+		return fmt.Errorf("error loading mpm config: %w", err)
+	}
+
+	source := infoSource
+	if source == "auto" {
+		source = "modrinth"
+		if strings.Contains(id, "/") {
+			source = "hangar"
+		}
+	}
+
+	var serverType, serverVersion string
+	if pkg != nil {
+		serverType = strings.ToLower(pkg.Server.Type)
+		serverVersion = pluginGameVersion(pkg.Server)
+	}
+
+	var info *pluginInfo
+	switch source {
+	case "modrinth":
+		info, err = modrinthInfo(sources.NewModrinthClient(endpoints.Modrinth...), id, serverType, serverVersion)
+	case "hangar":
+		info, err = hangarInfo(sources.NewHangarClient(endpoints.Hangar...), id, serverType, serverVersion)
+	default:
+		return fmt.Errorf("invalid --source value %q (use modrinth, hangar, or auto)", infoSource)
+	}
+	if err != nil {
+		return fmt.Errorf("could not get info for '%s': %w", id, err)
+	}
+
+	if pkg != nil && serverType != "" {
+		info.Server = fmt.Sprintf("%s %s", serverType, serverVersion)
+	}
+
+	if ui.IsStructured() {
+		return ui.PrintResult(info)
+	}
+
+	printPluginInfo(info)
+	return nil
+}
+
+func modrinthInfo(client *sources.ModrinthClient, id, serverType, serverVersion string) (*pluginInfo, error) {
+	project, err := client.GetProject(id)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := client.GetProjectVersions(id, "")
+	if err != nil {
+		return nil, err
+	}
+
+	info := &pluginInfo{
+		Source:       "modrinth",
+		ID:           project.Slug,
+		Name:         project.Title,
+		Description:  project.Description,
+		Downloads:    project.Downloads,
+		Platforms:    project.Loaders,
+		GameVersions: project.GameVersions,
+		Links:        map[string]string{"page": fmt.Sprintf("https://modrinth.com/plugin/%s", project.Slug)},
+	}
+
+	if project.License != nil {
+		info.License = project.License.Name
+		if info.License == "" {
+			info.License = project.License.ID
+		}
+	}
+	for name, link := range map[string]string{"issues": project.IssuesURL, "source": project.SourceURL, "wiki": project.WikiURL, "discord": project.DiscordURL} {
+		if link != "" {
+			info.Links[name] = link
+		}
+	}
+
+	if members, err := client.GetProjectMembers(id); err == nil {
+		for _, m := range members {
+			info.Authors = append(info.Authors, m.User.Username)
+		}
+	}
+	if len(info.Authors) == 0 && project.Author != "" {
+		info.Authors = []string{project.Author}
+	}
+
+	for i, v := range versions {
+		if i >= infoRecentVersions {
+			break
+		}
+		info.Versions = append(info.Versions, pluginInfoVersion{
+			Version:      v.VersionNumber,
+			Date:         v.DatePublished,
+			Platforms:    v.Loaders,
+			GameVersions: v.GameVersions,
+		})
+	}
+
+	// Dependencies of the latest version, resolved to project names
+	if len(versions) > 0 {
+		var depIDs []string
+		depRequired := make(map[string]bool)
+		for _, dep := range versions[0].Dependencies {
+			if dep.ProjectID == "" || (dep.DependencyType != "required" && dep.DependencyType != "optional") {
+				continue
+			}
+			depIDs = append(depIDs, dep.ProjectID)
+			depRequired[dep.ProjectID] = dep.DependencyType == "required"
+		}
+
+		names := make(map[string]string)
+		if len(depIDs) > 0 {
+			if projects, err := client.GetProjects(depIDs); err == nil {
+				for _, p := range projects {
+					names[p.ID] = p.Title
+				}
+			}
+		}
+		for _, depID := range depIDs {
+			name := names[depID]
+			if name == "" {
+				name = depID
+			}
+			info.Dependencies = append(info.Dependencies, pluginInfoDep{Name: name, Required: depRequired[depID]})
+		}
+	}
+
+	if serverType != "" {
+		compatible := false
+		loaders := sources.ModrinthCompatibleLoaders(serverType)
+		for _, v := range versions {
+			if containsString(v.Loaders, loaders...) && (serverVersion == "" || containsString(v.GameVersions, serverVersion)) {
+				compatible = true
+				break
+			}
+		}
+		info.Compatible = &compatible
+	}
+
+	return info, nil
+}
+
+func hangarInfo(client *sources.HangarClient, id, serverType, serverVersion string) (*pluginInfo, error) {
+	parts := strings.SplitN(id, "/", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid Hangar ID format (expected owner/slug)")
+	}
+	owner, slug := parts[0], parts[1]
+
+	project, err := client.GetProject(owner, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := client.GetProjectVersions(owner, slug, "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	info := &pluginInfo{
+		Source:      "hangar",
+		ID:          fmt.Sprintf("%s/%s", project.Namespace.Owner, project.Namespace.Slug),
+		Name:        project.Name,
+		Description: project.Description,
+		Authors:     []string{project.Namespace.Owner},
+		License:     project.Settings.License.Name,
+		Downloads:   project.Stats.Downloads,
+		Links:       map[string]string{"page": fmt.Sprintf("https://hangar.papermc.io/%s/%s", project.Namespace.Owner, project.Namespace.Slug)},
+	}
+	if info.License == "" {
+		info.License = project.Settings.License.Type
+	}
+
+	gameVersions := make(map[string]bool)
+	for platform, platformVersions := range project.SupportedPlatforms {
+		info.Platforms = append(info.Platforms, strings.ToLower(platform))
+		for _, gv := range platformVersions {
+			if !gameVersions[gv] {
+				gameVersions[gv] = true
+				info.GameVersions = append(info.GameVersions, gv)
+			}
+		}
+	}
+	sort.Strings(info.Platforms)
+
+	for _, section := range project.Settings.Links {
+		for _, link := range section.Links {
+			if link.URL != "" {
+				info.Links[strings.ToLower(link.Name)] = link.URL
+			}
+		}
+	}
+
+	for i, v := range versions {
+		if i >= infoRecentVersions {
+			break
+		}
+		entry := pluginInfoVersion{Version: v.Name, Date: v.CreatedAt}
+		for platform, deps := range v.PlatformDependencies {
+			entry.Platforms = append(entry.Platforms, strings.ToLower(platform))
+			entry.GameVersions = append(entry.GameVersions, deps...)
+		}
+		sort.Strings(entry.Platforms)
+		info.Versions = append(info.Versions, entry)
+	}
+
+	// Dependencies of the latest version (deduplicated across platforms)
+	if len(versions) > 0 {
+		seen := make(map[string]bool)
+		for _, deps := range versions[0].PluginDependencies {
+			for _, dep := range deps {
+				if seen[dep.Name] {
+					continue
+				}
+				seen[dep.Name] = true
+				info.Dependencies = append(info.Dependencies, pluginInfoDep{Name: dep.Name, Required: dep.Required})
+			}
+		}
+		sort.Slice(info.Dependencies, func(i, j int) bool {
+			return info.Dependencies[i].Name < info.Dependencies[j].Name
+		})
+	}
+
+	if serverType != "" {
+		compatible, _ := sources.HangarIsPluginCompatible(project, serverType)
+		if compatible && serverVersion != "" {
+			// Only the versions of the platform this server runs count
+			platform := sources.HangarPlatform(serverType)
+			compatible = containsString(project.SupportedPlatforms[platform], serverVersion)
+		}
+		info.Compatible = &compatible
+	}
+
+	return info, nil
+}
+
+func printPluginInfo(info *pluginInfo) {
+	ui.PrintHeader("%s (%s)", info.Name, info.ID)
+
+	if info.Description != "" {
+		fmt.Fprintf(ui.Out, "  %s\n\n", info.Description)
+	}
+
+	printInfoField("Source", strings.ToUpper(info.Source))
+	printInfoField("Authors", strings.Join(info.Authors, ", "))
+	printInfoField("License", info.License)
+	printInfoField("Downloads", formatCount(info.Downloads))
+	printInfoField("Platforms", strings.Join(info.Platforms, ", "))
+	printInfoField("Game versions", summarizeVersions(info.GameVersions))
+	fmt.Fprintln(ui.Out)
+
+	if len(info.Versions) > 0 {
+		fmt.Fprintln(ui.Out, ui.SectionStyle.Render("Recent versions:"))
+		table := ui.NewTable("VERSION", "DATE", "PLATFORMS", "GAME VERSIONS")
+		for _, v := range info.Versions {
+			table.AddRow(v.Version, formatDate(v.Date), strings.Join(v.Platforms, ", "), summarizeVersions(v.GameVersions))
+		}
+		fmt.Fprintln(ui.Out, table.Render())
+	}
+
+	if len(info.Dependencies) > 0 {
+		fmt.Fprintln(ui.Out, ui.SectionStyle.Render("Dependencies:"))
+		for _, dep := range info.Dependencies {
+			kind := "optional"
+			if dep.Required {
+				kind = "required"
+			}
+			fmt.Fprintf(ui.Out, "  - %s %s\n", dep.Name, ui.DetailStyle.Render("("+kind+")"))
+		}
+		fmt.Fprintln(ui.Out)
+	}
+
+	if len(info.Links) > 0 {
+		fmt.Fprintln(ui.Out, ui.SectionStyle.Render("Links:"))
+		names := make([]string, 0, len(info.Links))
+		for name := range info.Links {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			printInfoField(name, info.Links[name])
+		}
+		fmt.Fprintln(ui.Out)
+	}
+
+	if info.Compatible != nil {
+		if *info.Compatible {
+			ui.PrintSuccess("Compatible with your server (%s)", info.Server)
+		} else {
+			ui.PrintWarning("No compatible release for your server (%s)", info.Server)
+		}
+	}
+}
+
+func printInfoField(label, value string) {
+	if value == "" {
+		value = "-"
+	}
+	fmt.Fprintf(ui.Out, "  %s %s\n", ui.DetailStyle.Render(fmt.Sprintf("%-14s", label+":")), value)
+}
+
+// summarizeVersions shortens long game version lists to "first ... last (N versions)"
+func summarizeVersions(versions []string) string {
+	if len(versions) <= 6 {
+		return strings.Join(versions, ", ")
+	}
+	return fmt.Sprintf("%s ... %s (%d versions)", versions[0], versions[len(versions)-1], len(versions))
+}
+
+// containsString reports whether list contains any of values (case-insensitive)
+func containsString(list []string, values ...string) bool {
+	for _, item := range list {
+		for _, value := range values {
+			if strings.EqualFold(item, value) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -1,10 +1,8 @@
 package cmd
 
 import (
-	"bufio"
 	"fmt"
 	"os"
-	"strings"
 
 	"github.com/storrealbac/mpm/internal/models"
 	"github.com/storrealbac/mpm/internal/ui"
@@ -47,15 +45,19 @@ func init() {
 func runInit(cmd *cobra.Command, args []string) error {
 	filename := "package.yml"
 
+	// Interactive setup needs a terminal
+	if interactive && !isInteractive() {
+		return fmt.Errorf("interactive setup requires a terminal (remove -i or run without --yes/--non-interactive)")
+	}
+
 	// Check if already exists
 	if _, err := os.Stat(filename); err == nil {
-		ui.PrintWarning("File %s already exists. Overwrite? (y/N): ", filename)
-
-		reader := bufio.NewReader(os.Stdin)
-		response, _ := reader.ReadString('\n')
-		response = strings.TrimSpace(strings.ToLower(response))
+		if !isInteractive() && !assumeYes {
+			return fmt.Errorf("%s already exists; use --yes to overwrite it", filename)
+		}
 
-		if response != "y" && response != "yes" {
+		ui.PrintWarning("File %s already exists.", filename)
+		if !confirm("Overwrite?") {
 			ui.PrintInfo("Operation cancelled.")
 			return nil
 		}
@@ -68,7 +70,8 @@ func runInit(cmd *cobra.Command, args []string) error {
 			Type:             "paper",
 			MinecraftVersion: "1.20.4",
 			Build:            "latest",
-			StartCommand:     "java -Xms3G -Xmx4G -jar server.jar nogui",
+			MinMemory:        "3G",
+			MaxMemory:        "4G",
 		},
 		Scripts: map[string]string{
 			"clean": "rm -rf logs/*.log",
@@ -81,34 +84,32 @@ func runInit(cmd *cobra.Command, args []string) error {
 
 	// Interactive mode
 	if interactive {
-		reader := bufio.NewReader(os.Stdin)
-
 		ui.PrintHeader("Interactive Setup")
 
-		fmt.Printf("%s", ui.InfoStyle.Render("Project Name: "))
-		name, _ := reader.ReadString('\n')
-		name = strings.TrimSpace(name)
+		fmt.Fprintf(ui.Out, "%s", ui.InfoStyle.Render("Project Name: "))
+		name := readLine()
 		if name != "" {
 			pkg.Name = name
 		}
 
-		fmt.Printf("%s", ui.InfoStyle.Render("Project Version: "))
-		version, _ := reader.ReadString('\n')
-		version = strings.TrimSpace(version)
+		fmt.Fprintf(ui.Out, "%s", ui.InfoStyle.Render("Project Version: "))
+		version := readLine()
 		if version != "" {
 			pkg.Version = version
 		}
 
-		fmt.Printf("%s", ui.InfoStyle.Render("Server Type (paper, purpur, folia, spigot, bukkit, sponge, velocity, waterfall) [paper]: "))
-		srvType, _ := reader.ReadString('\n')
-		srvType = strings.TrimSpace(srvType)
+		fmt.Fprintf(ui.Out, "%s", ui.InfoStyle.Render("Server Type (vanilla, paper, purpur, folia, pufferfish, leaf, leaves, canvas, spigot, bukkit, sponge, velocity, waterfall, bungeecord, fabric, quilt, forge, neoforge) [paper]: "))
+		srvType := readLine()
 		if srvType != "" {
 			pkg.Server.Type = srvType
+			if models.IsProxy(srvType) {
+				// mpm serve gives proxies 512M
+				pkg.Server.MinMemory, pkg.Server.MaxMemory = "", ""
+			}
 		}
 
-		fmt.Printf("%s", ui.InfoStyle.Render(fmt.Sprintf("Minecraft Version [%s]: ", pkg.Server.MinecraftVersion)))
-		mcVer, _ := reader.ReadString('\n')
-		mcVer = strings.TrimSpace(mcVer)
+		fmt.Fprintf(ui.Out, "%s", ui.InfoStyle.Render(fmt.Sprintf("Minecraft Version [%s]: ", pkg.Server.MinecraftVersion)))
+		mcVer := readLine()
 		if mcVer != "" {
 			pkg.Server.MinecraftVersion = mcVer
 		}
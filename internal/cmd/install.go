@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"bufio"
 	"crypto/sha256"
 	"crypto/sha512"
 	"encoding/hex"
@@ -13,11 +12,11 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/storrealbac/mpm/internal/config"
 	"github.com/storrealbac/mpm/internal/models"
 	"github.com/storrealbac/mpm/internal/server"
 	"github.com/storrealbac/mpm/internal/sources"
 	"github.com/storrealbac/mpm/internal/ui"
-	"github.com/storrealbac/mpm/internal/utils"
 	"github.com/spf13/cobra"
 )
 
@@ -25,6 +24,8 @@ var (
 	pluginsDir    string
 	force         bool
 	pluginSource  string // "modrinth", "hangar", or "auto" (default)
+	acceptEULA    bool
+	bootstrap     bool
 )
 
 var installCmd = &cobra.Command{
@@ -32,7 +33,12 @@ var installCmd = &cobra.Command{
 	Short: "Install plugins from Modrinth or Hangar",
 	Long: `Install plugins defined in package.yml or specified as arguments from Modrinth or Hangar.
 If arguments are specified, searches for and downloads the latest compatible version and adds it to package.yml.
-Use --source flag to specify the plugin source (modrinth, hangar, or auto).`,
+Use --source flag to specify the plugin source (modrinth, hangar, or auto).
+
+--accept-eula accepts the Minecraft EULA (https://aka.ms/MinecraftEULA): it sets
+server.eula: true in package.yml and writes eula.txt. --bootstrap then starts the
+server once after installing to generate its configuration files, unless they
+already exist.`,
 	RunE: runInstall,
 }
 
@@ -40,6 +46,8 @@ func init() {
 	installCmd.Flags().StringVar(&pluginsDir, "dir", "plugins", "Directory where plugins will be saved")
 	installCmd.Flags().BoolVar(&force, "force", false, "Force re-download if already exists")
 	installCmd.Flags().StringVar(&pluginSource, "source", "auto", "Plugin source: modrinth, hangar, or auto (searches both)")
+	installCmd.Flags().BoolVar(&acceptEULA, "accept-eula", false, "Accept the Minecraft EULA (sets server.eula in package.yml)")
+	installCmd.Flags().BoolVar(&bootstrap, "bootstrap", false, "Start the server once after installing to generate its configuration files")
 
 	// Set usage template (simplified)
 	// Set usage template (simplified)
@@ -55,23 +63,55 @@ func init() {
 }
 
 func runInstall(cmd *cobra.Command, args []string) error {
+	pkg, pkgErr := models.LoadPackageFromFile("package.yml")
+
+	// Mod loaders load from mods/ unless --dir says otherwise
+	if pkgErr == nil && !cmd.Flags().Changed("dir") {
+		pluginsDir = pkg.Server.ContentDir()
+	}
+
+	// Record the EULA acceptance so later installs and mpm serve don't ask again
+	if acceptEULA {
+		if pkgErr != nil {
+			return fmt.Errorf("could not read package.yml: %w", pkgErr)
+		}
+		if !pkg.Server.Eula {
+			pkg.Server.Eula = true
+			if err := pkg.SaveToFile("package.yml"); err != nil {
+				return fmt.Errorf("error saving package.yml: %w", err)
+			}
+		}
+		// Installing single plugins never reaches the eula.txt step below
+		if !pkg.Server.IsProxy() && !server.EULAAccepted(".") {
+			if err := server.WriteEULA("."); err != nil {
+				return err
+			}
+		}
+		ui.PrintInfo("Accepted the Minecraft EULA (%s)", server.EULAURL)
+	}
+
 	// Create plugins directory
 	if err := os.MkdirAll(pluginsDir, 0755); err != nil {
 		return fmt.Errorf("could not create directory %s: %w", pluginsDir, err)
 	}
 
-	modrinthClient := sources.NewModrinthClient()
-	hangarClient := sources.NewHangarClient()
+	// Resolve API endpoints (env, package.yml, user config, defaults)
+	endpoints, err := config.ResolveEndpoints(pkg)
+	if err != nil {
+		return fmt.Errorf("error loading mpm config: %w", err)
+	}
+
+	modrinthClient := sources.NewModrinthClient(endpoints.Modrinth...)
+	hangarClient := sources.NewHangarClient(endpoints.Hangar...)
 
 	// If arguments provided, install specific plugins
 	if len(args) > 0 {
 		// When installing specific plugins, we don't check/download the server jar
 		// We just need the server version for compatibility checking
-		pkg, err := models.LoadPackageFromFile("package.yml")
 		var serverVersion string
 		var serverType string
-		if err == nil {
-			serverVersion = pkg.Server.MinecraftVersion
+		if pkgErr == nil {
+			serverVersion = pluginGameVersion(pkg.Server)
 			serverType = pkg.Server.Type
 		}
 		return installSpecificPlugins(modrinthClient, hangarClient, args, serverVersion, serverType)
@@ -79,47 +119,68 @@ func runInstall(cmd *cobra.Command, args []string) error {
 
 	// If no arguments, install from package.yml (full install)
 	// This includes verifying the server jar
-	pkg, err := models.LoadPackageFromFile("package.yml")
 	var serverVersion string
-	if err == nil {
-		serverVersion = pkg.Server.MinecraftVersion
+	if pkgErr == nil {
+		serverVersion = pluginGameVersion(pkg.Server)
 		if pkg.Server.Type != "" {
 			ui.PrintInfo("Verifying server %s %s...", pkg.Server.Type, pkg.Server.MinecraftVersion)
 
+			launchFile := server.LaunchFile(pkg.Server.Type)
 			shouldDownload := true
 			if !force {
-				if _, err := os.Stat("server.jar"); err == nil {
-					ui.PrintSuccess("Server already exists (server.jar)")
+				if _, err := os.Stat(launchFile); err == nil {
+					ui.PrintSuccess("Server already exists (%s)", launchFile)
 					shouldDownload = false
 				}
 			}
 
 			if shouldDownload {
-				downloader, err := server.GetDownloader(pkg.Server.Type)
+				downloader, err := server.GetDownloader(pkg.Server.Type, endpoints, installerJava(pkg.Server))
 				if err != nil {
 					ui.PrintWarning("Could not get downloader for %s: %v", pkg.Server.Type, err)
 				} else {
 					// Use current directory for server.jar
-					_, err := downloader.Download(pkg.Server.MinecraftVersion, pkg.Server.Build, ".")
+					launchPath, err := downloader.Download(pkg.Server.MinecraftVersion, pkg.Server.Build, ".")
 					if err != nil {
 						ui.PrintError("Error downloading server: %v", err)
 					} else {
-						ui.PrintSuccess("Server ready (server.jar)")
+						ui.PrintSuccess("Server ready (%s)", filepath.Base(launchPath))
 					}
 				}
 			}
 		}
 	}
 
-	return installFromPackage(modrinthClient, hangarClient, serverVersion, pkg.Server.Type)
+	// eula.txt is written up front; otherwise the server stops on its first start
+	if pkgErr == nil && pkg.Server.Eula && !pkg.Server.IsProxy() && !server.EULAAccepted(".") {
+		if err := server.WriteEULA("."); err != nil {
+			return err
+		}
+		ui.PrintSuccess("Wrote %s", server.EULAFile)
+	}
+
+	if err := installFromPackage(modrinthClient, hangarClient, serverVersion, pkg.Server.Type); err != nil {
+		return err
+	}
+
+	// A first boot generates the default configuration files
+	if bootstrap && pkgErr == nil && pkg.Server.Type != "" {
+		if _, err := os.Stat(bootstrapFile(pkg.Server.Type)); err == nil {
+			ui.PrintInfo("Server already bootstrapped (%s)", bootstrapFile(pkg.Server.Type))
+			return nil
+		}
+		return bootstrapServer(pkg, defaultBootstrapTimeout)
+	}
+	return nil
 }
 
-type pluginSearchResult struct {
-	name       string
-	id         string
-	source     string // "modrinth" or "hangar"
-	distance   int
-	desc       string
+// pluginGameVersion is the game version plugins are filtered by. A proxy's
+// minecraft_version is the proxy's own version, and proxy plugins don't target one.
+func pluginGameVersion(serverConfig models.ServerConfig) string {
+	if serverConfig.IsProxy() {
+		return ""
+	}
+	return serverConfig.MinecraftVersion
 }
 
 func installSpecificPlugins(modrinthClient *sources.ModrinthClient, hangarClient *sources.HangarClient, plugins []string, serverVersion string, serverType string) error {
@@ -134,28 +195,23 @@ func installSpecificPlugins(modrinthClient *sources.ModrinthClient, hangarClient
 	}
 
 	serverType = strings.ToLower(serverType)
+	result := commandResult{Command: "install", Success: true, Plugins: []pluginResult{}}
+	jarIndex = newInstalledJars(pluginsDir, lockFile)
 
 	for _, query := range plugins {
 		// Search both APIs based on source flag
 		searchModrinth := pluginSource == "auto" || pluginSource == "modrinth"
-		searchHangar := pluginSource == "auto" || pluginSource == "hangar"
+		// Hangar only hosts plugins
+		searchHangar := (pluginSource == "auto" || pluginSource == "hangar") && !models.IsModLoader(serverType)
 
-		var results []pluginSearchResult
+		var results []searchHit
 
 		// Search Hangar
 		if searchHangar {
 			hangarProjects, err := hangarClient.SearchProjects(query, serverType, 25)
 			if err == nil {
 				for _, p := range hangarProjects {
-					fullSlug := fmt.Sprintf("%s/%s", p.Namespace.Owner, p.Namespace.Slug)
-					distance := utils.LevenshteinDistance(query, p.Name)
-					results = append(results, pluginSearchResult{
-						name:     p.Name,
-						id:       fullSlug,
-						source:   "hangar",
-						distance: distance,
-						desc:     p.Description,
-					})
+					results = append(results, hangarSearchHit(p, serverType))
 				}
 			}
 		}
@@ -165,72 +221,104 @@ func installSpecificPlugins(modrinthClient *sources.ModrinthClient, hangarClient
 			modrinthProjects, err := modrinthClient.SearchProjects(query, serverType, false)
 			if err == nil {
 				for _, p := range modrinthProjects {
-					distance := utils.LevenshteinDistance(query, p.Title)
-					results = append(results, pluginSearchResult{
-						name:     p.Title,
-						id:       p.Slug,
-						source:   "modrinth",
-						distance: distance,
-						desc:     p.Description,
-					})
+					results = append(results, modrinthSearchHit(p, serverType))
 				}
 			}
 		}
 
 		if len(results) == 0 {
 			ui.PrintWarning("No results found for '%s'", query)
+			result.Success = false
+			result.Plugins = append(result.Plugins, pluginResult{Plugin: query, Status: "not-found", Error: "no results found"})
 			continue
 		}
 
-		// Sort by edit distance (lower is better)
-		sort.Slice(results, func(i, j int) bool {
-			return results[i].distance < results[j].distance
-		})
+		// Rank by relevance and merge projects published on both sources
+		results = rankSearchHits(query, results)
 
-		// Check for exact match (distance 0)
-		var selected *pluginSearchResult
-		if results[0].distance == 0 {
+		// Check for exact match on name or slug
+		var selected *searchHit
+		if results[0].exact {
 			selected = &results[0]
-			ui.PrintSuccess("Found: %s (%s) from %s", selected.name, selected.id, selected.source)
+			ui.PrintSuccess("Found: %s (%s) from %s", selected.Name, selected.ID, selected.Source)
 		} else {
-			// Show top 6 results by edit distance
-			fmt.Println("Did you mean:")
-
+			// Show top 6 results by relevance
 			displayResults := results
 			if len(displayResults) > 6 {
 				displayResults = displayResults[:6]
 			}
 
-			for i, r := range displayResults {
-				fmt.Printf("   %d. [%s] %s (%s) - %s\n", i+1, strings.ToUpper(r.source), r.name, r.id, r.desc)
+			// Without a terminal we can't ask, so fail with the candidate list
+			if !isInteractive() {
+				candidates := make([]string, 0, len(displayResults))
+				for _, r := range displayResults {
+					candidates = append(candidates, fmt.Sprintf("[%s] %s (%s)", strings.ToUpper(r.Source), r.Name, r.ID))
+				}
+				err := fmt.Errorf("ambiguous search for '%s': %d candidates", query, len(displayResults))
+				ui.PrintError("%v:\n      %s", err, strings.Join(candidates, "\n      "))
+				ui.PrintInfo("Use the exact name, or add the plugin to package.yml with its ID.")
+				result.Success = false
+				result.Plugins = append(result.Plugins, pluginResult{
+					Plugin: query,
+					Status: "ambiguous",
+					Error:  fmt.Sprintf("%v: %s", err, strings.Join(candidates, ", ")),
+				})
+				continue
 			}
 
-			fmt.Printf("\nSelect a number (1-%d) or press Enter to cancel: ", len(displayResults))
-			reader := bufio.NewReader(os.Stdin)
-			input, _ := reader.ReadString('\n')
-			input = strings.TrimSpace(input)
+			fmt.Fprintln(ui.Out, "Did you mean:")
+			for i, r := range displayResults {
+				line := fmt.Sprintf("   %d. [%s] %s (%s) - %s", i+1, strings.ToUpper(r.Source), r.Name, r.ID, r.Description)
+				if label := alsoOnLabel(r); label != "" {
+					line += " " + ui.DetailStyle.Render("("+label+")")
+				}
+				fmt.Fprintln(ui.Out, line)
+			}
 
-			var choice int
-			if _, err := fmt.Sscanf(input, "%d", &choice); err == nil && choice >= 1 && choice <= len(displayResults) {
-				selected = &displayResults[choice-1]
+			fmt.Fprintln(ui.Out)
+			if choice, ok := promptSelection("Select a number", len(displayResults)); ok {
+				selected = &displayResults[choice]
 			} else {
 				ui.PrintInfo("Operation cancelled.")
+				result.Plugins = append(result.Plugins, pluginResult{Plugin: query, Status: "cancelled"})
 				continue
 			}
 		}
 
 		// Install the selected plugin
-		if selected.source == "hangar" {
-			if err := installFromHangar(hangarClient, selected.id, serverVersion, serverType, pkg, lockFile); err != nil {
-				ui.PrintError("Failed to install '%s': %v", selected.name, err)
-				continue
-			}
+		entry := pluginResult{Plugin: selected.Name, ID: selected.ID, Source: selected.Source}
+
+		// Refuse a plugin that package.yml already has from another source
+		if dup, reason := findInstalledDuplicate(pkg, *selected); dup != nil {
+			err := fmt.Errorf("%s is already installed as %s (%s, %s)", selected.Name, dup.Name, pluginKey(*dup), reason)
+			ui.PrintError("%v", err)
+			entry.Status = "duplicate"
+			entry.Error = err.Error()
+			result.Success = false
+			result.Plugins = append(result.Plugins, entry)
+			continue
+		}
+
+		var installErr error
+		if selected.Source == "hangar" {
+			installErr = installFromHangar(hangarClient, selected.ID, serverVersion, serverType, pkg, lockFile)
 		} else {
-			if err := installFromModrinth(modrinthClient, selected.id, serverVersion, serverType, pkg, lockFile); err != nil {
-				ui.PrintError("Failed to install '%s': %v", selected.name, err)
-				continue
-			}
+			installErr = installFromModrinth(modrinthClient, selected.ID, serverVersion, serverType, pkg, lockFile)
+		}
+
+		if installErr != nil {
+			ui.PrintError("Failed to install '%s': %v", selected.Name, installErr)
+			entry.Status = "error"
+			entry.Error = installErr.Error()
+			result.Success = false
+		} else {
+			lock := lockFile.Plugins[selected.ID]
+			entry.Plugin = lock.Name
+			entry.Version = lock.Version
+			entry.Hash = lock.Hash
+			entry.Status = "installed"
 		}
+		result.Plugins = append(result.Plugins, entry)
 	}
 
 	// Save updated package.yml
@@ -243,6 +331,16 @@ func installSpecificPlugins(modrinthClient *sources.ModrinthClient, hangarClient
 		return fmt.Errorf("error saving package-lock.yml: %w", err)
 	}
 
+	if ui.IsStructured() {
+		if err := ui.PrintResult(result); err != nil {
+			return err
+		}
+	}
+
+	if !result.Success {
+		return fmt.Errorf("some plugins could not be installed")
+	}
+
 	return nil
 }
 
@@ -254,7 +352,7 @@ func installFromModrinth(client *sources.ModrinthClient, pluginID, serverVersion
 	}
 
 	// Get versions
-	versions, err := client.GetProjectVersions(pluginID, serverVersion)
+	versions, err := client.GetProjectVersionsForLoaders(pluginID, serverVersion, sources.ModrinthCompatibleLoaders(serverType))
 	if err != nil {
 		return fmt.Errorf("error getting versions: %v", err)
 	}
@@ -297,7 +395,7 @@ func installFromModrinth(client *sources.ModrinthClient, pluginID, serverVersion
 	}
 
 	// Download
-	if err := downloadFileModrinth(client, fileToDownload.URL, fileToDownload.Filename, pluginsDir, fileToDownload.Hashes["sha512"], -1); err != nil {
+	if err := downloadFileModrinth(client, fileToDownload.URL, fileToDownload.Filename, pluginsDir, fileToDownload.Hashes["sha512"], pluginID, -1); err != nil {
 		return fmt.Errorf("error downloading: %v", err)
 	}
 
@@ -326,6 +424,7 @@ func installFromModrinth(client *sources.ModrinthClient, pluginID, serverVersion
 		Name:    newPlugin.Name,
 		Version: latestVersion.VersionNumber,
 		Hash:    fileToDownload.Hashes["sha512"],
+		File:    fileToDownload.Filename,
 	}
 
 	ui.PrintSuccess("Installed %s %s from Modrinth", newPlugin.Name, newPlugin.Version)
@@ -384,7 +483,7 @@ func installFromHangar(client *sources.HangarClient, pluginID, serverVersion, se
 	filename := sources.GetFilename(&latestVersion, serverType)
 
 	// Download
-	if err := downloadFileHangar(client, downloadURL, filename, pluginsDir, hash, -1); err != nil {
+	if err := downloadFileHangar(client, downloadURL, filename, pluginsDir, hash, pluginID, -1); err != nil {
 		return fmt.Errorf("error downloading: %v", err)
 	}
 
@@ -413,6 +512,7 @@ func installFromHangar(client *sources.HangarClient, pluginID, serverVersion, se
 		Name:    newPlugin.Name,
 		Version: latestVersion.Name,
 		Hash:    hash,
+		File:    filename,
 	}
 
 	ui.PrintSuccess("Installed %s %s from Hangar", newPlugin.Name, newPlugin.Version)
@@ -433,6 +533,21 @@ func installFromPackage(modrinthClient *sources.ModrinthClient, hangarClient *so
 
 	ui.PrintHeader("Installing %d plugins for server '%s'...", len(pkg.Plugins), pkg.Name)
 
+	result := commandResult{Command: "install", Success: true, Plugins: []pluginResult{}}
+	// failPlugin records a plugin that could not be resolved before downloading
+	failPlugin := func(plugin models.Plugin, err error) {
+		source, id := pluginSourceAndID(plugin)
+		result.Success = false
+		result.Plugins = append(result.Plugins, pluginResult{
+			Plugin:  plugin.Name,
+			ID:      id,
+			Source:  source,
+			Version: plugin.Version,
+			Status:  "error",
+			Error:   err.Error(),
+		})
+	}
+
 	// Prepare download tasks
 	type downloadTask struct {
 		plugin         models.Plugin
@@ -450,16 +565,26 @@ func installFromPackage(modrinthClient *sources.ModrinthClient, hangarClient *so
 
 	var tasks []downloadTask
 
+	jarIndex = newInstalledJars(pluginsDir, lockFile)
+
 	// Fetch metadata for all plugins first
 	for i, plugin := range pkg.Plugins {
+		// Skip entries that repeat an earlier plugin from another source
+		if dup, reason := findDuplicateEntry(pkg.Plugins[:i], plugin); dup != nil {
+			ui.PrintWarning("Skipping %s (%s): duplicate of %s (%s, %s)", plugin.Name, pluginKey(plugin), dup.Name, pluginKey(*dup), reason)
+			failPlugin(plugin, fmt.Errorf("duplicate of %s (%s): %s", dup.Name, pluginKey(*dup), reason))
+			continue
+		}
+
 		// Determine plugin source
 		if plugin.ModrinthID != "" {
 			ui.PrintStep(i+1, len(pkg.Plugins), "Checking: %s (Modrinth: %s)", plugin.Name, plugin.ModrinthID)
 
 			// Get project versions
-			versions, err := modrinthClient.GetProjectVersions(plugin.ModrinthID, serverVersion)
+			versions, err := modrinthClient.GetProjectVersionsForLoaders(plugin.ModrinthID, serverVersion, sources.ModrinthCompatibleLoaders(serverType))
 			if err != nil {
 				ui.PrintError("Error getting info: %v", err)
+				failPlugin(plugin, err)
 				continue
 			}
 
@@ -475,6 +600,7 @@ func installFromPackage(modrinthClient *sources.ModrinthClient, hangarClient *so
 					versions = []sources.ModrinthVersion{selectedAlt.version}
 				} else {
 					ui.PrintError("No versions available for %s on any platform", plugin.Name)
+					failPlugin(plugin, fmt.Errorf("no versions available on any platform"))
 					continue
 				}
 			}
@@ -494,6 +620,7 @@ func installFromPackage(modrinthClient *sources.ModrinthClient, hangarClient *so
 
 			if targetVersion == nil {
 				ui.PrintError("Version %s not found for %s", plugin.Version, plugin.Name)
+				failPlugin(plugin, fmt.Errorf("version %s not found", plugin.Version))
 				continue
 			}
 
@@ -511,6 +638,7 @@ func installFromPackage(modrinthClient *sources.ModrinthClient, hangarClient *so
 
 			if fileToDownload == nil {
 				ui.PrintError("No downloadable files for %s", plugin.Name)
+				failPlugin(plugin, fmt.Errorf("no downloadable files"))
 				continue
 			}
 
@@ -528,6 +656,7 @@ func installFromPackage(modrinthClient *sources.ModrinthClient, hangarClient *so
 			parts := strings.SplitN(plugin.HangarID, "/", 2)
 			if len(parts) != 2 {
 				ui.PrintError("Invalid Hangar ID format for %s (expected owner/slug)", plugin.Name)
+				failPlugin(plugin, fmt.Errorf("invalid Hangar ID format (expected owner/slug)"))
 				continue
 			}
 			owner, slug := parts[0], parts[1]
@@ -536,6 +665,7 @@ func installFromPackage(modrinthClient *sources.ModrinthClient, hangarClient *so
 			versions, err := hangarClient.GetProjectVersions(owner, slug, serverVersion, serverType)
 			if err != nil {
 				ui.PrintError("Error getting info: %v", err)
+				failPlugin(plugin, err)
 				continue
 			}
 
@@ -546,6 +676,7 @@ func installFromPackage(modrinthClient *sources.ModrinthClient, hangarClient *so
 				project, err := hangarClient.GetProject(owner, slug)
 				if err != nil {
 					ui.PrintError("Error getting project info for %s: %v", plugin.Name, err)
+					failPlugin(plugin, err)
 					continue
 				}
 
@@ -559,6 +690,7 @@ func installFromPackage(modrinthClient *sources.ModrinthClient, hangarClient *so
 					serverType = selectedAlt.platform // Update serverType for this plugin
 				} else {
 					ui.PrintError("No versions available for %s on any platform", plugin.Name)
+					failPlugin(plugin, fmt.Errorf("no versions available on any platform"))
 					continue
 				}
 			}
@@ -578,6 +710,7 @@ func installFromPackage(modrinthClient *sources.ModrinthClient, hangarClient *so
 
 			if targetVersion == nil {
 				ui.PrintError("Version %s not found for %s", plugin.Version, plugin.Name)
+				failPlugin(plugin, fmt.Errorf("version %s not found", plugin.Version))
 				continue
 			}
 
@@ -585,6 +718,7 @@ func installFromPackage(modrinthClient *sources.ModrinthClient, hangarClient *so
 			downloadURL, hash, err := sources.GetDownloadURL(targetVersion, serverType)
 			if err != nil {
 				ui.PrintError("No downloadable files for %s: %v", plugin.Name, err)
+				failPlugin(plugin, err)
 				continue
 			}
 
@@ -599,15 +733,25 @@ func installFromPackage(modrinthClient *sources.ModrinthClient, hangarClient *so
 				hangarFilename: filename,
 				index:          i,
 			})
+		} else if plugin.File != "" {
+			// Local jars are managed by hand, only check they are present
+			ui.PrintStep(i+1, len(pkg.Plugins), "Checking: %s (local: %s)", plugin.Name, plugin.File)
+			if _, err := os.Stat(filepath.Join(pluginsDir, plugin.File)); err != nil {
+				ui.PrintError("Local file %s not found in %s", plugin.File, pluginsDir)
+				failPlugin(plugin, fmt.Errorf("local file %s not found", plugin.File))
+				continue
+			}
+			result.Plugins = append(result.Plugins, pluginResult{Plugin: plugin.Name, ID: plugin.File, Source: "local", Version: plugin.Version, File: plugin.File, Status: "local"})
 		} else {
 			ui.PrintWarning("Plugin %s has no Modrinth or Hangar ID, skipping", plugin.Name)
+			result.Plugins = append(result.Plugins, pluginResult{Plugin: plugin.Name, Version: plugin.Version, Status: "skipped"})
 		}
 	}
 
 	// Download with concurrency limit of 5
-	fmt.Println()
+	fmt.Fprintln(ui.Out)
 	ui.PrintInfo("Downloading %d plugins (5 concurrent downloads)...", len(tasks))
-	fmt.Println()
+	fmt.Fprintln(ui.Out)
 
 	// Initialize multi-bar progress
 	ui.InitMultiBar()
@@ -623,6 +767,7 @@ func installFromPackage(modrinthClient *sources.ModrinthClient, hangarClient *so
 	semaphore := make(chan struct{}, 5) // Limit to 5 concurrent downloads
 	var mutex sync.Mutex
 	errors := make([]error, 0)
+	failedTasks := make(map[int]error) // task index -> download error
 
 	for i, task := range tasks {
 		wg.Add(1)
@@ -639,23 +784,27 @@ func installFromPackage(modrinthClient *sources.ModrinthClient, hangarClient *so
 			var lockKey string
 			var version string
 			var hash string
+			var file string
 
 			// Download based on source
 			if t.source == "modrinth" {
-				err = downloadFileModrinth(modrinthClient, t.fileToDownload.URL, t.fileToDownload.Filename, pluginsDir, t.fileToDownload.Hashes["sha512"], barID)
+				err = downloadFileModrinth(modrinthClient, t.fileToDownload.URL, t.fileToDownload.Filename, pluginsDir, t.fileToDownload.Hashes["sha512"], t.plugin.ModrinthID, barID)
 				lockKey = t.plugin.ModrinthID
 				version = t.targetVersion.VersionNumber
 				hash = t.fileToDownload.Hashes["sha512"]
+				file = t.fileToDownload.Filename
 			} else if t.source == "hangar" {
-				err = downloadFileHangar(hangarClient, t.hangarURL, t.hangarFilename, pluginsDir, t.hangarHash, barID)
+				err = downloadFileHangar(hangarClient, t.hangarURL, t.hangarFilename, pluginsDir, t.hangarHash, t.plugin.HangarID, barID)
 				lockKey = t.plugin.HangarID
 				version = t.hangarVersion.Name
 				hash = t.hangarHash
+				file = t.hangarFilename
 			}
 
 			if err != nil {
 				mutex.Lock()
 				errors = append(errors, fmt.Errorf("error downloading %s: %v", t.plugin.Name, err))
+				failedTasks[taskIdx] = err
 				mutex.Unlock()
 				return
 			}
@@ -666,6 +815,7 @@ func installFromPackage(modrinthClient *sources.ModrinthClient, hangarClient *so
 				Name:    t.plugin.Name,
 				Version: version,
 				Hash:    hash,
+				File:    file,
 			}
 			mutex.Unlock()
 		}(task, i)
@@ -675,8 +825,30 @@ func installFromPackage(modrinthClient *sources.ModrinthClient, hangarClient *so
 	ui.CloseMultiBar()
 
 	// Print success messages
-	fmt.Println()
-	for _, task := range tasks {
+	fmt.Fprintln(ui.Out)
+	for i, task := range tasks {
+		entry := pluginResult{Plugin: task.plugin.Name, Source: task.source, Status: "installed"}
+		if task.source == "modrinth" {
+			entry.ID = task.plugin.ModrinthID
+			entry.Version = task.targetVersion.VersionNumber
+			entry.File = task.fileToDownload.Filename
+			entry.Hash = task.fileToDownload.Hashes["sha512"]
+		} else {
+			entry.ID = task.plugin.HangarID
+			entry.Version = task.hangarVersion.Name
+			entry.File = task.hangarFilename
+			entry.Hash = task.hangarHash
+		}
+
+		if err, failed := failedTasks[i]; failed {
+			entry.Status = "error"
+			entry.Error = err.Error()
+			result.Success = false
+			result.Plugins = append(result.Plugins, entry)
+			continue
+		}
+		result.Plugins = append(result.Plugins, entry)
+
 		if task.source == "modrinth" {
 			ui.PrintSuccess("Installed: %s v%s (Modrinth)", task.plugin.Name, task.targetVersion.VersionNumber)
 		} else if task.source == "hangar" {
@@ -686,7 +858,7 @@ func installFromPackage(modrinthClient *sources.ModrinthClient, hangarClient *so
 
 	// Report errors
 	if len(errors) > 0 {
-		fmt.Println()
+		fmt.Fprintln(ui.Out)
 		ui.PrintWarning("Some downloads failed:")
 		for _, err := range errors {
 			ui.PrintError("%v", err)
@@ -698,10 +870,14 @@ func installFromPackage(modrinthClient *sources.ModrinthClient, hangarClient *so
 		return fmt.Errorf("error saving package-lock.yml: %w", err)
 	}
 
+	if ui.IsStructured() {
+		return ui.PrintResult(result)
+	}
+
 	return nil
 }
 
-func downloadFileModrinth(client *sources.ModrinthClient, url, filename, destDir, expectedHash string, progressBarID int) error {
+func downloadFileModrinth(client *sources.ModrinthClient, url, filename, destDir, expectedHash, lockKey string, progressBarID int) error {
 	destPath := filepath.Join(destDir, filename)
 
 	if !force {
@@ -762,9 +938,9 @@ func downloadFileModrinth(client *sources.ModrinthClient, url, filename, destDir
 	} else {
 		// Force 100% if total was unknown (old style)
 		if size <= 0 {
-			fmt.Printf("\r[%s] 100.00%%", strings.Repeat("=", 40))
+			fmt.Fprintf(ui.Out, "\r[%s] 100.00%%", strings.Repeat("=", 40))
 		}
-		fmt.Println() // New line
+		fmt.Fprintln(ui.Out) // New line
 	}
 
 	// Verify Checksum
@@ -778,6 +954,11 @@ func downloadFileModrinth(client *sources.ModrinthClient, url, filename, destDir
 	// Close temp file before moving
 	tmpFile.Close()
 
+	// Refuse a jar that is the same plugin as one installed by another entry
+	if err := jarIndex.claim(lockKey, tmpFile.Name(), filename); err != nil {
+		return err
+	}
+
 	// Move temp file to destination
 	if err := os.Rename(tmpFile.Name(), destPath); err != nil {
 		// Fallback copy if rename fails (e.g. cross-device)
@@ -801,7 +982,7 @@ func downloadFileModrinth(client *sources.ModrinthClient, url, filename, destDir
 	return nil
 }
 
-func downloadFileHangar(client *sources.HangarClient, url, filename, destDir, expectedHash string, progressBarID int) error {
+func downloadFileHangar(client *sources.HangarClient, url, filename, destDir, expectedHash, lockKey string, progressBarID int) error {
 	destPath := filepath.Join(destDir, filename)
 
 	if !force {
@@ -862,9 +1043,9 @@ func downloadFileHangar(client *sources.HangarClient, url, filename, destDir, ex
 	} else {
 		// Force 100% if total was unknown (old style)
 		if size <= 0 {
-			fmt.Printf("\r[%s] 100.00%%", strings.Repeat("=", 40))
+			fmt.Fprintf(ui.Out, "\r[%s] 100.00%%", strings.Repeat("=", 40))
 		}
-		fmt.Println() // New line
+		fmt.Fprintln(ui.Out) // New line
 	}
 
 	// Verify Checksum
@@ -878,6 +1059,11 @@ func downloadFileHangar(client *sources.HangarClient, url, filename, destDir, ex
 	// Close temp file before moving
 	tmpFile.Close()
 
+	// Refuse a jar that is the same plugin as one installed by another entry
+	if err := jarIndex.claim(lockKey, tmpFile.Name(), filename); err != nil {
+		return err
+	}
+
 	// Move temp file to destination
 	if err := os.Rename(tmpFile.Name(), destPath); err != nil {
 		// Fallback copy if rename fails (e.g. cross-device)
@@ -925,24 +1111,7 @@ type alternativeVersionInfo struct {
 func findAlternativeModrinthVersions(client *sources.ModrinthClient, pluginID, serverVersion, currentPlatform string) []alternativeVersionInfo {
 	alternatives := []alternativeVersionInfo{}
 
-	// Define platform search order based on current platform
-	var platformsToTry []string
-	switch strings.ToLower(currentPlatform) {
-	case "paper", "purpur", "folia":
-		platformsToTry = []string{"paper", "spigot", "bukkit", "purpur", "folia"}
-	case "spigot":
-		platformsToTry = []string{"spigot", "bukkit", "paper"}
-	case "bukkit":
-		platformsToTry = []string{"bukkit", "spigot", "paper"}
-	case "velocity":
-		platformsToTry = []string{"velocity"}
-	case "waterfall":
-		platformsToTry = []string{"bungeecord"}
-	case "sponge":
-		platformsToTry = []string{"sponge"}
-	default:
-		platformsToTry = []string{"paper", "spigot", "bukkit", "velocity", "bungeecord", "sponge"}
-	}
+	platformsToTry := alternativePlatforms(currentPlatform)
 
 	// Try each platform
 	for _, platform := range platformsToTry {
@@ -1000,42 +1169,77 @@ func findAlternativeModrinthVersions(client *sources.ModrinthClient, pluginID, s
 	return alternatives
 }
 
+// alternativePlatforms returns the Modrinth loaders to fall back to, in order, when a
+// project has no version for currentPlatform. Only loaders the platform can load are
+// listed: mod loaders don't run each other's mods, except Quilt which loads Fabric mods
+func alternativePlatforms(currentPlatform string) []string {
+	switch strings.ToLower(currentPlatform) {
+	case "paper", "purpur", "pufferfish", "leaf", "leaves":
+		return []string{"paper", "spigot", "bukkit", "purpur", "folia"}
+	case "folia", "canvas":
+		// Region threading only runs plugins built for Folia
+		return []string{"folia"}
+	case "spigot":
+		return []string{"spigot", "bukkit", "paper"}
+	case "bukkit":
+		return []string{"bukkit", "spigot", "paper"}
+	case "velocity":
+		return []string{"velocity"}
+	case "waterfall", "bungeecord":
+		return []string{"bungeecord", "waterfall"}
+	case "sponge":
+		return []string{"sponge"}
+	case "quilt":
+		return []string{"quilt", "fabric"}
+	case "fabric", "forge", "neoforge":
+		return []string{strings.ToLower(currentPlatform)}
+	default:
+		return []string{"paper", "spigot", "bukkit", "velocity", "bungeecord", "sponge"}
+	}
+}
+
 // promptAlternativeVersionSelection shows alternatives and lets user choose
 func promptAlternativeVersionSelection(alternatives []alternativeVersionInfo) *sources.ModrinthVersion {
 	ui.PrintInfo("Found versions from alternative platforms:")
-	fmt.Println()
+
+	// Without a terminal, deterministically take the first (newest) alternative
+	if !isInteractive() {
+		selected := alternatives[0]
+		ui.PrintInfo("Non-interactive mode: using %s from platform %s", selected.version.Name, strings.ToUpper(selected.platform))
+		return &selected.version
+	}
+	fmt.Fprintln(ui.Out)
 
 	// Group by platform and show max 3 per platform
 	platformGroups := make(map[string][]alternativeVersionInfo)
+	var platformOrder []string
 	for _, alt := range alternatives {
+		if _, seen := platformGroups[alt.platform]; !seen {
+			platformOrder = append(platformOrder, alt.platform)
+		}
 		platformGroups[alt.platform] = append(platformGroups[alt.platform], alt)
 	}
 
 	options := []alternativeVersionInfo{}
 	idx := 1
-	for platform, versions := range platformGroups {
-		fmt.Printf("  Platform: %s\n", strings.ToUpper(platform))
+	for _, platform := range platformOrder {
+		versions := platformGroups[platform]
+		fmt.Fprintf(ui.Out, "  Platform: %s\n", strings.ToUpper(platform))
 		limit := len(versions)
 		if limit > 3 {
 			limit = 3
 		}
 		for i := 0; i < limit; i++ {
 			v := versions[i]
-			fmt.Printf("    %d. %s (v%s) - Game versions: %s\n", idx, v.version.Name, v.version.VersionNumber, strings.Join(v.version.GameVersions, ", "))
+			fmt.Fprintf(ui.Out, "    %d. %s (v%s) - Game versions: %s\n", idx, v.version.Name, v.version.VersionNumber, strings.Join(v.version.GameVersions, ", "))
 			options = append(options, v)
 			idx++
 		}
-		fmt.Println()
+		fmt.Fprintln(ui.Out)
 	}
 
-	fmt.Printf("Select a version (1-%d) or press Enter to cancel: ", len(options))
-	reader := bufio.NewReader(os.Stdin)
-	input, _ := reader.ReadString('\n')
-	input = strings.TrimSpace(input)
-
-	var choice int
-	if _, err := fmt.Sscanf(input, "%d", &choice); err == nil && choice >= 1 && choice <= len(options) {
-		selected := options[choice-1]
+	if choice, ok := promptSelection("Select a version", len(options)); ok {
+		selected := options[choice]
 		ui.PrintSuccess("Selected: %s from platform %s", selected.version.Name, strings.ToUpper(selected.platform))
 		return &selected.version
 	}
@@ -1065,6 +1269,9 @@ func findAlternativeHangarVersions(client *sources.HangarClient, owner, slug, se
 		return alternatives
 	}
 
+	// Map iteration order is random; sort so non-interactive runs are reproducible
+	sort.Strings(availablePlatforms)
+
 	ui.PrintInfo("Searching in alternative platforms: %s", strings.Join(availablePlatforms, ", "))
 
 	// Try each available platform
@@ -1093,18 +1300,30 @@ func findAlternativeHangarVersions(client *sources.HangarClient, owner, slug, se
 // promptAlternativeHangarVersionSelection shows Hangar alternatives and lets user choose
 func promptAlternativeHangarVersionSelection(alternatives []hangarAlternativeVersionInfo) *hangarAlternativeVersionInfo {
 	ui.PrintInfo("Found versions from alternative platforms:")
-	fmt.Println()
+
+	// Without a terminal, deterministically take the first (newest) alternative
+	if !isInteractive() {
+		selected := alternatives[0]
+		ui.PrintInfo("Non-interactive mode: using %s from platform %s", selected.version.Name, strings.ToUpper(selected.platform))
+		return &selected
+	}
+	fmt.Fprintln(ui.Out)
 
 	// Group by platform and show max 3 per platform
 	platformGroups := make(map[string][]hangarAlternativeVersionInfo)
+	var platformOrder []string
 	for _, alt := range alternatives {
+		if _, seen := platformGroups[alt.platform]; !seen {
+			platformOrder = append(platformOrder, alt.platform)
+		}
 		platformGroups[alt.platform] = append(platformGroups[alt.platform], alt)
 	}
 
 	options := []hangarAlternativeVersionInfo{}
 	idx := 1
-	for platform, versions := range platformGroups {
-		fmt.Printf("  Platform: %s\n", strings.ToUpper(platform))
+	for _, platform := range platformOrder {
+		versions := platformGroups[platform]
+		fmt.Fprintf(ui.Out, "  Platform: %s\n", strings.ToUpper(platform))
 		limit := len(versions)
 		if limit > 3 {
 			limit = 3
@@ -1115,21 +1334,15 @@ func promptAlternativeHangarVersionSelection(alternatives []hangarAlternativeVer
 			if deps, ok := v.version.PlatformDependencies[strings.ToUpper(platform)]; ok {
 				gameVersions = deps
 			}
-			fmt.Printf("    %d. %s - Game versions: %s\n", idx, v.version.Name, strings.Join(gameVersions, ", "))
+			fmt.Fprintf(ui.Out, "    %d. %s - Game versions: %s\n", idx, v.version.Name, strings.Join(gameVersions, ", "))
 			options = append(options, v)
 			idx++
 		}
-		fmt.Println()
+		fmt.Fprintln(ui.Out)
 	}
 
-	fmt.Printf("Select a version (1-%d) or press Enter to cancel: ", len(options))
-	reader := bufio.NewReader(os.Stdin)
-	input, _ := reader.ReadString('\n')
-	input = strings.TrimSpace(input)
-
-	var choice int
-	if _, err := fmt.Sscanf(input, "%d", &choice); err == nil && choice >= 1 && choice <= len(options) {
-		selected := options[choice-1]
+	if choice, ok := promptSelection("Select a version", len(options)); ok {
+		selected := options[choice]
 		ui.PrintSuccess("Selected: %s from platform %s", selected.version.Name, strings.ToUpper(selected.platform))
 		return &selected
 	}
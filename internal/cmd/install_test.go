@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/storrealbac/mpm/internal/sources"
+)
+
+// fakeModrinthVersions serves /project/<id>/version with one 1.21 version per loader
+func fakeModrinthVersions(t *testing.T, loaders ...string) *sources.ModrinthClient {
+	t.Helper()
+	var versions []sources.ModrinthVersion
+	for _, loader := range loaders {
+		versions = append(versions, sources.ModrinthVersion{
+			ID:           loader,
+			Name:         "1.0.0+" + loader,
+			GameVersions: []string{"1.21"},
+			Loaders:      []string{loader},
+		})
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(versions)
+	}))
+	t.Cleanup(server.Close)
+	return sources.NewModrinthClient(server.URL)
+}
+
+func TestFindAlternativeModrinthVersions(t *testing.T) {
+	tests := []struct {
+		name     string
+		platform string
+		loaders  []string // loaders the project publishes for, never the platform's own
+		want     []string // platforms of the alternatives found
+	}{
+		{name: "quilt falls back to fabric", platform: "quilt", loaders: []string{"fabric"}, want: []string{"fabric"}},
+		{name: "fabric can't load quilt mods", platform: "fabric", loaders: []string{"quilt"}},
+		{name: "forge can't load neoforge mods", platform: "forge", loaders: []string{"neoforge"}},
+		{name: "neoforge can't load forge mods", platform: "neoforge", loaders: []string{"forge"}},
+		{name: "mod loaders don't take plugins", platform: "fabric", loaders: []string{"paper", "forge"}},
+		{name: "canvas falls back to folia", platform: "canvas", loaders: []string{"paper", "folia"}, want: []string{"folia"}},
+		{name: "canvas can't load paper plugins", platform: "canvas", loaders: []string{"paper", "spigot", "bukkit", "purpur"}},
+		{name: "paper falls back to spigot", platform: "paper", loaders: []string{"spigot", "fabric"}, want: []string{"spigot"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fakeModrinthVersions(t, tt.loaders...)
+
+			var got []string
+			for _, alt := range findAlternativeModrinthVersions(client, "project", "1.21", tt.platform) {
+				got = append(got, alt.platform)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("alternatives for %s = %v, want %v", tt.platform, got, tt.want)
+			}
+		})
+	}
+}
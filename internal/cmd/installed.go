@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/storrealbac/mpm/internal/jar"
+	"github.com/storrealbac/mpm/internal/models"
+	"github.com/storrealbac/mpm/internal/utils"
+)
+
+// installedJar is a jar in the plugins directory and the descriptor read from it
+type installedJar struct {
+	File       string
+	Descriptor *jar.Descriptor // nil if the jar has no readable descriptor
+	Err        error
+}
+
+// scanPluginsDir reads the descriptor serverType loads from every jar in dir,
+// sorted by filename
+func scanPluginsDir(dir, serverType string) []installedJar {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var jars []installedJar
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(strings.ToLower(entry.Name()), ".jar") {
+			continue
+		}
+		desc, err := jar.ReadFor(filepath.Join(dir, entry.Name()), serverType)
+		jars = append(jars, installedJar{File: entry.Name(), Descriptor: desc, Err: err})
+	}
+
+	sort.Slice(jars, func(i, j int) bool { return jars[i].File < jars[j].File })
+	return jars
+}
+
+// findInstalledJar returns the jar of a package.yml entry: its local file, the
+// file recorded in the lock, or else the jar whose descriptor declares the
+// plugin's name or slug
+func findInstalledJar(plugin models.Plugin, lock models.PluginLock, jars []installedJar) *installedJar {
+	if installed := findLockedJar(plugin, lock, jars); installed != nil || plugin.File != "" {
+		return installed
+	}
+
+	wanted := map[string]bool{
+		utils.NormalizeName(plugin.Name):        true,
+		utils.NormalizeName(pluginSlug(plugin)): true,
+	}
+	for i := range jars {
+		desc := jars[i].Descriptor
+		if desc == nil {
+			continue
+		}
+		if wanted[utils.NormalizeName(desc.Name)] || (desc.ID != "" && wanted[utils.NormalizeName(desc.ID)]) {
+			return &jars[i]
+		}
+	}
+
+	return nil
+}
+
+// findLockedJar returns the jar mpm knows belongs to a package.yml entry: its
+// local file or the file recorded in the lock
+func findLockedJar(plugin models.Plugin, lock models.PluginLock, jars []installedJar) *installedJar {
+	// Local entries name their jar directly
+	file := lock.File
+	if plugin.File != "" {
+		file = plugin.File
+	}
+	if file == "" {
+		return nil
+	}
+
+	for i := range jars {
+		if jars[i].File == file {
+			return &jars[i]
+		}
+	}
+	return nil
+}
+
+// installedFile returns the jar filename, or "" if the plugin isn't installed
+func installedFile(installed *installedJar) string {
+	if installed == nil {
+		return ""
+	}
+	return installed.File
+}
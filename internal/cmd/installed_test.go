@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/storrealbac/mpm/internal/jar"
+	"github.com/storrealbac/mpm/internal/models"
+)
+
+func TestFindInstalledJar(t *testing.T) {
+	jars := []installedJar{
+		{File: "EssentialsX-2.20.1.jar", Descriptor: &jar.Descriptor{Name: "Essentials"}},
+		{File: "LuckPerms-Bukkit-5.4.jar", Descriptor: &jar.Descriptor{Name: "LuckPerms"}},
+		{File: "broken.jar"},
+		{File: "geyser-velocity.jar", Descriptor: &jar.Descriptor{ID: "geyser", Name: "Geyser-Velocity"}},
+	}
+
+	tests := []struct {
+		name   string
+		plugin models.Plugin
+		lock   models.PluginLock
+		locked string // what findLockedJar returns, "" for nil
+		want   string // what findInstalledJar returns, "" for nil
+	}{
+		{
+			name:   "locked file",
+			plugin: models.Plugin{Name: "Whatever", ModrinthID: "luckperms"},
+			lock:   models.PluginLock{File: "EssentialsX-2.20.1.jar"},
+			locked: "EssentialsX-2.20.1.jar",
+			want:   "EssentialsX-2.20.1.jar",
+		},
+		{
+			name:   "local file",
+			plugin: models.Plugin{Name: "Broken", File: "broken.jar"},
+			locked: "broken.jar",
+			want:   "broken.jar",
+		},
+		{
+			name:   "missing local file is not guessed",
+			plugin: models.Plugin{Name: "LuckPerms", File: "gone.jar"},
+		},
+		{
+			name:   "descriptor name",
+			plugin: models.Plugin{Name: "LuckPerms", ModrinthID: "Vebnzrzj"},
+			want:   "LuckPerms-Bukkit-5.4.jar",
+		},
+		{
+			name:   "descriptor name matches the hangar slug",
+			plugin: models.Plugin{Name: "EssentialsX", HangarID: "EssentialsX/Essentials"},
+			want:   "EssentialsX-2.20.1.jar",
+		},
+		{
+			name:   "velocity plugin id",
+			plugin: models.Plugin{Name: "Geyser", HangarID: "GeyserMC/Geyser"},
+			want:   "geyser-velocity.jar",
+		},
+		{
+			name:   "stale lock falls back to the descriptor",
+			plugin: models.Plugin{Name: "LuckPerms", ModrinthID: "luckperms"},
+			lock:   models.PluginLock{File: "LuckPerms-Bukkit-5.3.jar"},
+			want:   "LuckPerms-Bukkit-5.4.jar",
+		},
+		{
+			name:   "not installed",
+			plugin: models.Plugin{Name: "ViaVersion", ModrinthID: "viaversion"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := installedFile(findLockedJar(tt.plugin, tt.lock, jars)); got != tt.locked {
+				t.Errorf("findLockedJar() = %q, want %q", got, tt.locked)
+			}
+			if got := installedFile(findInstalledJar(tt.plugin, tt.lock, jars)); got != tt.want {
+				t.Errorf("findInstalledJar() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/storrealbac/mpm/internal/config"
+	"github.com/storrealbac/mpm/internal/models"
+	"github.com/storrealbac/mpm/internal/server"
+	"github.com/storrealbac/mpm/internal/ui"
+)
+
+var javaCmd = &cobra.Command{
+	Use:   "java",
+	Short: "Find and install Java runtimes",
+	Long: `Find the Java runtimes on this machine and install Temurin JDKs.
+
+Minecraft 1.20.5 and later need Java 21, 1.18 to 1.20.4 need Java 17, 1.17
+needs Java 16 and older versions Java 8. mpm serve runs the server with the
+installed Java that matches server.minecraft_version.`,
+}
+
+var javaListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the Java runtimes mpm can use",
+	Long: `List the Java runtimes found in $MPM_JAVA, $JAVA_HOME, the PATH, the JDKs
+installed by mpm and the usual system locations, and show which one mpm serve
+would use for the server in package.yml.`,
+	RunE: runJavaList,
+}
+
+var javaInstallCmd = &cobra.Command{
+	Use:   "install [major-version]",
+	Short: "Download a Temurin JDK",
+	Long: `Download the latest Eclipse Temurin JDK of a Java major version from Adoptium
+into mpm's cache directory. Without a version, the one required by
+server.minecraft_version is installed.
+
+Examples:
+  mpm java install
+  mpm java install 21`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runJavaInstall,
+}
+
+func init() {
+	javaCmd.AddCommand(javaListCmd)
+	javaCmd.AddCommand(javaInstallCmd)
+	rootCmd.AddCommand(javaCmd)
+}
+
+func runJavaList(cmd *cobra.Command, args []string) error {
+	required := 0
+	if pkg, err := models.LoadPackageFromFile("package.yml"); err == nil {
+		required = requiredJava(pkg.Server)
+	}
+
+	installs := server.DetectJava()
+	if len(installs) == 0 {
+		ui.PrintWarning("No Java runtime found. Run 'mpm java install' to download one.")
+		return nil
+	}
+	selected := server.SelectJava(installs, required)
+
+	table := ui.NewTable("VERSION", "SOURCE", "PATH", "")
+	for i, install := range installs {
+		mark := ""
+		if selected == &installs[i] {
+			mark = ui.CreateStatusBadge("SELECTED")
+		}
+		table.AddRow(install.Version, install.Source, install.Path, mark)
+	}
+	fmt.Fprintln(ui.Out, table.Render())
+	fmt.Fprintln(ui.Out)
+
+	if required > 0 {
+		ui.PrintInfo("The server needs Java %d.", required)
+	}
+	if problem := javaProblem(required, selected); problem != "" {
+		ui.PrintWarning("%s", problem)
+	}
+	return nil
+}
+
+func runJavaInstall(cmd *cobra.Command, args []string) error {
+	pkg, pkgErr := models.LoadPackageFromFile("package.yml")
+
+	var major int
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 8 {
+			return fmt.Errorf("invalid Java version: %s", args[0])
+		}
+		major = n
+	} else {
+		if pkgErr != nil {
+			return fmt.Errorf("could not read package.yml: %w\nPass the Java version, e.g. 'mpm java install 21'", pkgErr)
+		}
+		major = requiredJava(pkg.Server)
+		if major == 0 {
+			return fmt.Errorf("don't know which Java %s %s needs, pass the version", pkg.Server.Type, pkg.Server.MinecraftVersion)
+		}
+	}
+
+	endpoints, err := config.ResolveEndpoints(pkg)
+	if err != nil {
+		return fmt.Errorf("error loading mpm config: %w", err)
+	}
+
+	ui.PrintInfo("Installing Temurin JDK %d...", major)
+	install, err := server.InstallJDK(major, endpoints.Adoptium)
+	if err != nil {
+		return err
+	}
+
+	ui.PrintSuccess("Java %s ready (%s)", install.Version, install.Path)
+	return nil
+}
+
+// requiredJava is the Java major version the server needs, 0 if unknown.
+// Proxies are versioned on their own and don't follow Minecraft's requirements.
+func requiredJava(serverConfig models.ServerConfig) int {
+	if serverConfig.IsProxy() {
+		return 0
+	}
+	return server.RequiredJava(serverConfig.MinecraftVersion)
+}
+
+// javaProblem describes why the selected Java can't run a server that needs
+// Java required, or returns ""
+func javaProblem(required int, selected *server.JavaInstall) string {
+	switch {
+	case selected == nil && required > 0:
+		return fmt.Sprintf("No Java runtime found, the server needs Java %d. Run 'mpm java install'.", required)
+	case selected == nil:
+		return "No Java runtime found. Run 'mpm java install <version>'."
+	case selected.Major < required:
+		return fmt.Sprintf("The server needs Java %d but %s is Java %d. Run 'mpm java install' or set MPM_JAVA.", required, selected.Path, selected.Major)
+	}
+	return ""
+}
+
+// installerJava is the java that runs the installers and BuildTools for a server:
+// server.java, or the installed Java that matches its Minecraft version.
+// It returns "" to let the downloader fall back to the java on the PATH.
+func installerJava(serverConfig models.ServerConfig) string {
+	if !server.RunsJava(serverConfig.Type) {
+		return ""
+	}
+	required := requiredJava(serverConfig)
+	java := serverJava(serverConfig, required)
+	if problem := javaProblem(required, java); problem != "" {
+		ui.PrintWarning("%s", problem)
+	}
+	if java == nil {
+		return ""
+	}
+	return java.Path
+}
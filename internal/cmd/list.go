@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/storrealbac/mpm/internal/models"
@@ -42,45 +41,80 @@ func runList(cmd *cobra.Command, args []string) error {
 
 	ui.PrintHeader("Plugin List")
 
+	// Load lock for hashes in structured output
+	lockFile, err := models.LoadPackageLockFromFile("package-lock.yml")
+	if err != nil {
+		return fmt.Errorf("error loading package-lock.yml: %w", err)
+	}
+
 	// Create table
-	table := ui.NewTable("NAME", "VERSION", "STATUS")
+	table := ui.NewTable("NAME", "VERSION", "STATUS", "JAR")
+	result := commandResult{Command: "list", Success: true, Plugins: []pluginResult{}}
+	jars := scanPluginsDir(pkg.Server.ContentDir(), pkg.Server.Type)
 
 	for _, plugin := range pkg.Plugins {
 		var status string
-		// Check if installed using normalized name matching
-		found := false
-		files, _ := os.ReadDir("plugins")
-
-		// Normalize plugin name: remove special chars, replace spaces with dashes, lowercase
-		normalizedPluginName := normalizePluginName(plugin.Name)
-
-		for _, file := range files {
-			normalizedFileName := normalizePluginName(file.Name())
-			// Check if filename starts with normalized plugin name
-			if strings.HasPrefix(normalizedFileName, normalizedPluginName) {
-				found = true
-				break
+		// Find the jar by lock filename or the name declared in its descriptor
+		source, id := pluginSourceAndID(plugin)
+		installed := findInstalledJar(plugin, lockFile.Plugins[id], jars)
+
+		statusText := "MISSING"
+		jarInfo := "-"
+		if installed != nil {
+			statusText = "INSTALLED"
+			jarInfo = installed.File
+			if installed.Descriptor != nil {
+				jarInfo = fmt.Sprintf("%s (%s %s)", installed.File, installed.Descriptor.Name, installed.Descriptor.Version)
 			}
 		}
+		status = ui.CreateStatusBadge(statusText)
+
+		result.Plugins = append(result.Plugins, pluginResult{
+			Plugin:  plugin.Name,
+			ID:      id,
+			Source:  source,
+			Version: plugin.Version,
+			Status:  strings.ToLower(statusText),
+			File:    installedFile(installed),
+			Hash:    lockFile.Plugins[id].Hash,
+		})
 
-		if found {
-			status = ui.CreateStatusBadge("INSTALLED")
-		} else {
-			status = ui.CreateStatusBadge("MISSING")
+		// Add data (table handles styling internally)
+		table.AddRow(plugin.Name, plugin.Version, status, jarInfo)
+	}
+
+	// Jars on disk that package.yml doesn't account for
+	unmanaged := findUnmanagedJars(pkg, lockFile, jars)
+	for _, j := range unmanaged {
+		name, version := j.File, "-"
+		if j.Descriptor != nil {
+			name, version = j.Descriptor.Name, j.Descriptor.Version
 		}
+		result.Plugins = append(result.Plugins, pluginResult{
+			Plugin:  name,
+			Version: version,
+			Status:  "unmanaged",
+			File:    j.File,
+			Error:   j.Reason,
+		})
+		table.AddRow(name, version, ui.CreateStatusBadge("UNMANAGED"), j.File)
+	}
 
-		// Add data (table handles styling internally)
-		table.AddRow(plugin.Name, plugin.Version, status)
+	if ui.IsStructured() {
+		return ui.PrintResult(result)
 	}
 
 	// Render the table
-	fmt.Println(table.Render())
-	fmt.Println()
+	fmt.Fprintln(ui.Out, table.Render())
+	fmt.Fprintln(ui.Out)
 
 	// Summary
 	totalPlugins := len(pkg.Plugins)
 	summary := fmt.Sprintf("Total plugins: %d", totalPlugins)
 	ui.PrintInfo(summary)
+	if len(unmanaged) > 0 {
+		ui.PrintWarning("%d unmanaged jars in %s/, run 'mpm prune' to quarantine them.", len(unmanaged), pkg.Server.ContentDir())
+	}
 
 	return nil
 }
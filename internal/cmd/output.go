@@ -0,0 +1,40 @@
+package cmd
+
+import "github.com/storrealbac/mpm/internal/models"
+
+// Structured results emitted with --output json|yaml
+
+// pluginResult describes the outcome of a command for a single plugin
+type pluginResult struct {
+	Plugin  string `json:"plugin" yaml:"plugin"`
+	ID      string `json:"id,omitempty" yaml:"id,omitempty"`
+	Source  string `json:"source,omitempty" yaml:"source,omitempty"` // modrinth, hangar or local
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+	Latest  string `json:"latest,omitempty" yaml:"latest,omitempty"`
+	Status  string `json:"status" yaml:"status"`
+	File    string `json:"file,omitempty" yaml:"file,omitempty"`
+	Hash    string `json:"hash,omitempty" yaml:"hash,omitempty"`
+	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// commandResult is the top-level document printed by each command
+type commandResult struct {
+	Command string         `json:"command" yaml:"command"`
+	Success bool           `json:"success" yaml:"success"`
+	Plugins []pluginResult `json:"plugins" yaml:"plugins"`
+	Errors  []string       `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// pluginSourceAndID returns the source name and ID of a package.yml plugin entry
+func pluginSourceAndID(plugin models.Plugin) (string, string) {
+	if plugin.ModrinthID != "" {
+		return "modrinth", plugin.ModrinthID
+	}
+	if plugin.HangarID != "" {
+		return "hangar", plugin.HangarID
+	}
+	if plugin.File != "" {
+		return "local", plugin.File
+	}
+	return "", ""
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"crypto/sha512"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/storrealbac/mpm/internal/sources"
+	"github.com/storrealbac/mpm/internal/ui"
+	"gopkg.in/yaml.v3"
+)
+
+// captureResult runs a command with --output format and decodes the document it
+// prints to stdout
+func captureResult(t *testing.T, format string, run func() error) (commandResult, error) {
+	t.Helper()
+	stdout, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdout.Close()
+
+	saved := os.Stdout
+	os.Stdout = stdout
+	if err := ui.SetOutputFormat(format); err != nil {
+		t.Fatal(err)
+	}
+	runErr := run()
+	os.Stdout = saved
+	ui.SetOutputFormat(ui.OutputText)
+
+	data, err := os.ReadFile(stdout.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result commandResult
+	if format == ui.OutputYAML {
+		err = yaml.Unmarshal(data, &result)
+	} else {
+		err = json.Unmarshal(data, &result)
+	}
+	if err != nil {
+		t.Fatalf("stdout is not a %s document: %v\n%s", format, err, data)
+	}
+	return result, runErr
+}
+
+// writeOutputProject creates a Velocity project with a Modrinth plugin, a missing
+// Hangar plugin, a local jar and an unmanaged jar, and returns the Modrinth jar's hash
+func writeOutputProject(t *testing.T) string {
+	t.Helper()
+	chdirTemp(t)
+	t.Setenv("MPM_CONFIG", filepath.Join(t.TempDir(), "config.yml"))
+	writeFiles(t, map[string]string{"plugins/": ""})
+	writePluginJar(t, "plugins", "TAB-4.0.0.jar", map[string]string{"velocity-plugin.json": `{"id": "tab", "name": "TAB", "version": "4.0.0"}`})
+	writePluginJar(t, "plugins", "custom.jar", map[string]string{"velocity-plugin.json": `{"id": "custom", "version": "1.0"}`})
+	writePluginJar(t, "plugins", "stray.jar", map[string]string{"velocity-plugin.json": `{"id": "stray", "version": "0.1"}`})
+
+	hash := hashFile(filepath.Join("plugins", "TAB-4.0.0.jar"), sha512.New)
+	writeFiles(t, map[string]string{
+		"package.yml": `name: proxy
+version: 1.0.0
+server:
+  type: velocity
+plugins:
+  - name: TAB
+    version: 4.0.0
+    modrinth_id: tab
+  - name: Geyser
+    version: "2.4"
+    hangar_id: GeyserMC/Geyser
+  - name: Custom
+    version: "1.0"
+    file: custom.jar
+`,
+		"package-lock.yml": fmt.Sprintf(`plugins:
+  tab:
+    name: TAB
+    version: 4.0.0
+    hash: %s
+    file: TAB-4.0.0.jar
+`, hash),
+	})
+	return hash
+}
+
+func TestListOutput(t *testing.T) {
+	for _, format := range []string{ui.OutputJSON, ui.OutputYAML} {
+		t.Run(format, func(t *testing.T) {
+			hash := writeOutputProject(t)
+
+			result, err := captureResult(t, format, func() error { return runList(listCmd, nil) })
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := commandResult{Command: "list", Success: true, Plugins: []pluginResult{
+				{Plugin: "TAB", ID: "tab", Source: "modrinth", Version: "4.0.0", Status: "installed", File: "TAB-4.0.0.jar", Hash: hash},
+				{Plugin: "Geyser", ID: "GeyserMC/Geyser", Source: "hangar", Version: "2.4", Status: "missing"},
+				{Plugin: "Custom", ID: "custom.jar", Source: "local", Version: "1.0", Status: "installed", File: "custom.jar"},
+				{Plugin: "stray", Version: "0.1", Status: "unmanaged", File: "stray.jar"},
+			}}
+			checkResult(t, result, want)
+		})
+	}
+}
+
+func TestValidateOutput(t *testing.T) {
+	hash := writeOutputProject(t)
+
+	result, err := captureResult(t, ui.OutputJSON, func() error { return runValidate(validateCmd, nil) })
+	if err == nil {
+		t.Error("validate succeeded with a missing plugin")
+	}
+	want := commandResult{Command: "validate", Success: false, Plugins: []pluginResult{
+		{Plugin: "TAB", ID: "tab", Source: "modrinth", Version: "4.0.0", Status: "ok", File: "TAB-4.0.0.jar", Hash: hash},
+		{Plugin: "Geyser", ID: "GeyserMC/Geyser", Source: "hangar", Version: "2.4", Status: "missing"},
+		{Plugin: "Custom", ID: "custom.jar", Source: "local", Version: "1.0", Status: "ok", File: "custom.jar"},
+		{Plugin: "stray", Status: "unmanaged", File: "stray.jar"},
+	}, Errors: []string{"1 plugins missing"}}
+	checkResult(t, result, want)
+}
+
+func TestUpdateCheckOutput(t *testing.T) {
+	hash := writeOutputProject(t)
+	writeFiles(t, map[string]string{"package.yml": "name: proxy\nversion: 1.0.0\nserver:\n  type: velocity\nplugins:\n  - name: TAB\n    version: 4.0.0\n    modrinth_id: tab\n  - name: Custom\n    version: \"1.0\"\n    file: custom.jar\n"})
+
+	modrinth := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/project/tab/version" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode([]sources.ModrinthVersion{{
+			VersionNumber: "4.1.0",
+			Loaders:       []string{"velocity"},
+			Files:         []sources.ModrinthFile{{URL: "http://example.invalid/TAB-4.1.0.jar", Filename: "TAB-4.1.0.jar", Primary: true}},
+		}})
+	}))
+	defer modrinth.Close()
+	t.Setenv("MPM_MODRINTH_URL", modrinth.URL)
+
+	checkOnly = true
+	defer func() { checkOnly = false }()
+
+	result, err := captureResult(t, ui.OutputJSON, func() error { return runUpdate(updateCmd, nil) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := commandResult{Command: "update", Success: true, Plugins: []pluginResult{
+		{Plugin: "TAB", ID: "tab", Source: "modrinth", Version: "4.0.0", Latest: "4.1.0", Status: "outdated", Hash: hash},
+	}}
+	checkResult(t, result, want)
+}
+
+// checkResult compares a decoded result with want, only checking that unmanaged
+// jars come with a reason
+func checkResult(t *testing.T, got, want commandResult) {
+	t.Helper()
+	for i, p := range got.Plugins {
+		if p.Status == "unmanaged" {
+			if p.Error == "" {
+				t.Errorf("unmanaged %s has no reason", p.File)
+			}
+			got.Plugins[i].Error = ""
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("result =\n%+v\nwant\n%+v", got, want)
+	}
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+
+	"github.com/storrealbac/mpm/internal/ui"
+)
+
+var (
+	assumeYes      bool // --yes: answer yes to confirmations
+	nonInteractive bool // --non-interactive: never read from stdin
+)
+
+// stdinReader is shared so buffered input isn't lost between prompts
+var stdinReader = bufio.NewReader(os.Stdin)
+
+// isInteractive reports whether mpm may prompt the user.
+// Prompts are disabled by --yes, --non-interactive, or when stdin is not a terminal.
+func isInteractive() bool {
+	if assumeYes || nonInteractive {
+		return false
+	}
+
+	fd := os.Stdin.Fd()
+	return isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd)
+}
+
+// readLine reads a trimmed line from stdin
+func readLine() string {
+	input, _ := stdinReader.ReadString('\n')
+	return strings.TrimSpace(input)
+}
+
+// confirm asks a yes/no question. In non-interactive mode it returns the --yes value
+// without prompting.
+func confirm(question string) bool {
+	if !isInteractive() {
+		return assumeYes
+	}
+
+	fmt.Fprintf(ui.Out, "%s (y/N): ", question)
+	response := strings.ToLower(readLine())
+	return response == "y" || response == "yes"
+}
+
+// promptSelection asks the user to pick one of count options (1-based).
+// Returns the 0-based index and false if the user cancelled.
+func promptSelection(prompt string, count int) (int, bool) {
+	fmt.Fprintf(ui.Out, "%s (1-%d) or press Enter to cancel: ", prompt, count)
+	input := readLine()
+
+	var choice int
+	if _, err := fmt.Sscanf(input, "%d", &choice); err == nil && choice >= 1 && choice <= count {
+		return choice - 1, true
+	}
+	return 0, false
+}
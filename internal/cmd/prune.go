@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/storrealbac/mpm/internal/models"
+	"github.com/storrealbac/mpm/internal/ui"
+)
+
+var (
+	pruneQuarantineDir string
+	pruneDryRun        bool
+)
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Quarantine unmanaged jars",
+	Long: `Moves jars in plugins/ (mods/ on mod loaders) that package.yml doesn't
+account for (added by hand, or left behind by removed plugins and older
+versions) into a timestamped quarantine folder, and drops package-lock.yml
+entries of plugins that are no longer in package.yml.
+
+Nothing is deleted: move a jar back where it came from to restore it.
+
+Examples:
+  mpm prune --dry-run
+  mpm prune
+  mpm prune --quarantine /backups/quarantine`,
+	RunE: runPrune,
+}
+
+func init() {
+	pruneCmd.Flags().StringVar(&pruneQuarantineDir, "quarantine", "quarantine", "Folder where unmanaged jars are moved")
+	pruneCmd.Flags().BoolVar(&pruneDryRun, "dry-run", false, "Only show what would be moved")
+
+	pruneCmd.SetUsageTemplate(fmt.Sprintf(`%s
+  {{.UseLine}}
+
+%s
+{{.Flags.FlagUsages | trimTrailingWhitespaces}}
+`,
+		ui.SectionStyle.Render("Usage:"),
+		ui.SectionStyle.Render("Flags:"),
+	))
+
+	rootCmd.AddCommand(pruneCmd)
+}
+
+func runPrune(cmd *cobra.Command, args []string) error {
+	pkg, err := models.LoadPackageFromFile("package.yml")
+	if err != nil {
+		return fmt.Errorf("could not read package.yml: %w", err)
+	}
+
+	lockFile, err := models.LoadPackageLockFromFile("package-lock.yml")
+	if err != nil {
+		return fmt.Errorf("error loading package-lock.yml: %w", err)
+	}
+
+	pluginsDir := pkg.Server.ContentDir()
+	unmanaged := findUnmanagedJars(pkg, lockFile, scanPluginsDir(pluginsDir, pkg.Server.Type))
+	orphaned := orphanedLockEntries(pkg, lockFile)
+	result := commandResult{Command: "prune", Success: true, Plugins: []pluginResult{}}
+
+	if len(unmanaged) == 0 && len(orphaned) == 0 {
+		if ui.IsStructured() {
+			return ui.PrintResult(result)
+		}
+		ui.PrintSuccess("Nothing to prune: every jar in %s/ is managed.", pluginsDir)
+		return nil
+	}
+
+	// Each run gets its own folder so earlier quarantines are never overwritten
+	destDir := filepath.Join(pruneQuarantineDir, time.Now().Format("20060102-150405"))
+	if !pruneDryRun && len(unmanaged) > 0 {
+		if err := os.MkdirAll(destDir, 0755); err != nil {
+			return fmt.Errorf("could not create quarantine folder %s: %w", destDir, err)
+		}
+	}
+
+	for _, j := range unmanaged {
+		entry := pluginResult{Plugin: j.File, File: j.File, Error: j.Reason}
+		if j.Descriptor != nil {
+			entry.Plugin = j.Descriptor.Name
+			entry.Version = j.Descriptor.Version
+		}
+
+		if pruneDryRun {
+			ui.PrintInfo("Would quarantine %s (%s)", unmanagedLabel(j), j.Reason)
+			entry.Status = "unmanaged"
+			result.Plugins = append(result.Plugins, entry)
+			continue
+		}
+
+		if err := os.Rename(filepath.Join(pluginsDir, j.File), filepath.Join(destDir, j.File)); err != nil {
+			ui.PrintError("Could not move %s: %v", j.File, err)
+			entry.Status = "error"
+			entry.Error = err.Error()
+			result.Success = false
+		} else {
+			ui.PrintSuccess("Quarantined %s (%s)", unmanagedLabel(j), j.Reason)
+			entry.Status = "quarantined"
+		}
+		result.Plugins = append(result.Plugins, entry)
+	}
+
+	// Forget lock entries of plugins removed from package.yml
+	for _, key := range orphaned {
+		if pruneDryRun {
+			ui.PrintInfo("Would drop lock entry %s (not in package.yml)", key)
+			continue
+		}
+		delete(lockFile.Plugins, key)
+		ui.PrintSuccess("Dropped lock entry %s (not in package.yml)", key)
+	}
+
+	if !pruneDryRun && len(orphaned) > 0 {
+		if err := lockFile.SaveToFile("package-lock.yml"); err != nil {
+			return fmt.Errorf("error saving package-lock.yml: %w", err)
+		}
+	}
+
+	if ui.IsStructured() {
+		if err := ui.PrintResult(result); err != nil {
+			return err
+		}
+	} else if !pruneDryRun && len(unmanaged) > 0 {
+		ui.PrintInfo("Moved to %s. Move a jar back into %s/ to restore it.", destDir, pluginsDir)
+	}
+
+	if !result.Success {
+		return fmt.Errorf("some jars could not be quarantined")
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/storrealbac/mpm/internal/sources"
+	"github.com/storrealbac/mpm/internal/utils"
+)
+
+// modrinthSearchHit converts a Modrinth search hit, recording platform compatibility
+func modrinthSearchHit(p sources.ModrinthProject, serverType string) searchHit {
+	compatible, exact := sources.ModrinthIsPluginCompatible(&p, serverType)
+	return searchHit{
+		Source:        "modrinth",
+		Name:          p.Title,
+		ID:            p.Slug,
+		Description:   p.Description,
+		Downloads:     p.Downloads,
+		Stars:         p.Follows,
+		Updated:       p.DateModified,
+		slug:          p.Slug,
+		compatible:    compatible,
+		exactPlatform: exact,
+	}
+}
+
+// hangarSearchHit converts a Hangar search hit, recording platform compatibility
+func hangarSearchHit(p sources.HangarProject, serverType string) searchHit {
+	compatible, exact := sources.HangarIsPluginCompatible(&p, serverType)
+	return searchHit{
+		Source:        "hangar",
+		Name:          p.Name,
+		ID:            fmt.Sprintf("%s/%s", p.Namespace.Owner, p.Namespace.Slug),
+		Description:   p.Description,
+		Downloads:     p.Stats.Downloads,
+		Stars:         p.Stats.Stars,
+		Updated:       p.LastUpdated,
+		slug:          p.Namespace.Slug,
+		compatible:    compatible,
+		exactPlatform: exact,
+	}
+}
+
+// rankSearchHits scores hits against the query, sorts them best first and merges
+// projects published on both Modrinth and Hangar into a single entry
+func rankSearchHits(query string, hits []searchHit) []searchHit {
+	for i := range hits {
+		hits[i].Score = utils.RelevanceScore(utils.RankInput{
+			Query:         query,
+			Name:          hits[i].Name,
+			Slug:          hits[i].slug,
+			Downloads:     hits[i].Downloads,
+			Compatible:    hits[i].compatible,
+			ExactPlatform: hits[i].exactPlatform,
+		})
+		hits[i].exact = utils.IsExactMatch(query, hits[i].Name, hits[i].slug)
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].Score > hits[j].Score
+	})
+
+	return dedupeSearchHits(hits)
+}
+
+// dedupeSearchHits keeps the best-ranked entry of each project, listing the other
+// sources it is published on. hits must already be sorted best first.
+func dedupeSearchHits(hits []searchHit) []searchHit {
+	deduped := make([]searchHit, 0, len(hits))
+	index := make(map[string]int) // normalized name -> position in deduped
+
+	for _, hit := range hits {
+		key := utils.NormalizeName(hit.Name)
+		if pos, ok := index[key]; ok && key != "" && deduped[pos].Source != hit.Source {
+			deduped[pos].AlsoOn = append(deduped[pos].AlsoOn, fmt.Sprintf("%s:%s", hit.Source, hit.ID))
+			continue
+		}
+		if _, ok := index[key]; !ok {
+			index[key] = len(deduped)
+		}
+		deduped = append(deduped, hit)
+	}
+
+	return deduped
+}
+
+// alsoOnLabel renders the other sources of a deduplicated hit ("also on Hangar")
+func alsoOnLabel(hit searchHit) string {
+	if len(hit.AlsoOn) == 0 {
+		return ""
+	}
+
+	names := make([]string, 0, len(hit.AlsoOn))
+	for _, other := range hit.AlsoOn {
+		source, _, _ := strings.Cut(other, ":")
+		names = append(names, strings.ToUpper(source))
+	}
+	return "also on " + strings.Join(names, ", ")
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRankSearchHits(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		hits   []searchHit
+		want   []string // source:id, best first
+		alsoOn map[string][]string
+	}{
+		{
+			name:  "exact match first",
+			query: "luckperms",
+			hits: []searchHit{
+				{Source: "modrinth", Name: "LuckPerms Addon", ID: "lp-addon", slug: "lp-addon", compatible: true},
+				{Source: "modrinth", Name: "LuckPerms", ID: "luckperms", slug: "luckperms", compatible: true},
+			},
+			want: []string{"modrinth:luckperms", "modrinth:lp-addon"},
+		},
+		{
+			name:  "same project on both sources is merged",
+			query: "luckperms",
+			hits: []searchHit{
+				{Source: "hangar", Name: "LuckPerms", ID: "Luck/LuckPerms", slug: "LuckPerms", compatible: true},
+				{Source: "modrinth", Name: "LuckPerms", ID: "luckperms", slug: "luckperms", compatible: true, exactPlatform: true},
+			},
+			want:   []string{"modrinth:luckperms"},
+			alsoOn: map[string][]string{"modrinth:luckperms": {"hangar:Luck/LuckPerms"}},
+		},
+		{
+			name:  "same name on one source is kept twice",
+			query: "chat",
+			hits: []searchHit{
+				{Source: "modrinth", Name: "Chat", ID: "chat-a", slug: "chat-a", compatible: true, Downloads: 100},
+				{Source: "modrinth", Name: "Chat", ID: "chat-b", slug: "chat-b", compatible: true},
+			},
+			want: []string{"modrinth:chat-a", "modrinth:chat-b"},
+		},
+		{
+			name:  "incompatible hits sink",
+			query: "geyser",
+			hits: []searchHit{
+				{Source: "modrinth", Name: "Geyser Extras", ID: "geyser-extras-fabric", slug: "geyser-extras-fabric"},
+				{Source: "modrinth", Name: "Geyser Extras", ID: "geyser-extras", slug: "geyser-extras", compatible: true},
+			},
+			want: []string{"modrinth:geyser-extras", "modrinth:geyser-extras-fabric"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ranked := rankSearchHits(tt.query, tt.hits)
+
+			var got []string
+			for _, hit := range ranked {
+				key := hit.Source + ":" + hit.ID
+				got = append(got, key)
+				if want := tt.alsoOn[key]; !reflect.DeepEqual(hit.AlsoOn, want) {
+					t.Errorf("%s also on %v, want %v", key, hit.AlsoOn, want)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ranked %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAlsoOnLabel(t *testing.T) {
+	tests := []struct {
+		alsoOn []string
+		want   string
+	}{
+		{nil, ""},
+		{[]string{"hangar:Luck/LuckPerms"}, "also on HANGAR"},
+		{[]string{"hangar:a/b", "modrinth:c"}, "also on HANGAR, MODRINTH"},
+	}
+	for _, tt := range tests {
+		if got := alsoOnLabel(searchHit{AlsoOn: tt.alsoOn}); got != tt.want {
+			t.Errorf("alsoOnLabel(%v) = %q, want %q", tt.alsoOn, got, tt.want)
+		}
+	}
+}
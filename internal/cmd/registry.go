@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/storrealbac/mpm/internal/registry"
+	"github.com/storrealbac/mpm/internal/ui"
+)
+
+var (
+	registryDir       string
+	registryAddr      string
+	registryPublicURL string
+)
+
+var registryCmd = &cobra.Command{
+	Use:   "registry",
+	Short: "Host a local plugin registry",
+	Long: `Host a local plugin registry that speaks a Modrinth v2 compatible API.
+
+Point mpm at it with the endpoints.modrinth setting or MPM_MODRINTH_URL.`,
+}
+
+var registryServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve a directory of approved plugins",
+	Long: `Serve a directory of plugin jars plus metadata as a Modrinth v2 compatible API.
+
+Each plugin lives in its own directory with a project.yml describing it:
+
+  registry/
+    luckperms/
+      project.yml
+      LuckPerms-Bukkit-5.4.131.jar
+
+Example project.yml:
+
+  slug: luckperms
+  title: LuckPerms
+  description: A permissions plugin
+  author: lucko
+  categories: [paper, spigot, bukkit]
+  versions:
+    - version_number: 5.4.131
+      game_versions: [1.20.4, 1.21]
+      loaders: [paper, spigot, bukkit]
+      file: LuckPerms-Bukkit-5.4.131.jar
+
+Examples:
+  mpm registry serve --dir registry --addr :8080
+  MPM_MODRINTH_URL=http://localhost:8080/v2 mpm install luckperms`,
+	RunE: runRegistryServe,
+}
+
+func init() {
+	registryServeCmd.Flags().StringVar(&registryDir, "dir", "registry", "Directory containing the plugin catalog")
+	registryServeCmd.Flags().StringVar(&registryAddr, "addr", ":8080", "Address to listen on")
+	registryServeCmd.Flags().StringVar(&registryPublicURL, "public-url", "", "Base URL used in download links (defaults to the request host)")
+
+	registryCmd.AddCommand(registryServeCmd)
+	rootCmd.AddCommand(registryCmd)
+}
+
+func runRegistryServe(cmd *cobra.Command, args []string) error {
+	reg, err := registry.Load(registryDir)
+	if err != nil {
+		return fmt.Errorf("could not load registry from %s: %w", registryDir, err)
+	}
+
+	versionCount := 0
+	for _, p := range reg.Projects {
+		versionCount += len(p.Versions)
+	}
+
+	srv := &registry.Server{
+		Registry:  reg,
+		PublicURL: registryPublicURL,
+	}
+
+	ui.PrintSuccess("Loaded %d projects (%d versions) from %s", len(reg.Projects), versionCount, registryDir)
+	ui.PrintInfo("Serving Modrinth v2 API on %s (use <url>/v2 as the Modrinth endpoint)", registryAddr)
+
+	httpServer := &http.Server{
+		Addr:              registryAddr,
+		Handler:           srv.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return httpServer.ListenAndServe()
+}
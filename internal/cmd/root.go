@@ -7,11 +7,20 @@ import (
 	"github.com/storrealbac/mpm/internal/ui"
 )
 
+var outputFormat string
+
 var rootCmd = &cobra.Command{
 	Use:   "mpm",
 	Short: ui.MPMStyle.Render("mpm") + " - Minecraft Plugin Manager - Manage your server plugins with ease",
 	Long: ui.MPMStyle.Render("mpm") + ` is a CLI tool to manage Minecraft server plugins using the Modrinth API.
 It allows you to install, update, and remove plugins, as well as manage the server jar itself.`,
+	// main prints returned errors, so don't let cobra print them twice
+	SilenceErrors: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Arguments parsed fine; runtime errors shouldn't dump usage
+		cmd.SilenceUsage = true
+		return ui.SetOutputFormat(outputFormat)
+	},
 }
 
 func Execute() error {
@@ -36,6 +45,10 @@ Use "{{.CommandPath}} [command] --help" for more information about a command.
 		ui.SectionStyle.Render("Flags:"),
 	))
 
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "text", "Output format: text, json, or yaml")
+	rootCmd.PersistentFlags().BoolVarP(&assumeYes, "yes", "y", false, "Answer yes to confirmations and never prompt")
+	rootCmd.PersistentFlags().BoolVar(&nonInteractive, "non-interactive", false, "Never prompt; fail on ambiguous choices (default when stdin is not a terminal)")
+
 	// Use default help command
 	// The custom help command was causing banner duplication
 
@@ -51,7 +51,7 @@ func runScript(cmd *cobra.Command, args []string) error {
 
 	// Display what we're running
 	ui.PrintInfo("Running script: %s", scriptName)
-	fmt.Printf("  Command: %s\n\n", scriptCommand)
+	fmt.Fprintf(ui.Out, "  Command: %s\n\n", scriptCommand)
 
 	// Execute the script
 	return executeCommand(scriptCommand)
@@ -76,7 +76,7 @@ func executeCommand(command string) error {
 	}
 
 	// Set up stdout/stderr
-	cmd.Stdout = os.Stdout
+	cmd.Stdout = ui.Out
 	cmd.Stderr = os.Stderr
 	cmd.Stdin = os.Stdin
 
@@ -0,0 +1,335 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/storrealbac/mpm/internal/config"
+	"github.com/storrealbac/mpm/internal/models"
+	"github.com/storrealbac/mpm/internal/sources"
+	"github.com/storrealbac/mpm/internal/ui"
+)
+
+var (
+	searchSource       string
+	searchPlatform     string
+	searchMCVersion    string
+	searchCategory     string
+	searchMinDownloads int
+	searchSort         string
+	searchPage         int
+	searchLimit        int
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search plugins on Modrinth and Hangar",
+	Long: `Search plugins on Modrinth and Hangar without modifying package.yml.
+
+The platform and Minecraft version default to the server in package.yml, if present.
+
+Examples:
+  mpm search luckperms
+  mpm search economy --sort downloads --min-downloads 10000
+  mpm search chat --platform velocity --page 2
+  mpm search worldedit --mc-version 1.20.4 --source hangar`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runSearch,
+}
+
+func init() {
+	searchCmd.Flags().StringVar(&searchSource, "source", "auto", "Plugin source: modrinth, hangar, or auto (searches both)")
+	searchCmd.Flags().StringVar(&searchPlatform, "platform", "", "Server platform (paper, purpur, folia, spigot, velocity, fabric, forge...)")
+	searchCmd.Flags().StringVar(&searchMCVersion, "mc-version", "", "Minecraft version the plugin must support")
+	searchCmd.Flags().StringVar(&searchCategory, "category", "", "Category filter (e.g. economy, chat, utility)")
+	searchCmd.Flags().IntVar(&searchMinDownloads, "min-downloads", 0, "Only show plugins with at least this many downloads")
+	searchCmd.Flags().StringVar(&searchSort, "sort", "relevance", "Sort by: relevance, downloads, or updated")
+	searchCmd.Flags().IntVar(&searchPage, "page", 1, "Page of results to show")
+	searchCmd.Flags().IntVar(&searchLimit, "limit", 10, "Results per page and source")
+
+	searchCmd.SetUsageTemplate(fmt.Sprintf(`%s
+  {{.UseLine}}
+
+%s
+{{.Flags.FlagUsages | trimTrailingWhitespaces}}
+`,
+		ui.SectionStyle.Render("Usage:"),
+		ui.SectionStyle.Render("Flags:"),
+	))
+
+	rootCmd.AddCommand(searchCmd)
+}
+
+// searchHit is a search result normalized across sources
+type searchHit struct {
+	Source      string   `json:"source" yaml:"source"`
+	Name        string   `json:"name" yaml:"name"`
+	ID          string   `json:"id" yaml:"id"`
+	Description string   `json:"description" yaml:"description"`
+	Downloads   int      `json:"downloads" yaml:"downloads"`
+	Stars       int      `json:"stars" yaml:"stars"` // Hangar stars or Modrinth follows
+	Updated     string   `json:"updated,omitempty" yaml:"updated,omitempty"`
+	Score       float64  `json:"score" yaml:"score"`
+	AlsoOn      []string `json:"also_on,omitempty" yaml:"also_on,omitempty"` // same project on other sources (source:id)
+
+	slug          string
+	exact         bool // query names this project exactly
+	compatible    bool
+	exactPlatform bool
+}
+
+// searchResult is the structured output of mpm search
+type searchResult struct {
+	Query  string         `json:"query" yaml:"query"`
+	Page   int            `json:"page" yaml:"page"`
+	Total  int            `json:"total" yaml:"total"`
+	Totals map[string]int `json:"totals" yaml:"totals"` // per source
+	Hits   []searchHit    `json:"hits" yaml:"hits"`
+	Errors []string       `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// Hangar pages are fetched this many projects at a time, at most
+// hangarSearchMaxPages times, when filtering by downloads
+const (
+	hangarSearchBatch    = 25
+	hangarSearchMaxPages = 20
+)
+
+func runSearch(cmd *cobra.Command, args []string) error {
+	query := strings.Join(args, " ")
+
+	switch searchSort {
+	case "relevance", "downloads", "updated":
+	default:
+		return fmt.Errorf("invalid --sort value %q (use relevance, downloads, or updated)", searchSort)
+	}
+	if searchPage < 1 {
+		searchPage = 1
+	}
+	if searchLimit < 1 {
+		searchLimit = 10
+	}
+
+	// Default filters come from the local server, when there is one
+	pkg, pkgErr := models.LoadPackageFromFile("package.yml")
+	if pkgErr == nil {
+		if searchPlatform == "" {
+			searchPlatform = pkg.Server.Type
+		}
+		if searchMCVersion == "" {
+			searchMCVersion = pluginGameVersion(pkg.Server)
+		}
+	} else {
+		pkg = nil
+	}
+	platform := strings.ToLower(searchPlatform)
+
+	endpoints, err := config.ResolveEndpoints(pkg)
+	if err != nil {
+		return fmt.Errorf("error loading mpm config: %w", err)
+	}
+
+	result := searchResult{Query: query, Page: searchPage, Totals: map[string]int{}, Hits: []searchHit{}}
+	offset := (searchPage - 1) * searchLimit
+	shownBySource := map[string]int{}
+	totalsExact := true
+
+	spinner := ui.NewSpinner(fmt.Sprintf("Searching for '%s'...", query))
+	spinner.Start()
+
+	if searchSource == "auto" || searchSource == "modrinth" {
+		opts := sources.ModrinthSearchOptions{
+			Query:        query,
+			ServerType:   platform,
+			GameVersion:  searchMCVersion,
+			Index:        searchSort,
+			MinDownloads: searchMinDownloads,
+			Offset:       offset,
+			Limit:        searchLimit,
+		}
+		if searchCategory != "" {
+			opts.Categories = []string{searchCategory}
+		}
+
+		resp, err := sources.NewModrinthClient(endpoints.Modrinth...).Search(opts)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("modrinth: %v", err))
+		} else {
+			result.Totals["modrinth"] = resp.TotalHits
+			shownBySource["modrinth"] = len(resp.Hits)
+			for _, p := range resp.Hits {
+				result.Hits = append(result.Hits, modrinthSearchHit(p, platform))
+			}
+		}
+	}
+
+	// Hangar only hosts plugins
+	if (searchSource == "auto" || searchSource == "hangar") && !models.IsModLoader(platform) {
+		opts := sources.HangarSearchOptions{
+			Query:       query,
+			ServerType:  platform,
+			GameVersion: searchMCVersion,
+			Category:    searchCategory,
+			Offset:      offset,
+			Limit:       searchLimit,
+		}
+		switch searchSort {
+		case "downloads":
+			opts.Sort = "-downloads"
+		case "updated":
+			opts.Sort = "-updated"
+		}
+
+		projects, total, exact, err := hangarSearchPage(sources.NewHangarClient(endpoints.Hangar...), opts, searchMinDownloads)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("hangar: %v", err))
+		} else {
+			result.Totals["hangar"] = total
+			shownBySource["hangar"] = len(projects)
+			totalsExact = totalsExact && exact
+			for _, p := range projects {
+				result.Hits = append(result.Hits, hangarSearchHit(p, platform))
+			}
+		}
+	}
+
+	spinner.Stop()
+
+	// Each source pages on its own, so there is more to show while any of them has more
+	more := false
+	for source, total := range result.Totals {
+		result.Total += total
+		if offset+shownBySource[source] < total {
+			more = true
+		}
+	}
+
+	// Rank by relevance and merge projects published on both sources,
+	// then apply the requested order
+	result.Hits = rankSearchHits(query, result.Hits)
+	sort.SliceStable(result.Hits, func(i, j int) bool {
+		a, b := result.Hits[i], result.Hits[j]
+		switch searchSort {
+		case "downloads":
+			return a.Downloads > b.Downloads
+		case "updated":
+			return a.Updated > b.Updated // ISO-8601 timestamps sort lexically
+		default:
+			return false // already sorted by relevance
+		}
+	})
+
+	if ui.IsStructured() {
+		return ui.PrintResult(result)
+	}
+
+	for _, e := range result.Errors {
+		ui.PrintWarning("Search failed on %s", e)
+	}
+
+	if len(result.Hits) == 0 {
+		ui.PrintWarning("No results found for '%s'", query)
+		return nil
+	}
+
+	ui.PrintHeader("Search results for '%s' (page %d)", query, searchPage)
+
+	table := ui.NewTable("SOURCE", "NAME", "ID", "DOWNLOADS", "STARS", "UPDATED", "NOTES")
+	for _, hit := range result.Hits {
+		table.AddRow(
+			strings.ToUpper(hit.Source),
+			hit.Name,
+			hit.ID,
+			formatCount(hit.Downloads),
+			formatCount(hit.Stars),
+			formatDate(hit.Updated),
+			ui.DetailStyle.Render(alsoOnLabel(hit)),
+		)
+	}
+	fmt.Fprintln(ui.Out, table.Render())
+
+	switch {
+	case more && totalsExact:
+		ui.PrintInfo("Showing %d of %d results. Use --page %d for more.", len(result.Hits), result.Total, searchPage+1)
+	case more:
+		ui.PrintInfo("Showing %d results. Use --page %d for more.", len(result.Hits), searchPage+1)
+	}
+	ui.PrintInfo("Install with: mpm install <name> or add the ID to package.yml")
+	return nil
+}
+
+// formatCount renders large counts compactly (12.3k, 4.5M)
+func formatCount(n int) string {
+	switch {
+	case n >= 1000000:
+		return fmt.Sprintf("%.1fM", float64(n)/1000000)
+	case n >= 1000:
+		return fmt.Sprintf("%.1fk", float64(n)/1000)
+	default:
+		return fmt.Sprintf("%d", n)
+	}
+}
+
+// formatDate keeps the date part of an ISO-8601 timestamp
+func formatDate(timestamp string) string {
+	if len(timestamp) >= 10 {
+		return timestamp[:10]
+	}
+	if timestamp == "" {
+		return "-"
+	}
+	return timestamp
+}
+
+// hangarSearchPage returns a page of Hangar projects with at least minDownloads
+// downloads, their total, and whether the total is exact. Hangar can't filter
+// by downloads, so results are fetched until the page is full; the total
+// counts the matches seen, which is exact only when every result was seen.
+func hangarSearchPage(client *sources.HangarClient, opts sources.HangarSearchOptions, minDownloads int) ([]sources.HangarProject, int, bool, error) {
+	if minDownloads <= 0 {
+		resp, err := client.Search(opts)
+		if err != nil {
+			return nil, 0, false, err
+		}
+		return resp.Result, resp.Pagination.Count, true, nil
+	}
+
+	skip, limit := opts.Offset, opts.Limit
+	page := []sources.HangarProject{}
+	matches := 0
+	opts.Offset = 0
+	opts.Limit = hangarSearchBatch
+
+	for i := 0; i < hangarSearchMaxPages; i++ {
+		resp, err := client.Search(opts)
+		if err != nil {
+			return nil, 0, false, err
+		}
+
+		for _, p := range resp.Result {
+			if p.Stats.Downloads < minDownloads {
+				// Sorted by downloads, no match can follow
+				if opts.Sort == "-downloads" {
+					return page, matches, true, nil
+				}
+				continue
+			}
+			matches++
+			if matches > skip+limit {
+				// A match past this page is enough to know there is a next one
+				return page, matches, false, nil
+			}
+			if matches > skip {
+				page = append(page, p)
+			}
+		}
+
+		opts.Offset += len(resp.Result)
+		if len(resp.Result) == 0 || opts.Offset >= resp.Pagination.Count {
+			return page, matches, true, nil
+		}
+	}
+	return page, matches, false, nil
+}
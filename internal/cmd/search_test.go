@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/storrealbac/mpm/internal/sources"
+)
+
+// fakeHangar serves /projects from downloads, one project per entry (p0, p1...)
+func fakeHangar(t *testing.T, downloads []int, requests *int) *sources.HangarClient {
+	t.Helper()
+	projects := make([]sources.HangarProject, len(downloads))
+	for i, n := range downloads {
+		projects[i].Name = fmt.Sprintf("p%d", i)
+		projects[i].Stats.Downloads = n
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		end := min(offset+limit, len(projects))
+		offset = min(offset, end)
+		json.NewEncoder(w).Encode(sources.HangarSearchResponse{
+			Pagination: sources.HangarPagination{Count: len(projects), Limit: limit, Offset: offset},
+			Result:     projects[offset:end],
+		})
+	}))
+	t.Cleanup(server.Close)
+	return sources.NewHangarClient(server.URL)
+}
+
+func TestHangarSearchPage(t *testing.T) {
+	// Every third project has 100 downloads, the others 1
+	var mixed []int
+	for i := 0; i < 60; i++ {
+		if i%3 == 0 {
+			mixed = append(mixed, 100)
+		} else {
+			mixed = append(mixed, 1)
+		}
+	}
+	// Sorted by downloads, 10 projects match
+	var sorted []int
+	for i := 0; i < 60; i++ {
+		sorted = append(sorted, 100-i*5)
+	}
+
+	tests := []struct {
+		name         string
+		downloads    []int
+		sort         string
+		offset       int
+		limit        int
+		minDownloads int
+		want         []string
+		total        int
+		exact        bool
+		requests     int
+	}{
+		{
+			name:      "no filter is a single request",
+			downloads: mixed, limit: 3,
+			want: []string{"p0", "p1", "p2"}, total: 60, exact: true, requests: 1,
+		},
+		{
+			name:      "first page stops once full",
+			downloads: mixed, limit: 3, minDownloads: 50,
+			want: []string{"p0", "p3", "p6"}, total: 4, exact: false, requests: 1,
+		},
+		{
+			name:      "later page spans requests",
+			downloads: mixed, offset: 9, limit: 3, minDownloads: 50,
+			want: []string{"p27", "p30", "p33"}, total: 13, exact: false, requests: 2,
+		},
+		{
+			name:      "last page has an exact total",
+			downloads: mixed, offset: 18, limit: 5, minDownloads: 50,
+			want: []string{"p54", "p57"}, total: 20, exact: true, requests: 3,
+		},
+		{
+			name:      "sorted by downloads stops at the first miss",
+			downloads: sorted, sort: "-downloads", limit: 20, minDownloads: 55,
+			want: []string{"p0", "p1", "p2", "p3", "p4", "p5", "p6", "p7", "p8", "p9"}, total: 10, exact: true, requests: 1,
+		},
+		{
+			name:      "nothing matches",
+			downloads: mixed, limit: 5, minDownloads: 1000,
+			want: []string{}, total: 0, exact: true, requests: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			client := fakeHangar(t, tt.downloads, &requests)
+			opts := sources.HangarSearchOptions{Sort: tt.sort, Offset: tt.offset, Limit: tt.limit}
+
+			projects, total, exact, err := hangarSearchPage(client, opts, tt.minDownloads)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []string{}
+			for _, p := range projects {
+				got = append(got, p.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("projects = %v, want %v", got, tt.want)
+			}
+			if total != tt.total || exact != tt.exact {
+				t.Errorf("total = %d (exact %v), want %d (exact %v)", total, exact, tt.total, tt.exact)
+			}
+			if requests != tt.requests {
+				t.Errorf("made %d requests, want %d", requests, tt.requests)
+			}
+		})
+	}
+}
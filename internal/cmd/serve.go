@@ -6,13 +6,18 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/storrealbac/mpm/internal/models"
+	"github.com/storrealbac/mpm/internal/server"
 	"github.com/storrealbac/mpm/internal/ui"
 )
 
@@ -21,11 +26,22 @@ var serveCmd = &cobra.Command{
 	Short: "Start the Minecraft server",
 	Long: `Start the Minecraft server using the configuration from package.yml.
 
-The server will be started with the command specified in server.start_command,
-or with a default command based on the server type if not specified.
+The Minecraft EULA must be accepted first: with server.eula: true in package.yml
+mpm writes eula.txt before starting the server.
+
+The properties map in package.yml is merged into server.properties first; keys
+it doesn't declare and comments are kept.
+
+The server is started without a shell from server.jar, java, min_memory,
+max_memory, jvm_flags, jvm_args and server_args, with server.env added to its
+environment. A raw server.start_command overrides these and is run by the shell.
 
 After the server starts, any commands listed in startup_commands will be
-executed in the server console in order.`,
+executed in the server console in order.
+
+Lines typed in the terminal are passed to the server console. On Ctrl+C or
+SIGTERM the server is stopped gracefully with its stop command (stop, or end
+on BungeeCord, Waterfall and Velocity); a second signal kills it.`,
 	RunE: serveServer,
 }
 
@@ -40,35 +56,116 @@ func serveServer(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load package.yml: %w", err)
 	}
 
-	// Find the server jar file
-	serverJar, err := findServerJar()
-	if err != nil {
-		return fmt.Errorf("server jar not found: %w\nRun 'mpm install' to download the server", err)
+	// A server without an accepted EULA stops right after starting
+	if err := ensureEULA(pkg.Server); err != nil {
+		return err
+	}
+
+	// The properties in package.yml win over the ones in server.properties
+	if err := applyProperties(pkg); err != nil {
+		return err
 	}
 
-	// Get or build the start command
-	startCommand := pkg.Server.StartCommand
-	if startCommand == "" {
-		startCommand = buildDefaultStartCommand(serverJar)
+	serverCmd, display, err := serverCommand(pkg.Server)
+	if err != nil {
+		return err
 	}
 
 	ui.PrintInfo("Starting %s server (Minecraft %s)", pkg.Server.Type, pkg.Server.MinecraftVersion)
-	fmt.Printf("  Command: %s\n", startCommand)
+	fmt.Fprintf(ui.Out, "  Command: %s\n", display)
 
 	// Check if we have startup commands to execute
 	hasStartupCommands := len(pkg.StartupCommands) > 0
 	if hasStartupCommands {
 		ui.PrintInfo("Will execute %d startup command(s) after server starts", len(pkg.StartupCommands))
 	}
-	fmt.Println()
+	fmt.Fprintln(ui.Out)
 
 	// Start the server
-	return startServerWithCommands(startCommand, pkg.StartupCommands)
+	return startServerWithCommands(serverCmd, pkg.Server.Type, pkg.StartupCommands)
+}
+
+// serverCommand builds the command that starts the server, and the command line
+// shown to the user
+func serverCommand(serverConfig models.ServerConfig) (*exec.Cmd, string, error) {
+	// Find the server jar file
+	serverJar, err := findServerJar(serverConfig)
+	if err != nil {
+		return nil, "", fmt.Errorf("server jar not found: %w\nRun 'mpm install' to download the server", err)
+	}
+
+	// Use the configured Java, or the installed one that matches the Minecraft version
+	required := requiredJava(serverConfig)
+	java := serverJava(serverConfig, required)
+	if problem := javaProblem(required, java); problem != "" {
+		ui.PrintWarning("%s", problem)
+	}
+
+	var serverCmd *exec.Cmd
+	var display string
+	if serverConfig.StartCommand != "" {
+		// A raw start_command is run by the shell as written
+		if serverConfig.HasJVMSettings() {
+			ui.PrintWarning("server.start_command is set, so jar, java, min_memory, max_memory, jvm_flags, jvm_args and server_args are ignored")
+		}
+		serverCmd = shellCommand(serverConfig.StartCommand)
+		serverCmd.Env = append(os.Environ(), envList(serverConfig.Env)...)
+		display = serverConfig.StartCommand
+	} else {
+		jvm, err := jvmArgs(serverConfig)
+		if err != nil {
+			return nil, "", err
+		}
+
+		javaPath := "java"
+		env := os.Environ()
+		if java != nil {
+			javaPath = java.Path
+			env = javaEnv(java.Path)
+		}
+
+		argv, scriptJVMArgs := startArgs(serverConfig, serverJar, javaPath, jvm)
+		display = formatCommand(argv)
+		if len(scriptJVMArgs) > 0 {
+			// Start scripts run java themselves; the JVM picks these up from the environment
+			options := "JDK_JAVA_OPTIONS=" + strings.Join(scriptJVMArgs, " ")
+			env = append(env, options)
+			display = formatCommand([]string{options}) + " " + display
+		}
+		serverCmd = exec.Command(argv[0], argv[1:]...)
+		serverCmd.Env = append(env, envList(serverConfig.Env)...)
+	}
+	return serverCmd, display, nil
+}
+
+// ensureEULA makes sure eula.txt accepts the Minecraft EULA, writing it when
+// server.eula is set. Proxies have no EULA.
+func ensureEULA(serverConfig models.ServerConfig) error {
+	if serverConfig.IsProxy() || server.EULAAccepted(".") {
+		return nil
+	}
+	if !serverConfig.Eula {
+		return fmt.Errorf("the Minecraft EULA (%s) has not been accepted\nSet server.eula: true in package.yml or run 'mpm install --accept-eula'", server.EULAURL)
+	}
+
+	if err := server.WriteEULA("."); err != nil {
+		return err
+	}
+	ui.PrintInfo("Accepted the Minecraft EULA (%s) in %s", server.EULAURL, server.EULAFile)
+	return nil
 }
 
-func findServerJar() (string, error) {
-	// Look for server jar files in common locations
-	possibleNames := []string{"server.jar", "paper.jar", "purpur.jar", "folia.jar", "spigot.jar"}
+func findServerJar(serverConfig models.ServerConfig) (string, error) {
+	// An explicit server.jar is used as is
+	if serverConfig.Jar != "" {
+		if _, err := os.Stat(serverConfig.Jar); err != nil {
+			return "", fmt.Errorf("server.jar %s: %w", serverConfig.Jar, err)
+		}
+		return serverConfig.Jar, nil
+	}
+
+	// Look for the file mpm installs for this server type, then common locations
+	possibleNames := []string{server.LaunchFile(serverConfig.Type), "server.jar", "paper.jar", "purpur.jar", "folia.jar", "spigot.jar"}
 
 	for _, name := range possibleNames {
 		if _, err := os.Stat(name); err == nil {
@@ -86,74 +183,285 @@ func findServerJar() (string, error) {
 	return "", fmt.Errorf("no server jar file found")
 }
 
-func buildDefaultStartCommand(serverJar string) string {
-	// Default Java command with reasonable memory allocation
-	// Users can override this in package.yml with server.start_command
-	return fmt.Sprintf("java -Xms2G -Xmx4G -jar %s nogui", serverJar)
+// serverJava returns the Java that runs the server: server.java if set, otherwise
+// the installed Java that best matches the required version
+func serverJava(serverConfig models.ServerConfig, required int) *server.JavaInstall {
+	if serverConfig.Java == "" {
+		return server.SelectJava(server.DetectJava(), required)
+	}
+
+	// Resolve the executable first so its directory can become JAVA_HOME
+	path, err := exec.LookPath(serverConfig.Java)
+	if err != nil {
+		ui.PrintWarning("server.java: %v", err)
+		return &server.JavaInstall{Path: serverConfig.Java, Major: required}
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+
+	install, err := server.ProbeJava(path)
+	if err != nil {
+		ui.PrintWarning("server.java: %v", err)
+		return &server.JavaInstall{Path: path, Major: required}
+	}
+	return install
 }
 
-func startServerWithCommands(startCommand string, startupCommands []string) error {
-	var serverCmd *exec.Cmd
+// defaultMemory is the heap used when min_memory and max_memory aren't set.
+// Proxies need little memory.
+func defaultMemory(serverConfig models.ServerConfig) (string, string) {
+	if serverConfig.IsProxy() {
+		return "512M", "512M"
+	}
+	return "2G", "4G"
+}
 
-	// Use appropriate shell based on OS
-	if runtime.GOOS == "windows" {
-		serverCmd = exec.Command("cmd", "/C", startCommand)
-	} else {
-		serverCmd = exec.Command("sh", "-c", startCommand)
+// jvmArgs builds the JVM arguments from min_memory, max_memory, jvm_flags and
+// jvm_args. It fails on invalid memory sizes and unknown presets, and warns when
+// max_memory is more than the machine has.
+func jvmArgs(serverConfig models.ServerConfig) ([]string, error) {
+	defaultMin, defaultMax := defaultMemory(serverConfig)
+	minMemory, maxMemory := serverConfig.MinMemory, serverConfig.MaxMemory
+	if minMemory == "" && maxMemory == "" {
+		minMemory, maxMemory = defaultMin, defaultMax
 	}
 
-	// If we have startup commands, we need to monitor output and pipe commands
-	if len(startupCommands) > 0 {
-		stdin, err := serverCmd.StdinPipe()
-		if err != nil {
-			return fmt.Errorf("failed to create stdin pipe: %w", err)
+	var minBytes, maxBytes uint64
+	var err error
+	if maxMemory != "" {
+		if maxBytes, err = server.ParseMemory(maxMemory); err != nil {
+			return nil, fmt.Errorf("server.max_memory: %w", err)
+		}
+	}
+	if minMemory == "" {
+		minMemory, minBytes = maxMemory, maxBytes
+	} else if minBytes, err = server.ParseMemory(minMemory); err != nil {
+		return nil, fmt.Errorf("server.min_memory: %w", err)
+	}
+	if maxMemory == "" {
+		// Only min_memory is set: keep the default maximum unless it's smaller
+		maxMemory = defaultMax
+		maxBytes, _ = server.ParseMemory(defaultMax)
+		if minBytes > maxBytes {
+			maxMemory, maxBytes = minMemory, minBytes
 		}
+	}
+	if minBytes > maxBytes {
+		return nil, fmt.Errorf("server.min_memory (%s) is larger than server.max_memory (%s)", minMemory, maxMemory)
+	}
+	if total := server.TotalMemory(); total > 0 && maxBytes > total {
+		ui.PrintWarning("server.max_memory (%s) is more than this machine's %s of RAM", maxMemory, server.FormatMemory(total))
+	}
 
-		stdout, err := serverCmd.StdoutPipe()
-		if err != nil {
-			return fmt.Errorf("failed to create stdout pipe: %w", err)
+	flags, err := server.JVMFlags(serverConfig.JVMFlags, maxBytes)
+	if err != nil {
+		return nil, fmt.Errorf("server.jvm_flags: %w", err)
+	}
+
+	args := []string{"-Xms" + minMemory, "-Xmx" + maxMemory}
+	args = append(args, flags...)
+	return append(args, serverConfig.JVMArgs...), nil
+}
+
+// startArgs builds the argv that starts the server. Forge and NeoForge are started
+// with the script their installer generates, which runs java itself: their JVM
+// arguments are returned separately to be passed through the environment.
+func startArgs(serverConfig models.ServerConfig, launchFile, java string, jvm []string) ([]string, []string) {
+	serverArgs := serverConfig.ServerArgs
+	if serverArgs == nil && !serverConfig.IsProxy() {
+		// Proxies have no GUI to disable
+		serverArgs = []string{"nogui"}
+	}
+
+	switch filepath.Ext(launchFile) {
+	case ".sh":
+		return append([]string{"sh", launchFile}, serverArgs...), jvm
+	case ".bat":
+		return append([]string{"cmd", "/C", launchFile}, serverArgs...), jvm
+	}
+
+	argv := append([]string{java}, jvm...)
+	argv = append(argv, "-jar", launchFile)
+	return append(argv, serverArgs...), nil
+}
+
+// shellCommand runs a raw start_command with the platform's shell
+func shellCommand(command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/C", command)
+	}
+	return exec.Command("sh", "-c", command)
+}
+
+// formatCommand renders an argv for display, quoting arguments with spaces
+func formatCommand(argv []string) string {
+	quoted := make([]string, len(argv))
+	for i, arg := range argv {
+		quoted[i] = arg
+		if arg == "" || strings.ContainsAny(arg, " \t\"'") {
+			quoted[i] = fmt.Sprintf("%q", arg)
 		}
+	}
+	return strings.Join(quoted, " ")
+}
+
+// envList renders server.env as KEY=value pairs, sorted by key
+func envList(env map[string]string) []string {
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
 
-		// Stderr still goes directly to terminal
-		serverCmd.Stderr = os.Stderr
+	list := make([]string, 0, len(keys))
+	for _, key := range keys {
+		list = append(list, key+"="+env[key])
+	}
+	return list
+}
 
-		// Start the server
-		if err := serverCmd.Start(); err != nil {
-			return fmt.Errorf("failed to start server: %w", err)
+// javaEnv is the server's environment with the selected Java first on the PATH,
+// so start scripts that call java use it too
+func javaEnv(java string) []string {
+	// A java that couldn't be resolved says nothing about JAVA_HOME
+	if !filepath.IsAbs(java) {
+		return os.Environ()
+	}
+	bin := filepath.Dir(java)
+	env := []string{"JAVA_HOME=" + filepath.Dir(bin)}
+	for _, kv := range os.Environ() {
+		switch {
+		case strings.HasPrefix(kv, "JAVA_HOME="):
+		case strings.HasPrefix(kv, "PATH="):
+			env = append(env, "PATH="+bin+string(os.PathListSeparator)+strings.TrimPrefix(kv, "PATH="))
+		default:
+			env = append(env, kv)
 		}
+	}
+	return env
+}
 
-		// Monitor server output and execute startup commands when ready
-		serverReady := make(chan bool, 1)
-		go monitorServerOutput(stdout, serverReady)
-		go executeStartupCommands(stdin, startupCommands, serverReady)
+// stopCommand is the console command that shuts a server down gracefully
+func stopCommand(serverType string) string {
+	if models.IsProxy(serverType) {
+		return "end"
+	}
+	return "stop"
+}
+
+// readyPatterns are the console lines that mean the server finished starting
+func readyPatterns(serverType string) []string {
+	switch strings.ToLower(serverType) {
+	case "bungeecord", "waterfall":
+		return []string{"Listening on /"}
+	case "velocity":
+		return []string{"Done ("}
+	default:
+		// Common patterns: "Done (X.XXXs)!" or "Server started"
+		return []string{"Done (", "Server started"}
+	}
+}
+
+// serverConsole writes lines to the server's stdin. It is shared by the terminal
+// input, the startup commands and the stop command.
+type serverConsole struct {
+	mu sync.Mutex
+	w  io.Writer
+}
 
-		// Wait for server to finish (user stops it)
-		return serverCmd.Wait()
+func (c *serverConsole) send(line string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, err := fmt.Fprintf(c.w, "%s\n", line)
+	return err
+}
+
+func startServerWithCommands(serverCmd *exec.Cmd, serverType string, startupCommands []string) error {
+	// mpm owns the server's stdin so it can send commands to the console
+	stdin, err := serverCmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create stdin pipe: %w", err)
 	}
+	console := &serverConsole{w: stdin}
 
-	// No startup commands, just run the server normally
-	serverCmd.Stdout = os.Stdout
+	// Stderr still goes directly to terminal
 	serverCmd.Stderr = os.Stderr
-	serverCmd.Stdin = os.Stdin
 
+	// If we have startup commands, we need to monitor output to know when to send them
+	var stdout io.ReadCloser
+	if len(startupCommands) > 0 {
+		stdout, err = serverCmd.StdoutPipe()
+		if err != nil {
+			return fmt.Errorf("failed to create stdout pipe: %w", err)
+		}
+	} else {
+		serverCmd.Stdout = ui.Out
+	}
+
+	// Start the server
 	if err := serverCmd.Start(); err != nil {
 		return fmt.Errorf("failed to start server: %w", err)
 	}
 
+	go forwardInput(os.Stdin, console)
+
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go stopOnSignal(signals, serverCmd, console, stopCommand(serverType))
+
+	if len(startupCommands) > 0 {
+		// Monitor server output and execute startup commands when ready
+		serverReady := make(chan bool, 1)
+		go monitorServerOutput(stdout, serverReady, readyPatterns(serverType))
+		go executeStartupCommands(console, startupCommands, serverReady)
+	}
+
+	// Wait for server to finish (user stops it)
 	return serverCmd.Wait()
 }
 
-func monitorServerOutput(stdout io.ReadCloser, serverReady chan bool) {
+// forwardInput passes the lines typed in the terminal to the server console
+func forwardInput(input io.Reader, console *serverConsole) {
+	scanner := bufio.NewScanner(input)
+	for scanner.Scan() {
+		if err := console.send(scanner.Text()); err != nil {
+			return
+		}
+	}
+}
+
+// stopOnSignal stops the server with its stop command on the first signal,
+// and kills it on the second one
+func stopOnSignal(signals chan os.Signal, serverCmd *exec.Cmd, console *serverConsole, stop string) {
+	stopping := false
+	for range signals {
+		if stopping {
+			ui.PrintWarning("Killing the server")
+			serverCmd.Process.Kill()
+			return
+		}
+
+		stopping = true
+		fmt.Fprintln(ui.Out)
+		ui.PrintInfo("Stopping the server ('%s'), press Ctrl+C again to kill it", stop)
+		if err := console.send(stop); err != nil {
+			ui.PrintError("Failed to send '%s': %v", stop, err)
+		}
+	}
+}
+
+func monitorServerOutput(stdout io.ReadCloser, serverReady chan bool, patterns []string) {
 	scanner := bufio.NewScanner(stdout)
 	sentReady := false
 
 	for scanner.Scan() {
 		line := scanner.Text()
-		fmt.Println(line) // Print to terminal
+		fmt.Fprintln(ui.Out, line) // Print to terminal
 
 		// Detect when server is ready
-		// Common patterns: "Done (X.XXXs)!" or "Server started"
-		if !sentReady && (strings.Contains(line, "Done (") || strings.Contains(line, "Server started")) {
+		if !sentReady && containsAny(line, patterns) {
 			serverReady <- true
 			sentReady = true
 		}
@@ -165,7 +473,16 @@ func monitorServerOutput(stdout io.ReadCloser, serverReady chan bool) {
 	}
 }
 
-func executeStartupCommands(stdin io.WriteCloser, commands []string, serverReady chan bool) {
+func containsAny(line string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if strings.Contains(line, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+func executeStartupCommands(console *serverConsole, commands []string, serverReady chan bool) {
 	// Wait for server to be ready
 	<-serverReady
 
@@ -174,20 +491,15 @@ func executeStartupCommands(stdin io.WriteCloser, commands []string, serverReady
 
 	ui.PrintInfo("Executing startup commands...")
 
-	writer := bufio.NewWriter(stdin)
 	for i, command := range commands {
 		ui.PrintStep(i+1, len(commands), "%s", command)
-		if _, err := fmt.Fprintf(writer, "%s\n", command); err != nil {
+		if err := console.send(command); err != nil {
 			ui.PrintError("Failed to execute command '%s': %v", command, err)
 			continue
 		}
-		if err := writer.Flush(); err != nil {
-			ui.PrintError("Failed to flush command '%s': %v", command, err)
-			continue
-		}
 		time.Sleep(100 * time.Millisecond) // Small delay between commands
 	}
 
 	ui.PrintSuccess("Startup commands completed")
-	fmt.Println()
+	fmt.Fprintln(ui.Out)
 }
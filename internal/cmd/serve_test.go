@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/storrealbac/mpm/internal/models"
+	"github.com/storrealbac/mpm/internal/server"
+)
+
+func TestJVMArgs(t *testing.T) {
+	tests := []struct {
+		name   string
+		config models.ServerConfig
+		want   []string // leading arguments
+		err    string
+	}{
+		{name: "defaults", config: models.ServerConfig{Type: "paper"}, want: []string{"-Xms2G", "-Xmx4G"}},
+		{name: "proxy defaults", config: models.ServerConfig{Type: "velocity"}, want: []string{"-Xms512M", "-Xmx512M"}},
+		{name: "both set", config: models.ServerConfig{Type: "paper", MinMemory: "3G", MaxMemory: "6G"}, want: []string{"-Xms3G", "-Xmx6G"}},
+		{name: "only max", config: models.ServerConfig{Type: "paper", MaxMemory: "8G"}, want: []string{"-Xms8G", "-Xmx8G"}},
+		{name: "only min below the default max", config: models.ServerConfig{Type: "paper", MinMemory: "1G"}, want: []string{"-Xms1G", "-Xmx4G"}},
+		{name: "only min above the default max", config: models.ServerConfig{Type: "paper", MinMemory: "6G"}, want: []string{"-Xms6G", "-Xmx6G"}},
+		{
+			name:   "flags and extra args",
+			config: models.ServerConfig{Type: "velocity", MaxMemory: "1G", JVMFlags: "velocity", JVMArgs: []string{"-Dfoo=bar"}},
+			want:   []string{"-Xms1G", "-Xmx1G", "-XX:+UseG1GC", "-XX:G1HeapRegionSize=4M", "-XX:+UnlockExperimentalVMOptions", "-XX:+ParallelRefProcEnabled", "-XX:+AlwaysPreTouch", "-XX:MaxInlineLevel=15", "-Dfoo=bar"},
+		},
+		{name: "min larger than max", config: models.ServerConfig{Type: "paper", MinMemory: "8G", MaxMemory: "4G"}, err: "is larger than server.max_memory"},
+		{name: "zero max", config: models.ServerConfig{Type: "paper", MaxMemory: "0G"}, err: "server.max_memory"},
+		{name: "invalid min", config: models.ServerConfig{Type: "paper", MinMemory: "lots"}, err: "server.min_memory"},
+		{name: "unknown preset", config: models.ServerConfig{Type: "paper", JVMFlags: "fast"}, err: "server.jvm_flags"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, err := jvmArgs(tt.config)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("err = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(args) < len(tt.want) || !reflect.DeepEqual(args[:len(tt.want)], tt.want) {
+				t.Errorf("jvmArgs() = %v, want %v first", args, tt.want)
+			}
+		})
+	}
+}
+
+func TestStartArgs(t *testing.T) {
+	jvm := []string{"-Xms2G", "-Xmx4G"}
+	tests := []struct {
+		name       string
+		config     models.ServerConfig
+		launchFile string
+		want       []string
+		wantJVM    []string
+	}{
+		{
+			name:       "jar",
+			config:     models.ServerConfig{Type: "paper"},
+			launchFile: "server.jar",
+			want:       []string{"/jdk/bin/java", "-Xms2G", "-Xmx4G", "-jar", "server.jar", "nogui"},
+		},
+		{
+			name:       "proxy has no nogui",
+			config:     models.ServerConfig{Type: "velocity"},
+			launchFile: "server.jar",
+			want:       []string{"/jdk/bin/java", "-Xms2G", "-Xmx4G", "-jar", "server.jar"},
+		},
+		{
+			name:       "server args replace nogui",
+			config:     models.ServerConfig{Type: "paper", ServerArgs: []string{"--port", "25566"}},
+			launchFile: "purpur-1.21.jar",
+			want:       []string{"/jdk/bin/java", "-Xms2G", "-Xmx4G", "-jar", "purpur-1.21.jar", "--port", "25566"},
+		},
+		{
+			name:       "run script gets the JVM args separately",
+			config:     models.ServerConfig{Type: "forge"},
+			launchFile: "run.sh",
+			want:       []string{"sh", "run.sh", "nogui"},
+			wantJVM:    jvm,
+		},
+		{
+			name:       "windows run script",
+			config:     models.ServerConfig{Type: "neoforge"},
+			launchFile: "run.bat",
+			want:       []string{"cmd", "/C", "run.bat", "nogui"},
+			wantJVM:    jvm,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			argv, scriptJVM := startArgs(tt.config, tt.launchFile, "/jdk/bin/java", jvm)
+			if !reflect.DeepEqual(argv, tt.want) {
+				t.Errorf("argv = %v, want %v", argv, tt.want)
+			}
+			if !reflect.DeepEqual(scriptJVM, tt.wantJVM) {
+				t.Errorf("script JVM args = %v, want %v", scriptJVM, tt.wantJVM)
+			}
+		})
+	}
+}
+
+func TestFindServerJar(t *testing.T) {
+	tests := []struct {
+		name   string
+		files  map[string]string
+		config models.ServerConfig
+		want   string
+		err    bool
+	}{
+		{name: "launch file", files: map[string]string{"server.jar": "", "paper.jar": ""}, config: models.ServerConfig{Type: "paper"}, want: "server.jar"},
+		{name: "quilt launcher first", files: map[string]string{"server.jar": "", "quilt-server-launch.jar": ""}, config: models.ServerConfig{Type: "quilt"}, want: "quilt-server-launch.jar"},
+		{name: "common name", files: map[string]string{"purpur.jar": ""}, config: models.ServerConfig{Type: "purpur"}, want: "purpur.jar"},
+		{name: "any jar", files: map[string]string{"custom-1.21.jar": ""}, config: models.ServerConfig{Type: "paper"}, want: "custom-1.21.jar"},
+		{name: "explicit jar", files: map[string]string{"server.jar": "", "purpur-1.21.jar": ""}, config: models.ServerConfig{Type: "purpur", Jar: "purpur-1.21.jar"}, want: "purpur-1.21.jar"},
+		{name: "explicit jar is missing", files: map[string]string{"server.jar": ""}, config: models.ServerConfig{Type: "paper", Jar: "gone.jar"}, err: true},
+		{name: "no jar", files: map[string]string{"package.yml": ""}, config: models.ServerConfig{Type: "paper"}, err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chdirTemp(t)
+			writeFiles(t, tt.files)
+
+			got, err := findServerJar(tt.config)
+			if (err != nil) != tt.err {
+				t.Fatalf("err = %v, want error %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("findServerJar() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJavaEnv(t *testing.T) {
+	home := filepath.Join(t.TempDir(), "jdk-21")
+	java := filepath.Join(home, "bin", "java")
+	t.Setenv("JAVA_HOME", "/old/jdk")
+	t.Setenv("PATH", "/usr/bin")
+
+	tests := []struct {
+		name string
+		java string
+		want []string
+	}{
+		{
+			name: "resolved java",
+			java: java,
+			want: []string{"JAVA_HOME=" + home, "PATH=" + filepath.Join(home, "bin") + string(os.PathListSeparator) + "/usr/bin"},
+		},
+		{
+			name: "unresolved java keeps the environment",
+			java: "java",
+			want: []string{"JAVA_HOME=/old/jdk", "PATH=/usr/bin"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, kv := range javaEnv(tt.java) {
+				if strings.HasPrefix(kv, "JAVA_HOME=") || strings.HasPrefix(kv, "PATH=") {
+					got = append(got, kv)
+				}
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("javaEnv(%q) = %v, want %v", tt.java, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnsureEULA(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   models.ServerConfig
+		eulaTxt  string // "" for no eula.txt
+		err      bool
+		accepted bool // eula.txt accepts the EULA afterwards
+	}{
+		{name: "not accepted", config: models.ServerConfig{Type: "paper"}, err: true},
+		{name: "declined in eula.txt", config: models.ServerConfig{Type: "paper"}, eulaTxt: "eula=false\n", err: true},
+		{name: "accepted in package.yml", config: models.ServerConfig{Type: "paper", Eula: true}, accepted: true},
+		{name: "package.yml overrides eula.txt", config: models.ServerConfig{Type: "paper", Eula: true}, eulaTxt: "eula=false\n", accepted: true},
+		{name: "accepted in eula.txt", config: models.ServerConfig{Type: "paper"}, eulaTxt: "eula=true\n", accepted: true},
+		{name: "proxies have no EULA", config: models.ServerConfig{Type: "velocity"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chdirTemp(t)
+			if tt.eulaTxt != "" {
+				writeFiles(t, map[string]string{server.EULAFile: tt.eulaTxt})
+			}
+
+			err := ensureEULA(tt.config)
+			if (err != nil) != tt.err {
+				t.Fatalf("err = %v, want error %v", err, tt.err)
+			}
+			if got := server.EULAAccepted("."); got != tt.accepted {
+				t.Errorf("eula.txt accepted = %v, want %v", got, tt.accepted)
+			}
+		})
+	}
+}
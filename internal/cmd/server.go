@@ -0,0 +1,645 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/storrealbac/mpm/internal/config"
+	"github.com/storrealbac/mpm/internal/models"
+	"github.com/storrealbac/mpm/internal/server"
+	"github.com/storrealbac/mpm/internal/sources"
+	"github.com/storrealbac/mpm/internal/ui"
+)
+
+var (
+	upgradeCheck     bool
+	upgradeForce     bool
+	bootstrapTimeout time.Duration
+)
+
+// defaultBootstrapTimeout is how long a first boot may take to start, and then to stop
+const defaultBootstrapTimeout = 5 * time.Minute
+
+var serverCmd = &cobra.Command{
+	Use:   "server",
+	Short: "Manage the server itself",
+	Long:  `Manage the server jar and the Minecraft version in package.yml.`,
+}
+
+var serverBootstrapCmd = &cobra.Command{
+	Use:   "bootstrap",
+	Short: "Start the server once to generate its configuration files",
+	Long: `Starts the server once so it generates server.properties and its other default
+configuration files, then stops it cleanly as soon as it has finished starting.
+
+The Minecraft EULA must be accepted (server.eula: true in package.yml or
+'mpm install --accept-eula'). Proxies have no EULA and generate their own
+configuration (velocity.toml or config.yml). The properties in package.yml are
+merged into server.properties before the server starts.
+
+'mpm install --bootstrap' does the same after installing, when the files don't
+exist yet.`,
+	Args: cobra.NoArgs,
+	RunE: runServerBootstrap,
+}
+
+var serverUpgradeCmd = &cobra.Command{
+	Use:   "upgrade <minecraft-version>",
+	Short: "Move the server to another Minecraft version",
+	Long: `Checks every plugin in package.yml for a release compatible with the target
+Minecraft version and prints a go/no-go report.
+
+If every plugin can follow (or --force is given) and the upgrade is confirmed, mpm
+sets server.minecraft_version, downloads the new server jar and replaces each plugin
+with its compatible release. The upgrade is all or nothing: if any step fails, the
+old server jar, plugin jars, package.yml and package-lock.yml are restored.
+
+Local plugins (file:) can't be checked and are left for you to update.
+
+Examples:
+  mpm server upgrade 1.21.4 --check
+  mpm server upgrade 1.21.4
+  mpm server upgrade 1.21.4 --force --yes`,
+	Args: cobra.ExactArgs(1),
+	RunE: runServerUpgrade,
+}
+
+func init() {
+	serverUpgradeCmd.Flags().BoolVar(&upgradeCheck, "check", false, "Only print the report, don't upgrade")
+	serverUpgradeCmd.Flags().BoolVar(&upgradeForce, "force", false, "Upgrade even if some plugins have no compatible release (they keep their current jar)")
+
+	serverBootstrapCmd.Flags().DurationVar(&bootstrapTimeout, "timeout", defaultBootstrapTimeout, "How long the server may take to start, and then to stop")
+
+	serverCmd.AddCommand(serverUpgradeCmd)
+	serverCmd.AddCommand(serverBootstrapCmd)
+	rootCmd.AddCommand(serverCmd)
+}
+
+// upgradeStep is the new release a plugin moves to during an upgrade
+type upgradeStep struct {
+	index    int // position in pkg.Plugins
+	lockKey  string
+	source   string
+	version  string
+	url      string
+	filename string
+	hash     string
+}
+
+func runServerUpgrade(cmd *cobra.Command, args []string) error {
+	target := args[0]
+
+	pkg, err := models.LoadPackageFromFile("package.yml")
+	if err != nil {
+		return fmt.Errorf("could not read package.yml: %w", err)
+	}
+	lockFile, err := models.LoadPackageLockFromFile("package-lock.yml")
+	if err != nil {
+		return fmt.Errorf("error loading package-lock.yml: %w", err)
+	}
+
+	current := pkg.Server.MinecraftVersion
+	if target == current {
+		return fmt.Errorf("server is already on %s", current)
+	}
+	if server.IsVersionAlias(target) {
+		return fmt.Errorf("upgrade needs an exact version, not %q", target)
+	}
+
+	// The target must be a real Minecraft version before plugins are checked against it
+	upgraded := *pkg
+	upgraded.Server.MinecraftVersion = target
+	if problem := checkMinecraftVersion(&upgraded); problem != "" {
+		return fmt.Errorf("invalid target: %s", problem)
+	}
+
+	endpoints, err := config.ResolveEndpoints(pkg)
+	if err != nil {
+		return fmt.Errorf("error loading mpm config: %w", err)
+	}
+	modrinthClient := sources.NewModrinthClient(endpoints.Modrinth...)
+	hangarClient := sources.NewHangarClient(endpoints.Hangar...)
+
+	if !ui.IsStructured() {
+		ui.PrintHeader(fmt.Sprintf("Upgrade %s %s -> %s", pkg.Server.Type, current, target))
+	}
+
+	gameVersion := pluginGameVersion(upgraded.Server)
+	table := ui.NewTable("PLUGIN", "SOURCE", "CURRENT", "TARGET", "STATUS")
+	result := commandResult{Command: "server upgrade", Plugins: []pluginResult{}}
+	var steps []upgradeStep
+	blockers := 0
+
+	for i, plugin := range pkg.Plugins {
+		source, id := pluginSourceAndID(plugin)
+		entry := pluginResult{Plugin: plugin.Name, ID: id, Source: source, Version: plugin.Version}
+
+		var step *upgradeStep
+		var stepErr error
+		switch source {
+		case "modrinth":
+			step, stepErr = modrinthUpgradeStep(modrinthClient, id, gameVersion, pkg.Server.Type)
+		case "hangar":
+			step, stepErr = hangarUpgradeStep(hangarClient, id, gameVersion, pkg.Server.Type)
+		}
+
+		switch {
+		case source == "local":
+			entry.Status = "manual"
+		case stepErr != nil:
+			entry.Status = "error"
+			entry.Error = stepErr.Error()
+			blockers++
+		case step == nil:
+			entry.Status = "incompatible"
+			entry.Error = fmt.Sprintf("no release for %s", target)
+			blockers++
+		default:
+			entry.Status = "ok"
+			entry.Latest = step.version
+			step.index = i
+			step.source = source
+			steps = append(steps, *step)
+		}
+
+		targetVersion := entry.Latest
+		if targetVersion == "" {
+			targetVersion = "-"
+		}
+		table.AddRow(plugin.Name, source, plugin.Version, targetVersion, ui.CreateStatusBadge(entry.Status))
+		result.Plugins = append(result.Plugins, entry)
+	}
+
+	goAhead := blockers == 0 || upgradeForce
+	if !ui.IsStructured() {
+		fmt.Fprintln(ui.Out, table.Render())
+		fmt.Fprintln(ui.Out)
+		if blockers == 0 {
+			ui.PrintSuccess("GO: every plugin has a release for %s.", target)
+		} else if upgradeForce {
+			ui.PrintWarning("GO (forced): %d plugins have no release for %s and keep their current jar.", blockers, target)
+		} else {
+			ui.PrintError("NO-GO: %d plugins have no release for %s.", blockers, target)
+		}
+	}
+
+	if upgradeCheck || !goAhead {
+		result.Success = goAhead
+		if !goAhead {
+			result.Errors = append(result.Errors, fmt.Sprintf("%d plugins have no release for %s", blockers, target))
+		}
+		if ui.IsStructured() {
+			if err := ui.PrintResult(result); err != nil {
+				return err
+			}
+		} else if !goAhead {
+			ui.PrintInfo("Use --force to upgrade anyway; those plugins keep their current jar.")
+		}
+		if !goAhead {
+			return fmt.Errorf("upgrade blocked")
+		}
+		return nil
+	}
+
+	if !confirm(fmt.Sprintf("Upgrade to Minecraft %s?", target)) {
+		if !isInteractive() {
+			return fmt.Errorf("upgrade needs confirmation, pass --yes to proceed")
+		}
+		ui.PrintInfo("Upgrade cancelled.")
+		return nil
+	}
+
+	if err := applyUpgrade(pkg, lockFile, target, steps, endpoints); err != nil {
+		result.Errors = append(result.Errors, err.Error())
+		if ui.IsStructured() {
+			ui.PrintResult(result)
+		}
+		return err
+	}
+
+	for i := range result.Plugins {
+		if result.Plugins[i].Status == "ok" {
+			result.Plugins[i].Status = "updated"
+			result.Plugins[i].Version = result.Plugins[i].Latest
+		}
+	}
+	result.Success = true
+	if ui.IsStructured() {
+		return ui.PrintResult(result)
+	}
+
+	ui.PrintSuccess("Server upgraded to Minecraft %s.", target)
+	return nil
+}
+
+// modrinthUpgradeStep finds the newest Modrinth release of a plugin for gameVersion.
+// It returns nil if there is none.
+func modrinthUpgradeStep(client *sources.ModrinthClient, id, gameVersion, serverType string) (*upgradeStep, error) {
+	versions, err := client.GetProjectVersionsForLoaders(id, gameVersion, sources.ModrinthCompatibleLoaders(serverType))
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, nil
+	}
+
+	latest := versions[0]
+	var file *sources.ModrinthFile
+	for i := range latest.Files {
+		if latest.Files[i].Primary {
+			file = &latest.Files[i]
+			break
+		}
+	}
+	if file == nil && len(latest.Files) > 0 {
+		file = &latest.Files[0]
+	}
+	if file == nil {
+		return nil, fmt.Errorf("release %s has no files", latest.VersionNumber)
+	}
+
+	return &upgradeStep{
+		lockKey:  id,
+		version:  latest.VersionNumber,
+		url:      file.URL,
+		filename: file.Filename,
+		hash:     file.Hashes["sha512"],
+	}, nil
+}
+
+// hangarUpgradeStep finds the newest Hangar release of a plugin for gameVersion.
+// It returns nil if there is none.
+func hangarUpgradeStep(client *sources.HangarClient, id, gameVersion, serverType string) (*upgradeStep, error) {
+	parts := strings.SplitN(id, "/", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid Hangar ID format")
+	}
+
+	versions, err := client.GetProjectVersions(parts[0], parts[1], gameVersion, serverType)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, nil
+	}
+
+	latest := versions[0]
+	url, hash, err := sources.GetDownloadURL(&latest, serverType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &upgradeStep{
+		lockKey:  id,
+		version:  latest.Name,
+		url:      url,
+		filename: sources.GetFilename(&latest, serverType),
+		hash:     hash,
+	}, nil
+}
+
+// applyUpgrade switches the server and its plugins to target, restoring everything
+// it touched if a step fails
+func applyUpgrade(pkg *models.Package, lockFile *models.PackageLock, target string, steps []upgradeStep, endpoints models.Endpoints) (err error) {
+	tx, err := newUpgradeTransaction()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			ui.PrintWarning("Upgrade failed, rolling back...")
+			if rbErr := tx.rollback(); rbErr != nil {
+				ui.PrintError("Rollback incomplete: %v (backup kept in %s)", rbErr, tx.backupDir)
+				return
+			}
+			ui.PrintInfo("Restored the previous server, plugins and package files.")
+			return
+		}
+		tx.commit()
+	}()
+
+	// Server jar first: nothing else is worth doing if it can't be downloaded
+	if pkg.Server.Type != "" {
+		targetConfig := pkg.Server
+		targetConfig.MinecraftVersion = target
+		downloader, err := server.GetDownloader(pkg.Server.Type, endpoints, installerJava(targetConfig))
+		if err != nil {
+			return err
+		}
+		// Installers write more than the launch file (libraries, the vanilla jar, scripts)
+		for _, name := range server.ServerFiles(pkg.Server.Type) {
+			if err := tx.moveAside(name); err != nil {
+				return err
+			}
+		}
+		before, err := os.ReadDir(".")
+		if err != nil {
+			return err
+		}
+
+		// A pinned build belongs to the old version
+		build := pkg.Server.Build
+		switch strings.ToLower(build) {
+		case "", "latest", "stable", "recommended":
+		default:
+			ui.PrintInfo("Dropping build pin %s, it belongs to %s", build, pkg.Server.MinecraftVersion)
+			build = ""
+		}
+
+		ui.PrintInfo("Downloading %s %s...", pkg.Server.Type, target)
+		launchPath, err := downloader.Download(target, build, ".")
+		tx.created = append(tx.created, server.ServerFiles(pkg.Server.Type)...)
+		tx.created = append(tx.created, newEntries(before)...)
+		if err != nil {
+			return fmt.Errorf("error downloading server: %w", err)
+		}
+		ui.PrintSuccess("Server ready (%s)", filepath.Base(launchPath))
+
+		// A custom server.jar is the old version's jar, mpm serve must start the new one
+		if pkg.Server.Jar != "" {
+			if filepath.Clean(pkg.Server.Jar) != filepath.Clean(launchPath) {
+				ui.PrintInfo("Clearing server.jar: %s is the %s server", pkg.Server.Jar, pkg.Server.MinecraftVersion)
+			}
+			pkg.Server.Jar = ""
+		}
+
+		pkg.Server.MinecraftVersion = target
+		pkg.Server.Build = build
+	} else {
+		pkg.Server.MinecraftVersion = target
+	}
+
+	contentDir := pkg.Server.ContentDir()
+	if err := os.MkdirAll(contentDir, 0755); err != nil {
+		return err
+	}
+	jars := scanPluginsDir(contentDir, pkg.Server.Type)
+
+	modrinthClient := sources.NewModrinthClient(endpoints.Modrinth...)
+	hangarClient := sources.NewHangarClient(endpoints.Hangar...)
+	for _, step := range steps {
+		plugin := pkg.Plugins[step.index]
+		ui.PrintInfo("%s %s -> %s", plugin.Name, plugin.Version, step.version)
+
+		if old := findInstalledJar(plugin, lockFile.Plugins[step.lockKey], jars); old != nil {
+			if err := tx.moveAside(filepath.Join(contentDir, old.File)); err != nil {
+				return err
+			}
+		}
+
+		newPath := filepath.Join(contentDir, step.filename)
+		if err := tx.moveAside(newPath); err != nil {
+			return err
+		}
+		if step.source == "hangar" {
+			err = downloadFileHangar(hangarClient, step.url, step.filename, contentDir, step.hash, step.lockKey, -1)
+		} else {
+			err = downloadFileModrinth(modrinthClient, step.url, step.filename, contentDir, step.hash, step.lockKey, -1)
+		}
+		tx.created = append(tx.created, newPath)
+		if err != nil {
+			return fmt.Errorf("error downloading %s %s: %w", plugin.Name, step.version, err)
+		}
+
+		pkg.Plugins[step.index].Version = step.version
+		lockFile.Plugins[step.lockKey] = models.PluginLock{
+			Name:    plugin.Name,
+			Version: step.version,
+			Hash:    step.hash,
+			File:    step.filename,
+		}
+	}
+
+	if err := pkg.SaveToFile("package.yml"); err != nil {
+		return fmt.Errorf("error saving package.yml: %w", err)
+	}
+	if err := lockFile.SaveToFile("package-lock.yml"); err != nil {
+		return fmt.Errorf("error saving package-lock.yml: %w", err)
+	}
+	return nil
+}
+
+// upgradeTransaction records what an upgrade changed on disk so it can be undone.
+// Replaced files are moved into a backup directory rather than deleted.
+type upgradeTransaction struct {
+	backupDir string
+	moved     [][2]string // original path, backup path
+	created   []string    // files and directories written by the upgrade
+	saved     map[string][]byte
+}
+
+// upgradePackageFiles are restored byte for byte on rollback
+var upgradePackageFiles = []string{"package.yml", "package-lock.yml"}
+
+func newUpgradeTransaction() (*upgradeTransaction, error) {
+	backupDir, err := os.MkdirTemp(".", ".mpm-upgrade-")
+	if err != nil {
+		return nil, fmt.Errorf("could not create backup directory: %w", err)
+	}
+
+	tx := &upgradeTransaction{backupDir: backupDir, saved: map[string][]byte{}}
+	for _, name := range upgradePackageFiles {
+		data, err := os.ReadFile(name)
+		if err == nil {
+			tx.saved[name] = data
+		} else if !os.IsNotExist(err) {
+			os.RemoveAll(backupDir)
+			return nil, err
+		}
+	}
+	return tx, nil
+}
+
+// newEntries lists the entries of the current directory that aren't in before
+func newEntries(before []os.DirEntry) []string {
+	existed := map[string]bool{}
+	for _, entry := range before {
+		existed[entry.Name()] = true
+	}
+
+	entries, err := os.ReadDir(".")
+	if err != nil {
+		return nil
+	}
+	var created []string
+	for _, entry := range entries {
+		if !existed[entry.Name()] {
+			created = append(created, entry.Name())
+		}
+	}
+	return created
+}
+
+// moveAside moves an existing file or directory into the backup directory
+func (tx *upgradeTransaction) moveAside(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return nil
+	}
+	for _, m := range tx.moved {
+		if m[0] == path {
+			return nil
+		}
+	}
+
+	backup := filepath.Join(tx.backupDir, fmt.Sprintf("%d-%s", len(tx.moved), filepath.Base(path)))
+	if err := os.Rename(path, backup); err != nil {
+		return fmt.Errorf("could not back up %s: %w", path, err)
+	}
+	tx.moved = append(tx.moved, [2]string{path, backup})
+	return nil
+}
+
+// rollback removes what the upgrade wrote and puts the backed up files back
+func (tx *upgradeTransaction) rollback() error {
+	var failed []string
+	for _, path := range tx.created {
+		if err := os.RemoveAll(path); err != nil {
+			failed = append(failed, path)
+		}
+	}
+	for i := len(tx.moved) - 1; i >= 0; i-- {
+		if err := os.Rename(tx.moved[i][1], tx.moved[i][0]); err != nil {
+			failed = append(failed, tx.moved[i][0])
+		}
+	}
+	for _, name := range upgradePackageFiles {
+		var err error
+		if data, ok := tx.saved[name]; ok {
+			err = os.WriteFile(name, data, 0644)
+		} else {
+			err = os.Remove(name)
+		}
+		if err != nil && !os.IsNotExist(err) {
+			failed = append(failed, name)
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("could not restore %s", strings.Join(failed, ", "))
+	}
+	return os.RemoveAll(tx.backupDir)
+}
+
+// commit drops the backups of a finished upgrade
+func (tx *upgradeTransaction) commit() {
+	os.RemoveAll(tx.backupDir)
+}
+
+func runServerBootstrap(cmd *cobra.Command, args []string) error {
+	pkg, err := models.LoadPackageFromFile("package.yml")
+	if err != nil {
+		return fmt.Errorf("could not read package.yml: %w", err)
+	}
+	return bootstrapServer(pkg, bootstrapTimeout)
+}
+
+// bootstrapFile is the configuration file a server type writes on its first boot
+func bootstrapFile(serverType string) string {
+	switch strings.ToLower(serverType) {
+	case "velocity":
+		return "velocity.toml"
+	case "bungeecord", "waterfall":
+		return "config.yml"
+	default:
+		return "server.properties"
+	}
+}
+
+// bootstrapServer starts the server, waits until it has finished starting and
+// stops it with its stop command. The server is killed if either takes longer
+// than timeout.
+func bootstrapServer(pkg *models.Package, timeout time.Duration) error {
+	serverConfig := pkg.Server
+	if err := ensureEULA(serverConfig); err != nil {
+		return err
+	}
+	if err := applyProperties(pkg); err != nil {
+		return err
+	}
+
+	serverCmd, display, err := serverCommand(serverConfig)
+	if err != nil {
+		return err
+	}
+
+	// Server output must not mix with structured output
+	output := ui.Out
+	serverCmd.Stderr = output
+
+	stdin, err := serverCmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create stdin pipe: %w", err)
+	}
+	stdout, err := serverCmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+
+	ui.PrintInfo("Starting %s server once to generate its configuration files", serverConfig.Type)
+	fmt.Fprintf(output, "  Command: %s\n", display)
+	if err := serverCmd.Start(); err != nil {
+		return fmt.Errorf("failed to start server: %w", err)
+	}
+
+	// The output is read to the end before waiting for the server to exit
+	ready := make(chan struct{})
+	exited := make(chan error, 1)
+	go func() {
+		patterns := readyPatterns(serverConfig.Type)
+		scanner := bufio.NewScanner(stdout)
+		started := false
+		for scanner.Scan() {
+			line := scanner.Text()
+			fmt.Fprintln(output, line)
+			if !started && containsAny(line, patterns) {
+				started = true
+				close(ready)
+			}
+		}
+		exited <- serverCmd.Wait()
+	}()
+
+	select {
+	case <-ready:
+	case err := <-exited:
+		if err != nil {
+			return fmt.Errorf("the server stopped before it finished starting: %w", err)
+		}
+		return fmt.Errorf("the server stopped before it finished starting")
+	case <-time.After(timeout):
+		serverCmd.Process.Kill()
+		<-exited
+		return fmt.Errorf("the server did not finish starting within %s", timeout)
+	}
+
+	stop := stopCommand(serverConfig.Type)
+	ui.PrintInfo("Server started, stopping it ('%s')", stop)
+	console := &serverConsole{w: stdin}
+	if err := console.send(stop); err != nil {
+		ui.PrintWarning("Failed to send '%s': %v", stop, err)
+	}
+
+	select {
+	case err := <-exited:
+		if err != nil {
+			ui.PrintWarning("The server exited with an error while stopping: %v", err)
+		}
+	case <-time.After(timeout):
+		ui.PrintWarning("The server did not stop within %s, killing it", timeout)
+		serverCmd.Process.Kill()
+		<-exited
+	}
+
+	file := bootstrapFile(serverConfig.Type)
+	if _, err := os.Stat(file); err != nil {
+		ui.PrintWarning("The server stopped without writing %s", file)
+		return nil
+	}
+	ui.PrintSuccess("Server bootstrapped (%s)", file)
+	return nil
+}
@@ -0,0 +1,286 @@
+package cmd
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/storrealbac/mpm/internal/models"
+	"github.com/storrealbac/mpm/internal/server"
+)
+
+// chdirTemp runs the rest of the test in a new temporary directory
+func chdirTemp(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+// writeFiles creates files (a trailing / makes a directory) in the current directory
+func writeFiles(t *testing.T, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		if strings.HasSuffix(name, "/") {
+			if err := os.MkdirAll(name, 0755); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// readTree lists every file under the current directory with its content,
+// skipping the upgrade backups
+func readTree(t *testing.T) map[string]string {
+	t.Helper()
+	tree := map[string]string{}
+	err := filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if strings.HasPrefix(path, ".mpm-upgrade-") {
+				return filepath.SkipDir
+			}
+			if path != "." {
+				tree[path+"/"] = ""
+			}
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		tree[path] = string(data)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tree
+}
+
+func TestUpgradeTransaction(t *testing.T) {
+	tests := []struct {
+		name       string
+		serverType string
+		before     map[string]string // files before the upgrade
+		written    map[string]string // files the upgrade writes
+		commit     bool
+		want       map[string]string // files after rollback or commit
+	}{
+		{
+			name:       "rollback restores the server jar and package files",
+			serverType: "paper",
+			before: map[string]string{
+				"server.jar": "paper 1.20.4", "package.yml": "old", "package-lock.yml": "old lock",
+				"plugins/": "", "plugins/a.jar": "a",
+			},
+			written: map[string]string{
+				"server.jar": "paper 1.21", "package.yml": "new", "package-lock.yml": "new lock",
+				"cache/": "", "cache/mojang.jar": "x",
+			},
+			want: map[string]string{
+				"server.jar": "paper 1.20.4", "package.yml": "old", "package-lock.yml": "old lock",
+				"plugins/": "", "plugins/a.jar": "a",
+			},
+		},
+		{
+			name:       "rollback removes package files that didn't exist",
+			serverType: "paper",
+			before:     map[string]string{"server.jar": "old", "package.yml": "old"},
+			written:    map[string]string{"server.jar": "new", "package-lock.yml": "lock"},
+			want:       map[string]string{"server.jar": "old", "package.yml": "old"},
+		},
+		{
+			name:       "rollback restores every file of a mod loader",
+			serverType: "quilt",
+			before: map[string]string{
+				"quilt-server-launch.jar": "quilt 0.25", "server.jar": "vanilla 1.20.4",
+				"libraries/": "", "libraries/loader.jar": "0.25", "package.yml": "old",
+			},
+			written: map[string]string{
+				"quilt-server-launch.jar": "quilt 0.26", "server.jar": "vanilla 1.21",
+				"libraries/": "", "libraries/loader.jar": "0.26", "libraries/new.jar": "new",
+			},
+			want: map[string]string{
+				"quilt-server-launch.jar": "quilt 0.25", "server.jar": "vanilla 1.20.4",
+				"libraries/": "", "libraries/loader.jar": "0.25", "package.yml": "old",
+			},
+		},
+		{
+			name:       "rollback of a first install",
+			serverType: "forge",
+			before:     map[string]string{"package.yml": "old"},
+			written: map[string]string{
+				server.LaunchFile("forge"): "run", "user_jvm_args.txt": "# args",
+				"libraries/": "", "libraries/forge.jar": "forge",
+			},
+			want: map[string]string{"package.yml": "old"},
+		},
+		{
+			name:       "commit keeps the upgrade",
+			serverType: "paper",
+			before:     map[string]string{"server.jar": "old", "package.yml": "old"},
+			written:    map[string]string{"server.jar": "new", "package.yml": "new"},
+			commit:     true,
+			want:       map[string]string{"server.jar": "new", "package.yml": "new"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chdirTemp(t)
+			writeFiles(t, tt.before)
+
+			// The same steps as applyUpgrade
+			tx, err := newUpgradeTransaction()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, file := range server.ServerFiles(tt.serverType) {
+				if err := tx.moveAside(file); err != nil {
+					t.Fatal(err)
+				}
+			}
+			before, err := os.ReadDir(".")
+			if err != nil {
+				t.Fatal(err)
+			}
+			writeFiles(t, tt.written)
+			tx.created = append(tx.created, server.ServerFiles(tt.serverType)...)
+			tx.created = append(tx.created, newEntries(before)...)
+
+			if tt.commit {
+				tx.commit()
+			} else if err := tx.rollback(); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := readTree(t); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("files = %v, want %v", got, tt.want)
+			}
+			if _, err := os.Stat(tx.backupDir); !os.IsNotExist(err) {
+				t.Errorf("backup directory %s was left behind", tx.backupDir)
+			}
+		})
+	}
+}
+
+func TestNewEntries(t *testing.T) {
+	chdirTemp(t)
+	writeFiles(t, map[string]string{"server.jar": "", "plugins/": ""})
+	before, err := os.ReadDir(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(t, map[string]string{"server.jar": "new", "cache/": "", "versions/": "", "plugins/b.jar": ""})
+
+	got := newEntries(before)
+	sort.Strings(got)
+	if want := []string{"cache", "versions"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("newEntries() = %v, want %v", got, want)
+	}
+}
+
+func TestApplyUpgradeCustomJar(t *testing.T) {
+	const jar121 = "vanilla 1.21"
+	sum := sha1.Sum([]byte(jar121))
+
+	tests := []struct {
+		name    string
+		sha1    string
+		wantErr bool
+		wantJar string // server.jar in package.yml afterwards
+		files   map[string]string
+	}{
+		{
+			name:    "the upgrade starts from the new jar",
+			sha1:    hex.EncodeToString(sum[:]),
+			wantJar: "",
+			files:   map[string]string{"custom-1.20.4.jar": "vanilla 1.20.4", "server.jar": jar121, "plugins/": ""},
+		},
+		{
+			name:    "rollback keeps the custom jar",
+			sha1:    strings.Repeat("0", 40),
+			wantErr: true,
+			wantJar: "custom-1.20.4.jar",
+			files:   map[string]string{"custom-1.20.4.jar": "vanilla 1.20.4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mojang *httptest.Server
+			mojang = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/mc/game/version_manifest_v2.json":
+					fmt.Fprintf(w, `{"versions": [{"id": "1.21", "type": "release", "url": "%s/1.21.json"}]}`, mojang.URL)
+				case "/1.21.json":
+					fmt.Fprintf(w, `{"downloads": {"server": {"sha1": "%s", "url": "%s/server.jar"}}}`, tt.sha1, mojang.URL)
+				case "/server.jar":
+					io.WriteString(w, jar121)
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer mojang.Close()
+
+			chdirTemp(t)
+			writeFiles(t, map[string]string{
+				"custom-1.20.4.jar": "vanilla 1.20.4",
+				"package.yml":       "name: test\nversion: 1.0.0\nserver:\n  type: vanilla\n  minecraft_version: 1.20.4\n  jar: custom-1.20.4.jar\nplugins: []\n",
+			})
+			pkg, err := models.LoadPackageFromFile("package.yml")
+			if err != nil {
+				t.Fatal(err)
+			}
+			lockFile := &models.PackageLock{Plugins: map[string]models.PluginLock{}}
+
+			err = applyUpgrade(pkg, lockFile, "1.21", nil, models.Endpoints{Mojang: []string{mojang.URL}})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+
+			saved, err := models.LoadPackageFromFile("package.yml")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if saved.Server.Jar != tt.wantJar {
+				t.Errorf("server.jar = %q, want %q", saved.Server.Jar, tt.wantJar)
+			}
+			launch, err := findServerJar(saved.Server)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tree := readTree(t)
+			if tree[launch] != tt.files[launch] {
+				t.Errorf("mpm serve would start %s (%q)", launch, tree[launch])
+			}
+			delete(tree, "package.yml")
+			delete(tree, "package-lock.yml")
+			if !reflect.DeepEqual(tree, tt.files) {
+				t.Errorf("files = %v, want %v", tree, tt.files)
+			}
+		})
+	}
+}
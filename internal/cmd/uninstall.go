@@ -11,16 +11,27 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var uninstallPurge bool
+
 var uninstallCmd = &cobra.Command{
 	Use: "uninstall [plugin...]",
 
 	Short: "Uninstall plugins",
-	Long:  `Removes plugins from the plugins/ directory and package.yml.`,
-	RunE:  runUninstall,
+	Long: `Removes plugins from the plugins/ directory, package.yml and package-lock.yml.
+
+Plugins are matched by their exact name, Modrinth ID, Hangar ID or local file, and
+only the jar recorded for them in package-lock.yml is deleted. Use --purge to also
+delete the plugin's data folder (plugins/<Name>/) after a confirmation.
+
+Examples:
+  mpm uninstall luckperms
+  mpm uninstall ViaVersion/ViaVersion --purge`,
+	RunE: runUninstall,
 }
 
 func init() {
-	// Set usage template (simplified)
+	uninstallCmd.Flags().BoolVar(&uninstallPurge, "purge", false, "Also delete the plugin's data folder (plugins/<Name>/)")
+
 	// Set usage template (simplified)
 	uninstallCmd.SetUsageTemplate(fmt.Sprintf(`%s
   {{.UseLine}}
@@ -43,13 +54,20 @@ func runUninstall(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("could not read package.yml: %w", err)
 	}
 
-	pluginsDir := "plugins" // Should be configurable or read from root flag
+	lockFile, err := models.LoadPackageLockFromFile("package-lock.yml")
+	if err != nil {
+		return fmt.Errorf("error loading package-lock.yml: %w", err)
+	}
+
+	pluginsDir := pkg.Server.ContentDir()
+	result := commandResult{Command: "uninstall", Success: true, Plugins: []pluginResult{}}
 
 	for _, pluginName := range args {
-		// 1. Remove from package.yml
+		// 1. Find the entry by exact name or ID
 		foundIndex := -1
 		for i, p := range pkg.Plugins {
-			if strings.EqualFold(p.Name, pluginName) || strings.EqualFold(p.ModrinthID, pluginName) {
+			if strings.EqualFold(p.Name, pluginName) || strings.EqualFold(p.ModrinthID, pluginName) ||
+				strings.EqualFold(p.HangarID, pluginName) || strings.EqualFold(p.File, pluginName) {
 				foundIndex = i
 				break
 			}
@@ -57,37 +75,129 @@ func runUninstall(cmd *cobra.Command, args []string) error {
 
 		if foundIndex == -1 {
 			ui.PrintWarning("Plugin '%s' not found in package.yml", pluginName)
+			result.Success = false
+			result.Plugins = append(result.Plugins, pluginResult{Plugin: pluginName, Status: "not-found", Error: "not found in package.yml"})
 			continue
 		}
 
-		// 2. Remove file
-		// Heuristic: Search for files starting with the name in plugins/
-		// This is risky, ideally we should store the filename in package.yml
-		files, err := os.ReadDir(pluginsDir)
-		if err == nil {
-			for _, file := range files {
-				if !file.IsDir() && strings.HasSuffix(file.Name(), ".jar") {
-					// Simple heuristic: if filename starts with plugin name (case insensitive)
-					if strings.HasPrefix(strings.ToLower(file.Name()), strings.ToLower(pluginName)) {
-						fullPath := filepath.Join(pluginsDir, file.Name())
-						if err := os.Remove(fullPath); err != nil {
-							ui.PrintError("Error deleting %s: %v", file.Name(), err)
-						} else {
-							ui.PrintSuccess("Deleted file: %s", file.Name())
-						}
-					}
-				}
+		plugin := pkg.Plugins[foundIndex]
+		source, id := pluginSourceAndID(plugin)
+		entry := pluginResult{Plugin: plugin.Name, ID: id, Source: source, Version: plugin.Version, Status: "uninstalled"}
+
+		// 2. Remove the jar recorded in the lock (or named by a local entry)
+		jars := scanPluginsDir(pluginsDir, pkg.Server.Type)
+		installed := findLockedJar(plugin, lockFile.Plugins[id], jars)
+		if installed != nil {
+			entry.File = installed.File
+			if err := os.Remove(filepath.Join(pluginsDir, installed.File)); err != nil {
+				ui.PrintError("Error deleting %s: %v", installed.File, err)
+				entry.Status = "error"
+				entry.Error = err.Error()
+				result.Success = false
+			} else {
+				ui.PrintSuccess("Deleted file: %s", installed.File)
 			}
+		} else if guess := findInstalledJar(plugin, lockFile.Plugins[id], jars); guess != nil {
+			// Only files recorded in the lock are deleted; a jar that merely looks like the plugin is left alone
+			ui.PrintWarning("%s looks like %s but isn't recorded in package-lock.yml, leaving it as an unmanaged jar", guess.File, plugin.Name)
+			entry.Status = "unmanaged"
+			entry.File = guess.File
+			entry.Error = "not tracked in package-lock.yml"
+		} else {
+			ui.PrintWarning("No installed jar found for '%s'", plugin.Name)
+		}
+
+		// 3. Warn about plugins that need the removed one
+		declaredName := plugin.Name
+		if installed != nil && installed.Descriptor != nil && installed.Descriptor.Name != "" {
+			declaredName = installed.Descriptor.Name
 		}
+		warnDependents(plugin, declaredName, pkg.Plugins, jars, installed)
 
-		// Remove from package.yml
+		// 4. Optionally delete the data folder
+		if uninstallPurge {
+			if err := purgeDataFolder(pluginsDir, declaredName); err != nil {
+				ui.PrintError("Error deleting data folder of %s: %v", declaredName, err)
+				entry.Error = err.Error()
+				result.Success = false
+			}
+		}
+
+		// 5. Remove from package.yml and package-lock.yml
 		pkg.Plugins = append(pkg.Plugins[:foundIndex], pkg.Plugins[foundIndex+1:]...)
-		ui.PrintSuccess("Removed from package.yml: %s", pluginName)
+		delete(lockFile.Plugins, id)
+		ui.PrintSuccess("Removed from package.yml: %s", plugin.Name)
+		result.Plugins = append(result.Plugins, entry)
 	}
 
 	if err := pkg.SaveToFile("package.yml"); err != nil {
 		return fmt.Errorf("error saving package.yml: %w", err)
 	}
 
+	if err := lockFile.SaveToFile("package-lock.yml"); err != nil {
+		return fmt.Errorf("error saving package-lock.yml: %w", err)
+	}
+
+	if ui.IsStructured() {
+		if err := ui.PrintResult(result); err != nil {
+			return err
+		}
+	}
+
+	if !result.Success {
+		return fmt.Errorf("some plugins could not be uninstalled")
+	}
+
+	return nil
+}
+
+// warnDependents warns about installed plugins and package.yml entries that
+// depend on a plugin being removed
+func warnDependents(removed models.Plugin, declaredName string, plugins []models.Plugin, jars []installedJar, removedJar *installedJar) {
+	for _, j := range jars {
+		if j.Descriptor == nil || (removedJar != nil && j.File == removedJar.File) {
+			continue
+		}
+		if containsString(j.Descriptor.Depend, declaredName) {
+			ui.PrintWarning("%s depends on %s and will fail to load without it", j.Descriptor.Name, declaredName)
+		} else if containsString(j.Descriptor.SoftDepend, declaredName) {
+			ui.PrintInfo("%s optionally uses %s", j.Descriptor.Name, declaredName)
+		}
+	}
+
+	for _, p := range plugins {
+		if p.Name != removed.Name && containsString(p.Dependencies, removed.Name, declaredName) {
+			ui.PrintWarning("%s lists %s as a dependency in package.yml", p.Name, removed.Name)
+		}
+	}
+}
+
+// purgeDataFolder deletes plugins/<name>/ after a confirmation
+func purgeDataFolder(pluginsDir, name string) error {
+	// Never let a bad name resolve to plugins/ itself or outside of it
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid data folder name %q", name)
+	}
+
+	dataDir := filepath.Join(pluginsDir, name)
+	info, err := os.Stat(dataDir)
+	if err != nil || !info.IsDir() {
+		ui.PrintInfo("No data folder found at %s", dataDir)
+		return nil
+	}
+
+	if !confirm(fmt.Sprintf("Delete the data folder %s and all its contents?", dataDir)) {
+		if !isInteractive() {
+			ui.PrintInfo("Kept data folder %s (use --yes to delete it without a prompt)", dataDir)
+		} else {
+			ui.PrintInfo("Kept data folder %s", dataDir)
+		}
+		return nil
+	}
+
+	if err := os.RemoveAll(dataDir); err != nil {
+		return err
+	}
+	ui.PrintSuccess("Deleted data folder: %s", dataDir)
 	return nil
 }
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/storrealbac/mpm/internal/ui"
+)
+
+func TestUninstallOutput(t *testing.T) {
+	const packageYml = `name: test
+version: 1.0.0
+server:
+  type: paper
+plugins:
+  - name: LuckPerms
+    version: "5.4"
+    modrinth_id: luckperms
+  - name: Vault
+    version: "1.7"
+    hangar_id: MilkBowl/Vault
+  - name: Chunky
+    version: "1.4"
+    modrinth_id: chunky
+`
+	const lockYml = `plugins:
+  luckperms:
+    name: LuckPerms
+    version: "5.4"
+    hash: abc
+    file: LuckPerms-5.4.jar
+`
+
+	tests := []struct {
+		name string
+		arg  string
+		want pluginResult
+		jars []string // plugins/ afterwards
+	}{
+		{
+			name: "tracked jar is deleted",
+			arg:  "luckperms",
+			want: pluginResult{Plugin: "LuckPerms", ID: "luckperms", Source: "modrinth", Version: "5.4", Status: "uninstalled", File: "LuckPerms-5.4.jar"},
+			jars: []string{"Vault.jar"},
+		},
+		{
+			name: "lookalike jar is left alone",
+			arg:  "Vault",
+			want: pluginResult{Plugin: "Vault", ID: "MilkBowl/Vault", Source: "hangar", Version: "1.7", Status: "unmanaged", File: "Vault.jar", Error: "not tracked in package-lock.yml"},
+			jars: []string{"LuckPerms-5.4.jar", "Vault.jar"},
+		},
+		{
+			name: "nothing installed",
+			arg:  "chunky",
+			want: pluginResult{Plugin: "Chunky", ID: "chunky", Source: "modrinth", Version: "1.4", Status: "uninstalled"},
+			jars: []string{"LuckPerms-5.4.jar", "Vault.jar"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chdirTemp(t)
+			writeFiles(t, map[string]string{"package.yml": packageYml, "package-lock.yml": lockYml, "plugins/": ""})
+			writePluginJar(t, "plugins", "LuckPerms-5.4.jar", map[string]string{"plugin.yml": "name: LuckPerms\nversion: 5.4\nmain: a.LuckPerms\n"})
+			writePluginJar(t, "plugins", "Vault.jar", map[string]string{"plugin.yml": "name: Vault\nversion: 1.7\nmain: a.Vault\n"})
+
+			result, err := captureResult(t, ui.OutputJSON, func() error { return runUninstall(uninstallCmd, []string{tt.arg}) })
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := []pluginResult{tt.want}; !reflect.DeepEqual(result.Plugins, want) {
+				t.Errorf("plugins = %+v, want %+v", result.Plugins, want)
+			}
+
+			entries, err := os.ReadDir("plugins")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var jars []string
+			for _, entry := range entries {
+				jars = append(jars, entry.Name())
+			}
+			if !reflect.DeepEqual(jars, tt.jars) {
+				t.Errorf("plugins/ = %v, want %v", jars, tt.jars)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/storrealbac/mpm/internal/models"
+)
+
+// unmanagedJar is a jar in the plugins directory that no package.yml entry accounts for
+type unmanagedJar struct {
+	installedJar
+	Reason   string
+	Orphaned string // lock key of the stale entry that recorded this jar, if any
+}
+
+// findUnmanagedJars returns the jars that don't belong to any package.yml entry:
+// dropped in by hand, or left behind by a removed plugin or an older version
+func findUnmanagedJars(pkg *models.Package, lockFile *models.PackageLock, jars []installedJar) []unmanagedJar {
+	managed := make(map[string]bool)
+	inPackage := make(map[string]bool)
+	for _, plugin := range pkg.Plugins {
+		_, id := pluginSourceAndID(plugin)
+		inPackage[id] = true
+		if installed := findInstalledJar(plugin, lockFile.Plugins[id], jars); installed != nil {
+			managed[installed.File] = true
+		}
+	}
+
+	// Lock entries for plugins that are no longer in package.yml
+	orphanedFiles := make(map[string]string)
+	for key, entry := range lockFile.Plugins {
+		if !inPackage[key] && entry.File != "" {
+			orphanedFiles[entry.File] = key
+		}
+	}
+
+	var unmanaged []unmanagedJar
+	for _, j := range jars {
+		if managed[j.File] {
+			continue
+		}
+		item := unmanagedJar{installedJar: j, Reason: "not tracked in package-lock.yml"}
+		if key, ok := orphanedFiles[j.File]; ok {
+			item.Orphaned = key
+			item.Reason = fmt.Sprintf("orphaned: %s is no longer in package.yml", key)
+		}
+		unmanaged = append(unmanaged, item)
+	}
+
+	return unmanaged
+}
+
+// orphanedLockEntries returns the lock keys of plugins no longer in package.yml
+func orphanedLockEntries(pkg *models.Package, lockFile *models.PackageLock) []string {
+	inPackage := make(map[string]bool)
+	for _, plugin := range pkg.Plugins {
+		_, id := pluginSourceAndID(plugin)
+		inPackage[id] = true
+	}
+
+	var orphaned []string
+	for key := range lockFile.Plugins {
+		if !inPackage[key] {
+			orphaned = append(orphaned, key)
+		}
+	}
+	sort.Strings(orphaned)
+	return orphaned
+}
+
+// unmanagedLabel describes an unmanaged jar with its declared name and version
+func unmanagedLabel(j unmanagedJar) string {
+	if j.Descriptor == nil {
+		return j.File
+	}
+	return fmt.Sprintf("%s (%s %s)", j.File, j.Descriptor.Name, j.Descriptor.Version)
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/storrealbac/mpm/internal/jar"
+	"github.com/storrealbac/mpm/internal/models"
+	"github.com/storrealbac/mpm/internal/ui"
+)
+
+func TestFindUnmanagedJars(t *testing.T) {
+	jars := []installedJar{
+		{File: "EssentialsX-2.20.1.jar", Descriptor: &jar.Descriptor{Name: "Essentials"}},
+		{File: "LuckPerms-5.3.jar", Descriptor: &jar.Descriptor{Name: "LuckPerms"}},
+		{File: "LuckPerms-5.4.jar", Descriptor: &jar.Descriptor{Name: "LuckPerms"}},
+		{File: "Vault.jar", Descriptor: &jar.Descriptor{Name: "Vault"}},
+		{File: "custom.jar"},
+		{File: "dropped-in.jar"},
+	}
+	pkg := &models.Package{Plugins: []models.Plugin{
+		{Name: "LuckPerms", ModrinthID: "luckperms"},
+		{Name: "EssentialsX", HangarID: "EssentialsX/Essentials"}, // matched by descriptor
+		{Name: "Custom", File: "custom.jar"},
+	}}
+	lockFile := &models.PackageLock{Plugins: map[string]models.PluginLock{
+		"luckperms": {File: "LuckPerms-5.4.jar"},
+		"vault":     {File: "Vault.jar"}, // removed from package.yml
+	}}
+
+	type item struct{ File, Reason, Orphaned string }
+	want := []item{
+		{"LuckPerms-5.3.jar", "not tracked in package-lock.yml", ""},
+		{"Vault.jar", "orphaned: vault is no longer in package.yml", "vault"},
+		{"dropped-in.jar", "not tracked in package-lock.yml", ""},
+	}
+
+	var got []item
+	for _, j := range findUnmanagedJars(pkg, lockFile, jars) {
+		got = append(got, item{j.File, j.Reason, j.Orphaned})
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("findUnmanagedJars() = %v, want %v", got, want)
+	}
+
+	if got, want := orphanedLockEntries(pkg, lockFile), []string{"vault"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("orphanedLockEntries() = %v, want %v", got, want)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	const lockYml = "plugins:\n  luckperms:\n    name: LuckPerms\n    version: \"5.4\"\n    hash: \"\"\n    file: LuckPerms-5.4.jar\n  vault:\n    name: Vault\n    version: \"1.7\"\n    hash: \"\"\n    file: Vault.jar\n"
+
+	tests := []struct {
+		name       string
+		serverType string
+		contentDir string
+		dryRun     bool
+	}{
+		{name: "plugins", serverType: "paper", contentDir: "plugins"},
+		{name: "mods", serverType: "fabric", contentDir: "mods"},
+		{name: "dry run", serverType: "paper", contentDir: "plugins", dryRun: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chdirTemp(t)
+			writeFiles(t, map[string]string{
+				"package.yml":                        "name: test\nversion: 1.0.0\nserver:\n  type: " + tt.serverType + "\nplugins:\n  - name: LuckPerms\n    version: \"5.4\"\n    modrinth_id: luckperms\n",
+				"package-lock.yml":                   lockYml,
+				tt.contentDir + "/LuckPerms-5.4.jar": "managed",
+				tt.contentDir + "/Vault.jar":         "orphaned",
+				tt.contentDir + "/dropped-in.jar":    "by hand",
+			})
+			pruneQuarantineDir = "quarantine"
+			pruneDryRun = tt.dryRun
+			defer func() { pruneDryRun = false }()
+
+			result, err := captureResult(t, ui.OutputJSON, func() error { return runPrune(pruneCmd, nil) })
+			if err != nil {
+				t.Fatal(err)
+			}
+			status := "quarantined"
+			if tt.dryRun {
+				status = "unmanaged"
+			}
+			var statuses []string
+			for _, p := range result.Plugins {
+				statuses = append(statuses, p.File+" "+p.Status)
+			}
+			if want := []string{"Vault.jar " + status, "dropped-in.jar " + status}; !reflect.DeepEqual(statuses, want) {
+				t.Errorf("results = %v, want %v", statuses, want)
+			}
+
+			kept := listDir(t, tt.contentDir)
+			lockFile, err := models.LoadPackageLockFromFile("package-lock.yml")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.dryRun {
+				if want := []string{"LuckPerms-5.4.jar", "Vault.jar", "dropped-in.jar"}; !reflect.DeepEqual(kept, want) {
+					t.Errorf("%s/ = %v, want %v", tt.contentDir, kept, want)
+				}
+				if _, err := os.Stat("quarantine"); !os.IsNotExist(err) {
+					t.Error("a dry run created the quarantine folder")
+				}
+				if len(lockFile.Plugins) != 2 {
+					t.Errorf("a dry run changed the lock: %v", lockFile.Plugins)
+				}
+				return
+			}
+
+			if want := []string{"LuckPerms-5.4.jar"}; !reflect.DeepEqual(kept, want) {
+				t.Errorf("%s/ = %v, want %v", tt.contentDir, kept, want)
+			}
+			if _, ok := lockFile.Plugins["vault"]; ok {
+				t.Error("the orphaned lock entry was kept")
+			}
+
+			// Each run quarantines into its own folder; moving a jar back restores it
+			runs := listDir(t, "quarantine")
+			if len(runs) != 1 {
+				t.Fatalf("quarantine/ = %v, want one run", runs)
+			}
+			runDir := filepath.Join("quarantine", runs[0])
+			if want := []string{"Vault.jar", "dropped-in.jar"}; !reflect.DeepEqual(listDir(t, runDir), want) {
+				t.Errorf("%s = %v, want %v", runDir, listDir(t, runDir), want)
+			}
+			if err := os.Rename(filepath.Join(runDir, "dropped-in.jar"), filepath.Join(tt.contentDir, "dropped-in.jar")); err != nil {
+				t.Fatal(err)
+			}
+			data, err := os.ReadFile(filepath.Join(tt.contentDir, "dropped-in.jar"))
+			if err != nil || string(data) != "by hand" {
+				t.Errorf("restored jar = %q, %v", data, err)
+			}
+		})
+	}
+}
+
+// listDir returns the names in dir, sorted
+func listDir(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+func TestValidateStrictModsDir(t *testing.T) {
+	chdirTemp(t)
+	t.Setenv("MPM_CONFIG", filepath.Join(t.TempDir(), "config.yml"))
+	t.Setenv("MPM_MOJANG_URL", "http://127.0.0.1:1") // the version check is skipped
+	writeFiles(t, map[string]string{
+		"package.yml":       "name: test\nversion: 1.0.0\nserver:\n  type: fabric\n  minecraft_version: \"1.21\"\n  eula: true\nplugins: []\n",
+		"mods/sodium.jar":   "by hand",
+		"plugins/stray.jar": "not scanned on fabric",
+	})
+	validateStrict = true
+	defer func() { validateStrict = false }()
+
+	result, err := captureResult(t, ui.OutputJSON, func() error { return runValidate(validateCmd, nil) })
+	if err == nil {
+		t.Error("validate --strict succeeded with an unmanaged jar")
+	}
+	if want := []string{"1 unmanaged jars in mods/"}; !reflect.DeepEqual(result.Errors, want) {
+		t.Errorf("errors = %q, want %q", result.Errors, want)
+	}
+}
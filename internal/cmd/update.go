@@ -2,11 +2,11 @@ package cmd
 
 import (
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/storrealbac/mpm/internal/config"
 	"github.com/storrealbac/mpm/internal/models"
 	"github.com/storrealbac/mpm/internal/sources"
 	"github.com/storrealbac/mpm/internal/ui"
@@ -51,8 +51,16 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("error loading package-lock.yml: %w", err)
 	}
 
-	client := sources.NewModrinthClient()
+	endpoints, err := config.ResolveEndpoints(pkg)
+	if err != nil {
+		return fmt.Errorf("error loading mpm config: %w", err)
+	}
+
+	modrinthClient := sources.NewModrinthClient(endpoints.Modrinth...)
+	hangarClient := sources.NewHangarClient(endpoints.Hangar...)
+	pluginsDir := pkg.Server.ContentDir()
 	updatesFound := false
+	result := commandResult{Command: "update", Success: true, Plugins: []pluginResult{}}
 
 	ui.PrintHeader("Checking for updates...")
 
@@ -61,7 +69,7 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 		if len(args) > 0 {
 			found := false
 			for _, arg := range args {
-				if strings.EqualFold(plugin.Name, arg) || strings.EqualFold(plugin.ModrinthID, arg) {
+				if strings.EqualFold(plugin.Name, arg) || strings.EqualFold(plugin.ModrinthID, arg) || strings.EqualFold(plugin.HangarID, arg) {
 					found = true
 					break
 				}
@@ -71,86 +79,94 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 			}
 		}
 
-		versions, err := client.GetProjectVersions(plugin.ModrinthID, pkg.Server.MinecraftVersion)
+		// Local jars are updated by hand
+		if plugin.File != "" {
+			continue
+		}
+
+		source, id := pluginSourceAndID(plugin)
+		entry := pluginResult{
+			Plugin:  plugin.Name,
+			ID:      id,
+			Source:  source,
+			Version: plugin.Version,
+			Hash:    lockFile.Plugins[id].Hash,
+		}
+
+		var step *upgradeStep
+		if source == "hangar" {
+			step, err = hangarUpgradeStep(hangarClient, id, pluginGameVersion(pkg.Server), pkg.Server.Type)
+		} else {
+			step, err = modrinthUpgradeStep(modrinthClient, id, pluginGameVersion(pkg.Server), pkg.Server.Type)
+		}
 		if err != nil {
 			ui.PrintError("Error getting versions for %s: %v", plugin.Name, err)
+			entry.Status = "error"
+			entry.Error = err.Error()
+			result.Plugins = append(result.Plugins, entry)
+			result.Success = false
 			continue
 		}
 
-		if len(versions) == 0 {
+		if step == nil {
+			entry.Status = "unknown"
+			result.Plugins = append(result.Plugins, entry)
 			continue
 		}
 
-		latest := versions[0]
-		if latest.VersionNumber != plugin.Version {
+		entry.Latest = step.version
+		if step.version != plugin.Version {
 			updatesFound = true
-			ui.PrintInfo("Update available for %s: %s -> %s", plugin.Name, plugin.Version, latest.VersionNumber)
+			entry.Status = "outdated"
+			ui.PrintInfo("Update available for %s: %s -> %s", plugin.Name, plugin.Version, step.version)
 
 			if !checkOnly {
-				// Download new version
-				var fileToDownload *sources.ModrinthFile
-				for _, f := range latest.Files {
-					if f.Primary {
-						fileToDownload = &f
-						break
-					}
+				ui.PrintInfo("Downloading %s...", step.version)
+				if source == "hangar" {
+					err = downloadFileHangar(hangarClient, step.url, step.filename, pluginsDir, step.hash, id, -1)
+				} else {
+					err = downloadFileModrinth(modrinthClient, step.url, step.filename, pluginsDir, step.hash, id, -1)
 				}
-				if fileToDownload == nil && len(latest.Files) > 0 {
-					fileToDownload = &latest.Files[0]
+				if err != nil {
+					ui.PrintError("Error downloading: %v", err)
+					entry.Status = "error"
+					entry.Error = err.Error()
+					result.Success = false
+					result.Plugins = append(result.Plugins, entry)
+					continue
 				}
 
-				if fileToDownload != nil {
-					// Delete old version (simple heuristic)
-					// This is risky if we don't know the exact old filename.
-					// For now, just download the new one.
-					// TODO: Improve old version cleanup.
-
-					ui.PrintInfo("Downloading %s...", latest.VersionNumber)
-					// Use global pluginsDir if accessible, otherwise hardcode "plugins" or read from flag
-					// Since updateCmd doesn't have dir flag, assume "plugins"
-					pluginsDir := "plugins"
-					destPath := filepath.Join(pluginsDir, fileToDownload.Filename)
-
-					reader, size, err := client.DownloadFile(fileToDownload.URL)
-					if err == nil {
-						defer reader.Close()
-						file, err := os.Create(destPath)
-						if err == nil {
-							defer file.Close()
-
-							counter := &ui.WriteCounter{Total: uint64(size)}
-							io.Copy(file, io.TeeReader(reader, counter))
-
-							// Force 100% if total was unknown
-							if size <= 0 {
-								fmt.Printf("\r[%s] 100.00%%", strings.Repeat("=", 40))
-							}
-							fmt.Println()
-
-							ui.PrintSuccess("Updated to %s", latest.VersionNumber)
-
-							// Update model
-							pkg.Plugins[i].Version = latest.VersionNumber
-
-							// Save hash to package-lock.yml
-							lockFile.Plugins[plugin.ModrinthID] = models.PluginLock{
-								Name:    plugin.Name,
-								Version: latest.VersionNumber,
-								Hash:    fileToDownload.Hashes["sha512"],
-							}
-						} else {
-							ui.PrintError("Error creating file: %v", err)
-						}
-					} else {
-						ui.PrintError("Error downloading: %v", err)
+				// The new jar is in place, so the one the lock tracked can go
+				if old := lockFile.Plugins[id].File; old != "" && old != step.filename {
+					if err := os.Remove(filepath.Join(pluginsDir, old)); err != nil && !os.IsNotExist(err) {
+						ui.PrintWarning("Could not remove the previous jar %s: %v", old, err)
 					}
 				}
+
+				ui.PrintSuccess("Updated to %s", step.version)
+
+				// Update model
+				pkg.Plugins[i].Version = step.version
+				lockFile.Plugins[id] = models.PluginLock{
+					Name:    plugin.Name,
+					Version: step.version,
+					Hash:    step.hash,
+					File:    step.filename,
+				}
+
+				entry.Status = "updated"
+				entry.Version = step.version
+				entry.File = step.filename
+				entry.Hash = step.hash
 			}
 		} else {
+			entry.Status = "up-to-date"
 			if len(args) > 0 {
 				ui.PrintSuccess("%s is up to date (%s)", plugin.Name, plugin.Version)
 			}
 		}
+
+		result.Plugins = append(result.Plugins, entry)
 	}
 
 	if !updatesFound {
@@ -165,5 +181,9 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 		ui.PrintSuccess("package.yml and package-lock.yml updated.")
 	}
 
+	if ui.IsStructured() {
+		return ui.PrintResult(result)
+	}
+
 	return nil
 }
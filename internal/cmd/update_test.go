@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/storrealbac/mpm/internal/models"
+	"github.com/storrealbac/mpm/internal/sources"
+)
+
+// fakePluginSources serves LuckPerms 5.4 from a fake Modrinth and Geyser 2.4 from
+// a fake Hangar, both pointing at /files/<name>; bad lists files with a wrong hash
+func fakePluginSources(t *testing.T, bad map[string]bool) {
+	t.Helper()
+	var server *httptest.Server
+	sha512Of := func(name string) string {
+		sum := sha512.Sum512([]byte("new " + name))
+		if bad[name] {
+			sum = sha512.Sum512(nil)
+		}
+		return hex.EncodeToString(sum[:])
+	}
+	sha256Of := func(name string) string {
+		sum := sha256.Sum256([]byte("new " + name))
+		if bad[name] {
+			sum = sha256.Sum256(nil)
+		}
+		return hex.EncodeToString(sum[:])
+	}
+
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/modrinth/project/luckperms/version":
+			name := "LuckPerms-5.4.jar"
+			json.NewEncoder(w).Encode([]sources.ModrinthVersion{{
+				VersionNumber: "5.4",
+				Loaders:       []string{"paper"},
+				Files: []sources.ModrinthFile{{
+					URL:      server.URL + "/files/" + name,
+					Filename: name,
+					Primary:  true,
+					Hashes:   map[string]string{"sha512": sha512Of(name)},
+				}},
+			}})
+		case r.URL.Path == "/hangar/projects/GeyserMC/Geyser/versions":
+			name := "Geyser-2.4.jar"
+			json.NewEncoder(w).Encode(sources.HangarVersionsResponse{Result: []sources.HangarVersion{{
+				Name:                 "2.4",
+				PlatformDependencies: map[string][]string{"PAPER": {"1.21"}},
+				Downloads: map[string]sources.HangarVersionDownload{"PAPER": {
+					DownloadURL: server.URL + "/files/" + name,
+					FileInfo:    &sources.HangarFileInfo{Name: name, Sha256Hash: sha256Of(name)},
+				}},
+			}}})
+		case strings.HasPrefix(r.URL.Path, "/files/"):
+			w.Write([]byte("new " + strings.TrimPrefix(r.URL.Path, "/files/")))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("MPM_MODRINTH_URL", server.URL+"/modrinth")
+	t.Setenv("MPM_HANGAR_URL", server.URL+"/hangar")
+}
+
+func TestUpdateReplacesLockedJar(t *testing.T) {
+	const packageYml = `name: test
+version: 1.0.0
+server:
+  type: paper
+  minecraft_version: "1.21"
+plugins:
+  - name: LuckPerms
+    version: "5.3"
+    modrinth_id: luckperms
+  - name: Geyser
+    version: "2.3"
+    hangar_id: GeyserMC/Geyser
+`
+	const lockYml = `plugins:
+  luckperms:
+    name: LuckPerms
+    version: "5.3"
+    hash: old
+    file: LuckPerms-5.3.jar
+  GeyserMC/Geyser:
+    name: Geyser
+    version: "2.3"
+    hash: old
+    file: Geyser-2.3.jar
+`
+	old := map[string]string{
+		"plugins/LuckPerms-5.3.jar": "old",
+		"plugins/Geyser-2.3.jar":    "old",
+		"plugins/Other.jar":         "not tracked",
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		bad     map[string]bool
+		jars    []string          // plugins/ afterwards
+		locked  map[string]string // lock key -> file afterwards
+		wantErr bool
+	}{
+		{
+			name:   "modrinth",
+			args:   []string{"luckperms"},
+			jars:   []string{"Geyser-2.3.jar", "LuckPerms-5.4.jar", "Other.jar"},
+			locked: map[string]string{"luckperms": "LuckPerms-5.4.jar", "GeyserMC/Geyser": "Geyser-2.3.jar"},
+		},
+		{
+			name:   "hangar",
+			args:   []string{"GeyserMC/Geyser"},
+			jars:   []string{"Geyser-2.4.jar", "LuckPerms-5.3.jar", "Other.jar"},
+			locked: map[string]string{"luckperms": "LuckPerms-5.3.jar", "GeyserMC/Geyser": "Geyser-2.4.jar"},
+		},
+		{
+			name:   "checksum mismatch keeps the old jar",
+			bad:    map[string]bool{"LuckPerms-5.4.jar": true},
+			jars:   []string{"Geyser-2.4.jar", "LuckPerms-5.3.jar", "Other.jar"},
+			locked: map[string]string{"luckperms": "LuckPerms-5.3.jar", "GeyserMC/Geyser": "Geyser-2.4.jar"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chdirTemp(t)
+			t.Setenv("MPM_CONFIG", filepath.Join(t.TempDir(), "config.yml"))
+			fakePluginSources(t, tt.bad)
+			files := map[string]string{"package.yml": packageYml, "package-lock.yml": lockYml}
+			for name, content := range old {
+				files[name] = content
+			}
+			writeFiles(t, files)
+
+			if err := runUpdate(updateCmd, tt.args); (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+
+			entries, err := os.ReadDir("plugins")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var jars []string
+			for _, entry := range entries {
+				jars = append(jars, entry.Name())
+			}
+			if !reflect.DeepEqual(jars, tt.jars) {
+				t.Errorf("plugins/ = %v, want %v", jars, tt.jars)
+			}
+
+			lockFile, err := models.LoadPackageLockFromFile("package-lock.yml")
+			if err != nil {
+				t.Fatal(err)
+			}
+			locked := map[string]string{}
+			for key, entry := range lockFile.Plugins {
+				locked[key] = entry.File
+			}
+			if !reflect.DeepEqual(locked, tt.locked) {
+				t.Errorf("lock files = %v, want %v", locked, tt.locked)
+			}
+		})
+	}
+}
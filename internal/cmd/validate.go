@@ -1,27 +1,47 @@
 package cmd
 
 import (
+	"crypto/sha256"
 	"crypto/sha512"
 	"encoding/hex"
 	"fmt"
+	"hash"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/storrealbac/mpm/internal/config"
 	"github.com/storrealbac/mpm/internal/models"
+	"github.com/storrealbac/mpm/internal/server"
 	"github.com/storrealbac/mpm/internal/ui"
 	"github.com/spf13/cobra"
 )
 
+var validateStrict bool
+
 var validateCmd = &cobra.Command{
 	Use:   "validate",
 	Short: "Validate installation",
-	Long:  `Checks if all plugins defined in package.yml are present in the plugins/ directory.`,
+	Long: `Checks that server.minecraft_version is a real Minecraft version, and that all
+plugins defined in package.yml are present in the plugins/ directory (mods/ on
+mod loaders), match their recorded checksums, declare an api-version supported
+by the server and have their required dependencies installed.
+
+Jars in that directory that package.yml doesn't account for are reported as unmanaged.
+With --strict they fail the validation.
+
+It also checks the server's min_memory, max_memory and jvm_flags and the values
+of well-known keys in properties, and warns
+when max_memory is more than the machine's RAM or when no installed Java
+matches the version server.minecraft_version needs, or when the Minecraft EULA
+has not been accepted.`,
 	RunE:  runValidate,
 }
 
 func init() {
+	validateCmd.Flags().BoolVar(&validateStrict, "strict", false, "Fail when plugins/ (mods/ on mod loaders) contains unmanaged jars")
+
 	// Set usage template (simplified)
 	// Set usage template (simplified)
 	validateCmd.SetUsageTemplate(fmt.Sprintf(`%s
@@ -47,85 +67,201 @@ func runValidate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("error loading package-lock.yml: %w", err)
 	}
 
-	pluginsDir := "plugins"
+	pluginsDir := pkg.Server.ContentDir()
 	missingCount := 0
 	installedCount := 0
 	invalidCount := 0
+	incompatibleCount := 0
 
 	ui.PrintHeader("Validation Report")
 
+	// The server version must exist before any plugin can be checked against it
+	serverProblem := checkMinecraftVersion(pkg)
+
+	// Invalid memory sizes or JVM flag presets would stop mpm serve
+	if serverProblem == "" && pkg.Server.StartCommand == "" {
+		if _, err := jvmArgs(pkg.Server); err != nil {
+			serverProblem = err.Error()
+		}
+	}
+
+	// Invalid properties would stop mpm serve as well
+	if problems := propertyProblems(pkg.Properties); serverProblem == "" && len(problems) > 0 {
+		serverProblem = "invalid properties: " + strings.Join(problems, "; ")
+	}
+
+	// The wrong Java doesn't fail validation, but the server won't start with it
+	if required := requiredJava(pkg.Server); required > 0 {
+		if problem := javaProblem(required, serverJava(pkg.Server, required)); problem != "" {
+			ui.PrintWarning("%s", problem)
+		}
+	}
+
+	// Without an accepted EULA mpm serve refuses to start the server
+	if !pkg.Server.IsProxy() && !pkg.Server.Eula && !server.EULAAccepted(".") {
+		ui.PrintWarning("The Minecraft EULA (%s) has not been accepted. Set server.eula: true in package.yml or run 'mpm install --accept-eula'.", server.EULAURL)
+	}
+
 	// Create table
 	table := ui.NewTable("PLUGIN", "STATUS", "DETAILS")
+	result := commandResult{Command: "validate", Plugins: []pluginResult{}}
+
+	jars := scanPluginsDir(pluginsDir, pkg.Server.Type)
 
 	for _, plugin := range pkg.Plugins {
-		found := false
-		var matchedFile os.DirEntry
-		files, _ := os.ReadDir(pluginsDir)
-
-		// Normalize plugin name: remove special chars, replace spaces with dashes, lowercase
-		normalizedPluginName := normalizePluginName(plugin.Name)
-
-		for _, file := range files {
-			normalizedFileName := normalizePluginName(file.Name())
-			// Check if filename starts with normalized plugin name
-			if strings.HasPrefix(normalizedFileName, normalizedPluginName) {
-				found = true
-				matchedFile = file
-				break
-			}
+		// Find the jar by lock filename or the name declared in its descriptor
+		source, id := pluginSourceAndID(plugin)
+		installed := findInstalledJar(plugin, lockFile.Plugins[id], jars)
+		found := installed != nil
+
+		entry := pluginResult{
+			Plugin:  plugin.Name,
+			ID:      id,
+			Source:  source,
+			Version: plugin.Version,
+			Hash:    lockFile.Plugins[id].Hash,
 		}
+		entry.File = installedFile(installed)
 
 		var status, details string
 		if !found {
 			status = ui.CreateStatusBadge("MISSING")
 			details = fmt.Sprintf("v%s required", plugin.Version)
+			entry.Status = "missing"
 			missingCount++
 		} else {
 			// Validate Checksum if available in lock file
-			if pluginLock, exists := lockFile.Plugins[plugin.ModrinthID]; exists && pluginLock.Hash != "" {
-				fullPath := filepath.Join(pluginsDir, matchedFile.Name())
+			if pluginLock, exists := lockFile.Plugins[id]; exists && pluginLock.Hash != "" {
+				fullPath := filepath.Join(pluginsDir, installed.File)
 				valid, err := validateChecksum(fullPath, pluginLock.Hash)
 				if err != nil {
 					status = ui.CreateStatusBadge("ERROR")
 					details = fmt.Sprintf("Error reading file: %v", err)
+					entry.Status = "error"
+					entry.Error = err.Error()
 					invalidCount++
 				} else if !valid {
 					status = ui.CreateStatusBadge("INVALID")
 					details = "Checksum mismatch"
+					entry.Status = "invalid"
+					entry.Error = "checksum mismatch"
 					invalidCount++
 				} else {
 					status = ui.CreateStatusBadge("OK")
-					details = "Verified (SHA512)"
+					details = "Verified (checksum)"
+					entry.Status = "ok"
 					installedCount++
 				}
 			} else {
 				status = ui.CreateStatusBadge("OK")
 				details = "Installed (No hash)"
+				entry.Status = "ok"
 				installedCount++
 			}
+
+			// Check the jar's declared requirements against the server
+			if entry.Status == "ok" && installed.Descriptor != nil {
+				if problems := checkCompatibility(installed.Descriptor, pkg.Server.Type, pluginGameVersion(pkg.Server), jars); len(problems) > 0 {
+					status = ui.CreateStatusBadge("INCOMPATIBLE")
+					details = strings.Join(problems, "; ")
+					entry.Status = "incompatible"
+					entry.Error = details
+					installedCount--
+					incompatibleCount++
+				}
+			}
 		}
 
 		table.AddRow(plugin.Name, status, details)
+		result.Plugins = append(result.Plugins, entry)
+	}
+
+	// The same plugin listed twice (e.g. from Modrinth and Hangar) would load twice
+	duplicates := duplicateEntries(pkg.Plugins, lockFile, pluginsDir)
+
+	// Jars dropped in by hand or left behind by removed plugins
+	unmanaged := findUnmanagedJars(pkg, lockFile, jars)
+	for _, j := range unmanaged {
+		name := j.File
+		if j.Descriptor != nil {
+			name = j.Descriptor.Name
+		}
+		table.AddRow(name, ui.CreateStatusBadge("UNMANAGED"), fmt.Sprintf("%s: %s", j.File, j.Reason))
+		result.Plugins = append(result.Plugins, pluginResult{Plugin: name, File: j.File, Status: "unmanaged", Error: j.Reason})
+	}
+	unmanagedFails := validateStrict && len(unmanaged) > 0
+
+	if ui.IsStructured() {
+		result.Success = missingCount == 0 && invalidCount == 0 && incompatibleCount == 0 && len(duplicates) == 0 && !unmanagedFails && serverProblem == ""
+		if serverProblem != "" {
+			result.Errors = append(result.Errors, serverProblem)
+		}
+		if missingCount > 0 {
+			result.Errors = append(result.Errors, fmt.Sprintf("%d plugins missing", missingCount))
+		}
+		if invalidCount > 0 {
+			result.Errors = append(result.Errors, fmt.Sprintf("%d plugins have invalid checksums", invalidCount))
+		}
+		if incompatibleCount > 0 {
+			result.Errors = append(result.Errors, fmt.Sprintf("%d plugins are incompatible with the server", incompatibleCount))
+		}
+		for _, dup := range duplicates {
+			result.Errors = append(result.Errors, "duplicate plugin: "+dup)
+		}
+		if unmanagedFails {
+			result.Errors = append(result.Errors, fmt.Sprintf("%d unmanaged jars in %s/", len(unmanaged), pluginsDir))
+		}
+		if err := ui.PrintResult(result); err != nil {
+			return err
+		}
+		if !result.Success {
+			return fmt.Errorf("validation failed")
+		}
+		return nil
 	}
 
 	// Render the table
-	fmt.Println(table.Render())
-	fmt.Println()
+	fmt.Fprintln(ui.Out, table.Render())
+	fmt.Fprintln(ui.Out)
 
 	// Progress bar
 	totalPlugins := len(pkg.Plugins)
 	progressBar := ui.CreateProgressBar(installedCount, totalPlugins, 15)
-	fmt.Printf("%s\n\n", progressBar)
+	fmt.Fprintf(ui.Out, "%s\n\n", progressBar)
+
+	if len(unmanaged) > 0 && !unmanagedFails {
+		ui.PrintWarning("%d unmanaged jars in %s/, run 'mpm prune' to quarantine them.", len(unmanaged), pluginsDir)
+	}
 
 	// Summary
-	if missingCount > 0 || invalidCount > 0 {
+	if missingCount > 0 || invalidCount > 0 || incompatibleCount > 0 || len(duplicates) > 0 || unmanagedFails || serverProblem != "" {
+		if serverProblem != "" {
+			ui.PrintError("Validation failed: %s.", serverProblem)
+		}
+		for _, dup := range duplicates {
+			ui.PrintError("Duplicate plugin: %s", dup)
+		}
 		if missingCount > 0 {
 			ui.PrintWarning("Validation failed: %d plugins missing.", missingCount)
 		}
 		if invalidCount > 0 {
 			ui.PrintError("Validation failed: %d plugins have invalid checksums.", invalidCount)
 		}
-		ui.PrintInfo("Run 'mpm install' to fix.")
+		if incompatibleCount > 0 {
+			ui.PrintError("Validation failed: %d plugins are incompatible with the server.", incompatibleCount)
+		}
+		if unmanagedFails {
+			ui.PrintError("Validation failed: %d unmanaged jars in %s/.", len(unmanaged), pluginsDir)
+		}
+		if len(duplicates) > 0 {
+			ui.PrintInfo("Remove the duplicate entries from package.yml.")
+		}
+		if unmanagedFails {
+			ui.PrintInfo("Run 'mpm prune' to quarantine them, or add them to package.yml.")
+		}
+		if missingCount > 0 || invalidCount > 0 {
+			ui.PrintInfo("Run 'mpm install' to fix.")
+		}
 		return fmt.Errorf("validation failed")
 	}
 
@@ -140,7 +276,11 @@ func validateChecksum(filePath, expectedHash string) (bool, error) {
 	}
 	defer file.Close()
 
-	hasher := sha512.New()
+	// Modrinth locks record SHA-512 hashes, Hangar locks SHA-256 ones
+	var hasher hash.Hash = sha512.New()
+	if len(expectedHash) == sha256.Size*2 {
+		hasher = sha256.New()
+	}
 	if _, err := io.Copy(hasher, file); err != nil {
 		return false, err
 	}
@@ -148,3 +288,34 @@ func validateChecksum(filePath, expectedHash string) (bool, error) {
 	calculatedHash := hex.EncodeToString(hasher.Sum(nil))
 	return strings.EqualFold(calculatedHash, expectedHash), nil
 }
+
+// checkMinecraftVersion looks server.minecraft_version up in Mojang's version manifest.
+// It returns what is wrong with it, or "" when it exists or can't be checked.
+func checkMinecraftVersion(pkg *models.Package) string {
+	version := pkg.Server.MinecraftVersion
+	switch {
+	case models.IsProxy(pkg.Server.Type):
+		// Proxies are versioned on their own
+		return ""
+	case version == "":
+		return "server.minecraft_version is not set"
+	case server.IsVersionAlias(version) && !strings.EqualFold(pkg.Server.Type, "vanilla"):
+		return fmt.Sprintf("minecraft_version %q is only supported for vanilla servers", version)
+	}
+
+	endpoints, err := config.ResolveEndpoints(pkg)
+	if err != nil {
+		ui.PrintWarning("Could not check minecraft_version: %v", err)
+		return ""
+	}
+	manifest, err := server.GetVersionManifest(endpoints.Mojang)
+	if err != nil {
+		ui.PrintWarning("Could not check minecraft_version: %v", err)
+		return ""
+	}
+
+	if _, err := manifest.Resolve(version); err != nil {
+		return fmt.Sprintf("minecraft_version %q is not a known Minecraft version", version)
+	}
+	return ""
+}
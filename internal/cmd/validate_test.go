@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateChecksum(t *testing.T) {
+	content := []byte("plugin jar contents")
+	sum256 := sha256.Sum256(content)
+	sum512 := sha512.Sum512(content)
+	path := filepath.Join(t.TempDir(), "plugin.jar")
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		hash string
+		want bool
+	}{
+		{"modrinth sha512", hex.EncodeToString(sum512[:]), true},
+		{"hangar sha256", hex.EncodeToString(sum256[:]), true},
+		{"upper case", strings.ToUpper(hex.EncodeToString(sum256[:])), true},
+		{"wrong sha256", strings.Repeat("0", sha256.Size*2), false},
+		{"wrong sha512", strings.Repeat("0", sha512.Size*2), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := validateChecksum(path, tt.hash)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("validateChecksum() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := validateChecksum(filepath.Join(t.TempDir(), "missing.jar"), hex.EncodeToString(sum512[:])); err == nil {
+		t.Error("a missing file should fail")
+	}
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/storrealbac/mpm/internal/models"
+	"github.com/storrealbac/mpm/internal/server"
+	"github.com/storrealbac/mpm/internal/sources"
+	"gopkg.in/yaml.v3"
+)
+
+// UserConfig is the per-user configuration file (~/.config/mpm/config.yml)
+type UserConfig struct {
+	Endpoints models.Endpoints `yaml:"endpoints,omitempty"`
+}
+
+// UserConfigPath returns the location of the user config file.
+// MPM_CONFIG overrides the default location.
+func UserConfigPath() (string, error) {
+	if path := os.Getenv("MPM_CONFIG"); path != "" {
+		return path, nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "mpm", "config.yml"), nil
+}
+
+// LoadUserConfig loads the user config file, returning an empty config if it doesn't exist
+func LoadUserConfig() (*UserConfig, error) {
+	path, err := UserConfigPath()
+	if err != nil {
+		return &UserConfig{}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &UserConfig{}, nil
+		}
+		return nil, err
+	}
+
+	var cfg UserConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+// ResolveEndpoints merges endpoint settings from every source.
+// Precedence (highest first): environment variables, package.yml, user config, defaults.
+// Each endpoint is a list of base URLs (mirrors) that are tried in order.
+func ResolveEndpoints(pkg *models.Package) (models.Endpoints, error) {
+	var resolved models.Endpoints
+
+	userCfg, err := LoadUserConfig()
+	if err != nil {
+		return resolved, err
+	}
+
+	var pkgEndpoints models.Endpoints
+	if pkg != nil {
+		pkgEndpoints = pkg.Endpoints
+	}
+
+	resolved.Modrinth = pick("MPM_MODRINTH_URL", pkgEndpoints.Modrinth, userCfg.Endpoints.Modrinth, sources.ModrinthBaseURL)
+	resolved.Hangar = pick("MPM_HANGAR_URL", pkgEndpoints.Hangar, userCfg.Endpoints.Hangar, sources.HangarBaseURL)
+	resolved.PaperMC = pick("MPM_PAPERMC_URL", pkgEndpoints.PaperMC, userCfg.Endpoints.PaperMC, server.PaperMCBaseURL)
+	resolved.Purpur = pick("MPM_PURPUR_URL", pkgEndpoints.Purpur, userCfg.Endpoints.Purpur, server.PurpurBaseURL)
+	resolved.BuildTools = pick("MPM_BUILDTOOLS_URL", pkgEndpoints.BuildTools, userCfg.Endpoints.BuildTools, server.BuildToolsURL)
+	resolved.Sponge = pick("MPM_SPONGE_URL", pkgEndpoints.Sponge, userCfg.Endpoints.Sponge, server.SpongeAPIURL)
+	resolved.Fabric = pick("MPM_FABRIC_URL", pkgEndpoints.Fabric, userCfg.Endpoints.Fabric, server.FabricMetaURL)
+	resolved.Quilt = pick("MPM_QUILT_URL", pkgEndpoints.Quilt, userCfg.Endpoints.Quilt, server.QuiltMavenURL)
+	resolved.Forge = pick("MPM_FORGE_URL", pkgEndpoints.Forge, userCfg.Endpoints.Forge, server.ForgeMavenURL)
+	resolved.NeoForge = pick("MPM_NEOFORGE_URL", pkgEndpoints.NeoForge, userCfg.Endpoints.NeoForge, server.NeoForgeMavenURL)
+	resolved.Mojang = pick("MPM_MOJANG_URL", pkgEndpoints.Mojang, userCfg.Endpoints.Mojang, server.MojangMetaURL)
+	resolved.Pufferfish = pick("MPM_PUFFERFISH_URL", pkgEndpoints.Pufferfish, userCfg.Endpoints.Pufferfish, server.PufferfishJenkinsURL)
+	resolved.GitHub = pick("MPM_GITHUB_URL", pkgEndpoints.GitHub, userCfg.Endpoints.GitHub, server.GitHubAPIURL)
+	resolved.BungeeCord = pick("MPM_BUNGEECORD_URL", pkgEndpoints.BungeeCord, userCfg.Endpoints.BungeeCord, server.BungeeCordJenkinsURL)
+	resolved.Adoptium = pick("MPM_ADOPTIUM_URL", pkgEndpoints.Adoptium, userCfg.Endpoints.Adoptium, server.AdoptiumAPIURL)
+
+	return resolved, nil
+}
+
+// pick returns the first non-empty list of base URLs.
+// Environment variables accept a comma-separated list of mirrors.
+func pick(envVar string, fromPackage, fromUser []string, fallback string) []string {
+	if value := os.Getenv(envVar); value != "" {
+		if urls := cleanURLs(strings.Split(value, ",")); len(urls) > 0 {
+			return urls
+		}
+	}
+	if urls := cleanURLs(fromPackage); len(urls) > 0 {
+		return urls
+	}
+	if urls := cleanURLs(fromUser); len(urls) > 0 {
+		return urls
+	}
+	return []string{fallback}
+}
+
+// cleanURLs trims whitespace and trailing slashes and drops empty entries
+func cleanURLs(urls []string) []string {
+	cleaned := make([]string, 0, len(urls))
+	for _, u := range urls {
+		u = strings.TrimRight(strings.TrimSpace(u), "/")
+		if u != "" {
+			cleaned = append(cleaned, u)
+		}
+	}
+	return cleaned
+}
@@ -0,0 +1,389 @@
+package jar
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Descriptor files, in lookup order
+var descriptorFiles = []string{"paper-plugin.yml", "plugin.yml", "bungee.yml", "velocity-plugin.json"}
+
+// platformDescriptors are the descriptors a platform loads, in its own order. They
+// are looked up before the others, so a jar shipping several is read the way the
+// server reads it. Paper based servers use the default order
+var platformDescriptors = map[string][]string{
+	"spigot":     {"plugin.yml"},
+	"bukkit":     {"plugin.yml"},
+	"bungeecord": {"bungee.yml", "plugin.yml"},
+	"waterfall":  {"bungee.yml", "plugin.yml"},
+	"velocity":   {"velocity-plugin.json"},
+}
+
+// Descriptor is the plugin metadata declared inside a jar, normalized across
+// the Bukkit, Paper, BungeeCord and Velocity formats
+type Descriptor struct {
+	ID          string                `json:"id,omitempty" yaml:"id,omitempty"` // Velocity only
+	Name        string                `json:"name" yaml:"name"`
+	Version     string                `json:"version" yaml:"version"`
+	Main        string                `json:"main" yaml:"main"`
+	APIVersion  string                `json:"api_version,omitempty" yaml:"api_version,omitempty"`
+	Description string                `json:"description,omitempty" yaml:"description,omitempty"`
+	Authors     []string              `json:"authors,omitempty" yaml:"authors,omitempty"`
+	Depend      []string              `json:"depend,omitempty" yaml:"depend,omitempty"`
+	SoftDepend  []string              `json:"softdepend,omitempty" yaml:"softdepend,omitempty"`
+	LoadBefore  []string              `json:"loadbefore,omitempty" yaml:"loadbefore,omitempty"`
+	Provides    []string              `json:"provides,omitempty" yaml:"provides,omitempty"`
+	Commands    map[string]Command    `json:"commands,omitempty" yaml:"commands,omitempty"`
+	Permissions map[string]Permission `json:"permissions,omitempty" yaml:"permissions,omitempty"`
+
+	// Source is the descriptor file it was read from (plugin.yml, velocity-plugin.json...)
+	Source string `json:"source" yaml:"source"`
+}
+
+// Command is a command registered in plugin.yml or bungee.yml
+type Command struct {
+	Description string     `json:"description,omitempty" yaml:"description,omitempty"`
+	Usage       string     `json:"usage,omitempty" yaml:"usage,omitempty"`
+	Permission  string     `json:"permission,omitempty" yaml:"permission,omitempty"`
+	Aliases     stringList `json:"aliases,omitempty" yaml:"aliases,omitempty"`
+}
+
+// Permission is a permission node declared by the plugin
+type Permission struct {
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Default     scalar `json:"default,omitempty" yaml:"default,omitempty"` // true, false, op or not op
+}
+
+// CommandNames returns the declared commands sorted by name
+func (d *Descriptor) CommandNames() []string {
+	return sortedKeys(d.Commands)
+}
+
+// PermissionNames returns the declared permission nodes sorted by name
+func (d *Descriptor) PermissionNames() []string {
+	return sortedKeys(d.Permissions)
+}
+
+// bukkitDescriptor is the plugin.yml format (Bukkit, Spigot, Paper)
+type bukkitDescriptor struct {
+	Name        string                `yaml:"name"`
+	Version     scalar                `yaml:"version"`
+	Main        string                `yaml:"main"`
+	APIVersion  scalar                `yaml:"api-version"`
+	Description string                `yaml:"description"`
+	Author      string                `yaml:"author"`
+	Authors     stringList            `yaml:"authors"`
+	Depend      stringList            `yaml:"depend"`
+	SoftDepend  stringList            `yaml:"softdepend"`
+	LoadBefore  stringList            `yaml:"loadbefore"`
+	Provides    stringList            `yaml:"provides"`
+	Commands    map[string]Command    `yaml:"commands"`
+	Permissions map[string]Permission `yaml:"permissions"`
+}
+
+// paperDescriptor is the paper-plugin.yml format
+type paperDescriptor struct {
+	Name        string     `yaml:"name"`
+	Version     scalar     `yaml:"version"`
+	Main        string     `yaml:"main"`
+	APIVersion  scalar     `yaml:"api-version"`
+	Description string     `yaml:"description"`
+	Author      string     `yaml:"author"`
+	Authors     stringList `yaml:"authors"`
+	LoadBefore  []struct {
+		Name string `yaml:"name"`
+	} `yaml:"load-before"`
+	Provides     stringList            `yaml:"provides"`
+	Dependencies paperDependencies     `yaml:"dependencies"`
+	Permissions  map[string]Permission `yaml:"permissions"`
+}
+
+// paperDependency is a dependency in paper-plugin.yml
+type paperDependency struct {
+	Name     string `yaml:"name"`
+	Load     string `yaml:"load"` // BEFORE, AFTER or OMIT
+	Required *bool  `yaml:"required"`
+}
+
+// paperDependencies accepts both the current format (dependencies.server.<name>)
+// and the early list format (dependencies: [{name, required}])
+type paperDependencies []paperDependency
+
+func (p *paperDependencies) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.SequenceNode {
+		var list []paperDependency
+		if err := node.Decode(&list); err != nil {
+			return err
+		}
+		*p = list
+		return nil
+	}
+
+	var sections struct {
+		Server map[string]paperDependency `yaml:"server"`
+	}
+	if err := node.Decode(&sections); err != nil {
+		return err
+	}
+	for name, dep := range sections.Server {
+		dep.Name = name
+		*p = append(*p, dep)
+	}
+	sort.Slice(*p, func(i, j int) bool { return (*p)[i].Name < (*p)[j].Name })
+	return nil
+}
+
+// bungeeDescriptor is the bungee.yml format
+type bungeeDescriptor struct {
+	Name        string     `yaml:"name"`
+	Version     scalar     `yaml:"version"`
+	Main        string     `yaml:"main"`
+	Description string     `yaml:"description"`
+	Author      string     `yaml:"author"`
+	Depends     stringList `yaml:"depends"`
+	SoftDepends stringList `yaml:"softDepends"`
+}
+
+// velocityDescriptor is the velocity-plugin.json format
+type velocityDescriptor struct {
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	Version      string   `json:"version"`
+	Main         string   `json:"main"`
+	Description  string   `json:"description"`
+	Authors      []string `json:"authors"`
+	Dependencies []struct {
+		ID       string `json:"id"`
+		Optional bool   `json:"optional"`
+	} `json:"dependencies"`
+}
+
+// Read opens a plugin jar and parses its descriptor
+func Read(path string) (*Descriptor, error) {
+	return ReadFor(path, "")
+}
+
+// ReadFor opens a plugin jar and parses the descriptor serverType loads, falling
+// back to the others when the jar has none for that platform
+func ReadFor(path, serverType string) (*Descriptor, error) {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	files := make(map[string]*zip.File)
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+
+	for _, name := range descriptorOrder(serverType) {
+		f, ok := files[name]
+		if !ok {
+			continue
+		}
+
+		desc, err := parseDescriptor(f)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", name, err)
+		}
+		desc.Source = name
+		return desc, nil
+	}
+
+	return nil, fmt.Errorf("no plugin descriptor found in %s", path)
+}
+
+// descriptorOrder returns the descriptor files to look up for serverType
+func descriptorOrder(serverType string) []string {
+	preferred := platformDescriptors[strings.ToLower(serverType)]
+	order := append([]string{}, preferred...)
+	for _, name := range descriptorFiles {
+		if !containsName(preferred, name) {
+			order = append(order, name)
+		}
+	}
+	return order
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func parseDescriptor(f *zip.File) (*Descriptor, error) {
+	data, err := readZipFile(f)
+	if err != nil {
+		return nil, err
+	}
+
+	switch f.Name {
+	case "velocity-plugin.json":
+		return parseVelocity(data)
+	case "paper-plugin.yml":
+		return parsePaper(data)
+	case "bungee.yml":
+		return parseBungee(data)
+	default:
+		return parseBukkit(data)
+	}
+}
+
+func parseBukkit(data []byte) (*Descriptor, error) {
+	var raw bukkitDescriptor
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	return &Descriptor{
+		Name:        raw.Name,
+		Version:     string(raw.Version),
+		Main:        raw.Main,
+		APIVersion:  string(raw.APIVersion),
+		Description: raw.Description,
+		Authors:     authors(raw.Author, raw.Authors),
+		Depend:      raw.Depend,
+		SoftDepend:  raw.SoftDepend,
+		LoadBefore:  raw.LoadBefore,
+		Provides:    raw.Provides,
+		Commands:    raw.Commands,
+		Permissions: raw.Permissions,
+	}, nil
+}
+
+func parsePaper(data []byte) (*Descriptor, error) {
+	var raw paperDescriptor
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	desc := &Descriptor{
+		Name:        raw.Name,
+		Version:     string(raw.Version),
+		Main:        raw.Main,
+		APIVersion:  string(raw.APIVersion),
+		Description: raw.Description,
+		Authors:     authors(raw.Author, raw.Authors),
+		Provides:    raw.Provides,
+		Permissions: raw.Permissions,
+	}
+	for _, before := range raw.LoadBefore {
+		desc.LoadBefore = append(desc.LoadBefore, before.Name)
+	}
+	for _, dep := range raw.Dependencies {
+		// Paper dependencies are required unless stated otherwise
+		if dep.Required == nil || *dep.Required {
+			desc.Depend = append(desc.Depend, dep.Name)
+		} else {
+			desc.SoftDepend = append(desc.SoftDepend, dep.Name)
+		}
+		if dep.Load == "AFTER" {
+			desc.LoadBefore = append(desc.LoadBefore, dep.Name)
+		}
+	}
+
+	return desc, nil
+}
+
+func parseBungee(data []byte) (*Descriptor, error) {
+	var raw bungeeDescriptor
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	return &Descriptor{
+		Name:        raw.Name,
+		Version:     string(raw.Version),
+		Main:        raw.Main,
+		Description: raw.Description,
+		Authors:     authors(raw.Author, nil),
+		Depend:      raw.Depends,
+		SoftDepend:  raw.SoftDepends,
+	}, nil
+}
+
+func parseVelocity(data []byte) (*Descriptor, error) {
+	var raw velocityDescriptor
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	desc := &Descriptor{
+		ID:          raw.ID,
+		Name:        raw.Name,
+		Version:     raw.Version,
+		Main:        raw.Main,
+		Description: raw.Description,
+		Authors:     raw.Authors,
+	}
+	// The name is optional in Velocity, the ID is not
+	if desc.Name == "" {
+		desc.Name = raw.ID
+	}
+	for _, dep := range raw.Dependencies {
+		if dep.Optional {
+			desc.SoftDepend = append(desc.SoftDepend, dep.ID)
+		} else {
+			desc.Depend = append(desc.Depend, dep.ID)
+		}
+	}
+
+	return desc, nil
+}
+
+// authors merges the single author and authors list fields
+func authors(author string, list []string) []string {
+	var result []string
+	if author != "" {
+		result = append(result, author)
+	}
+	return append(result, list...)
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// scalar keeps a YAML scalar as written, so api-version 1.20 doesn't become 1.2
+type scalar string
+
+func (s *scalar) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.ScalarNode {
+		return fmt.Errorf("line %d: expected a scalar value", node.Line)
+	}
+	*s = scalar(node.Value)
+	return nil
+}
+
+// stringList accepts both a single value and a list
+type stringList []string
+
+func (l *stringList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		if node.Value != "" {
+			*l = stringList{node.Value}
+		}
+		return nil
+	}
+
+	var list []string
+	if err := node.Decode(&list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
@@ -0,0 +1,250 @@
+package jar
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeJar creates a jar in a temporary directory holding files
+func writeJar(t *testing.T, files map[string]string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "plugin.jar")
+	out, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+
+	w := zip.NewWriter(out)
+	for name, content := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  Descriptor
+		err   string
+	}{
+		{
+			name: "plugin.yml",
+			files: map[string]string{"plugin.yml": `
+name: Essentials
+version: 2.20
+main: com.earth2me.essentials.Essentials
+api-version: 1.20
+author: zenexer
+authors: [ementalo, snowleo]
+depend: Vault
+softdepend: [LuckPerms, WorldGuard]
+`},
+			want: Descriptor{
+				Name:       "Essentials",
+				Version:    "2.20",
+				Main:       "com.earth2me.essentials.Essentials",
+				APIVersion: "1.20",
+				Authors:    []string{"zenexer", "ementalo", "snowleo"},
+				Depend:     []string{"Vault"},
+				SoftDepend: []string{"LuckPerms", "WorldGuard"},
+				Source:     "plugin.yml",
+			},
+		},
+		{
+			name: "paper-plugin.yml wins over plugin.yml",
+			files: map[string]string{
+				"plugin.yml": "name: Legacy\nversion: 1\nmain: a.B\n",
+				"paper-plugin.yml": `
+name: Modern
+version: 2.0.0
+main: a.C
+api-version: '1.21'
+dependencies:
+  server:
+    Vault:
+      required: false
+    LuckPerms:
+      load: BEFORE
+`,
+			},
+			want: Descriptor{
+				Name:       "Modern",
+				Version:    "2.0.0",
+				Main:       "a.C",
+				APIVersion: "1.21",
+				Depend:     []string{"LuckPerms"},
+				SoftDepend: []string{"Vault"},
+				Source:     "paper-plugin.yml",
+			},
+		},
+		{
+			name: "paper-plugin.yml list dependencies",
+			files: map[string]string{"paper-plugin.yml": `
+name: Early
+version: 1.0
+main: a.D
+dependencies:
+  - name: Vault
+    required: true
+`},
+			want: Descriptor{
+				Name:    "Early",
+				Version: "1.0",
+				Main:    "a.D",
+				Depend:  []string{"Vault"},
+				Source:  "paper-plugin.yml",
+			},
+		},
+		{
+			name: "bungee.yml",
+			files: map[string]string{"bungee.yml": `
+name: BungeeTabList
+version: 3.5
+main: a.E
+author: CodeCrafter47
+depends: [LuckPerms]
+softDepends: ViaVersion
+`},
+			want: Descriptor{
+				Name:       "BungeeTabList",
+				Version:    "3.5",
+				Main:       "a.E",
+				Authors:    []string{"CodeCrafter47"},
+				Depend:     []string{"LuckPerms"},
+				SoftDepend: []string{"ViaVersion"},
+				Source:     "bungee.yml",
+			},
+		},
+		{
+			name: "velocity-plugin.json without a name",
+			files: map[string]string{"velocity-plugin.json": `{
+  "id": "luckperms",
+  "version": "5.4.102",
+  "main": "me.lucko.luckperms.velocity.LPVelocityBootstrap",
+  "authors": ["Luck"],
+  "dependencies": [{"id": "signedvelocity", "optional": true}, {"id": "protocolize", "optional": false}]
+}`},
+			want: Descriptor{
+				ID:         "luckperms",
+				Name:       "luckperms",
+				Version:    "5.4.102",
+				Main:       "me.lucko.luckperms.velocity.LPVelocityBootstrap",
+				Authors:    []string{"Luck"},
+				Depend:     []string{"protocolize"},
+				SoftDepend: []string{"signedvelocity"},
+				Source:     "velocity-plugin.json",
+			},
+		},
+		{
+			name:  "no descriptor",
+			files: map[string]string{"META-INF/MANIFEST.MF": "Manifest-Version: 1.0\n"},
+			err:   "no plugin descriptor found",
+		},
+		{
+			name:  "broken descriptor",
+			files: map[string]string{"plugin.yml": "name: [unclosed\n"},
+			err:   "invalid plugin.yml",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			desc, err := Read(writeJar(t, tt.files))
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("err = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(*desc, tt.want) {
+				t.Errorf("got  %+v\nwant %+v", *desc, tt.want)
+			}
+		})
+	}
+}
+
+func TestDescriptorNames(t *testing.T) {
+	path := writeJar(t, map[string]string{"plugin.yml": `
+name: Homes
+version: 1.0
+main: a.Homes
+commands:
+  sethome: {description: Set a home, aliases: sh}
+  home: {aliases: [h, homes]}
+permissions:
+  homes.use: {default: true}
+  homes.admin: {default: op}
+`})
+	desc, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := desc.CommandNames(), []string{"home", "sethome"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("CommandNames() = %v, want %v", got, want)
+	}
+	if got, want := desc.PermissionNames(), []string{"homes.admin", "homes.use"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("PermissionNames() = %v, want %v", got, want)
+	}
+	if got, want := []string(desc.Commands["home"].Aliases), []string{"h", "homes"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("home aliases = %v, want %v", got, want)
+	}
+	if got := desc.Permissions["homes.admin"].Default; got != "op" {
+		t.Errorf("homes.admin default = %q, want op", got)
+	}
+}
+
+func TestReadFor(t *testing.T) {
+	paper := "name: Modern\nversion: 2.0\nmain: a.ModernPaper\napi-version: '1.20'\n"
+	bukkit := "name: Modern\nversion: 2.0\nmain: a.ModernBukkit\napi-version: '1.13'\n"
+	bungee := "name: Modern\nversion: 2.0\nmain: a.ModernBungee\n"
+	velocity := `{"id": "modern", "name": "Modern", "version": "2.0", "main": "a.ModernVelocity"}`
+
+	tests := []struct {
+		name       string
+		files      map[string]string
+		serverType string
+		want       string // descriptor read
+	}{
+		{"paper reads paper-plugin.yml", map[string]string{"paper-plugin.yml": paper, "plugin.yml": bukkit}, "paper", "paper-plugin.yml"},
+		{"paper forks read paper-plugin.yml", map[string]string{"paper-plugin.yml": paper, "plugin.yml": bukkit}, "purpur", "paper-plugin.yml"},
+		{"spigot reads plugin.yml", map[string]string{"paper-plugin.yml": paper, "plugin.yml": bukkit}, "spigot", "plugin.yml"},
+		{"bukkit reads plugin.yml", map[string]string{"paper-plugin.yml": paper, "plugin.yml": bukkit}, "Bukkit", "plugin.yml"},
+		{"spigot without plugin.yml", map[string]string{"paper-plugin.yml": paper}, "spigot", "paper-plugin.yml"},
+		{"velocity reads velocity-plugin.json", map[string]string{"plugin.yml": bukkit, "bungee.yml": bungee, "velocity-plugin.json": velocity}, "velocity", "velocity-plugin.json"},
+		{"bungeecord reads bungee.yml", map[string]string{"plugin.yml": bukkit, "bungee.yml": bungee, "velocity-plugin.json": velocity}, "bungeecord", "bungee.yml"},
+		{"waterfall falls back to plugin.yml", map[string]string{"plugin.yml": bukkit, "velocity-plugin.json": velocity}, "waterfall", "plugin.yml"},
+		{"paper reads plugin.yml of a universal jar", map[string]string{"plugin.yml": bukkit, "bungee.yml": bungee, "velocity-plugin.json": velocity}, "paper", "plugin.yml"},
+		{"unknown server uses the default order", map[string]string{"paper-plugin.yml": paper, "plugin.yml": bukkit}, "", "paper-plugin.yml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			desc, err := ReadFor(writeJar(t, tt.files), tt.serverType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if desc.Source != tt.want {
+				t.Errorf("ReadFor(%q) read %s, want %s", tt.serverType, desc.Source, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package jar
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// ServerInfo is what can be told about a server jar from its contents
+type ServerInfo struct {
+	Type             string // paper, purpur, folia, spigot, velocity, waterfall, bungeecord, vanilla
+	MinecraftVersion string // empty for proxies and unknown jars
+}
+
+// Paperclip launchers list the bundled server jar in META-INF/versions.list,
+// e.g. "<sha256>\tpaper-1.20.4\t1.20.4/paper-1.20.4.jar"
+var bundledJarPattern = regexp.MustCompile(`^([a-z]+)-(\d+\.\d+(?:\.\d+)?)`)
+
+// brandTypes maps the Brand-Id manifest attribute of Paper forks to server types
+var brandTypes = map[string]string{
+	"papermc:paper":   "paper",
+	"papermc:folia":   "folia",
+	"purpurmc:purpur": "purpur",
+}
+
+// DetectServer inspects a server jar and guesses its type and Minecraft version
+func DetectServer(jarPath string) (*ServerInfo, error) {
+	archive, err := zip.OpenReader(jarPath)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	info := &ServerInfo{}
+	manifest := map[string]string{}
+
+	for _, f := range archive.File {
+		switch f.Name {
+		case "META-INF/MANIFEST.MF":
+			manifest, err = readManifest(f)
+			if err != nil {
+				return nil, fmt.Errorf("invalid manifest: %w", err)
+			}
+		case "version.json":
+			// Present in vanilla, Spigot and Paper jars
+			var version struct {
+				ID string `json:"id"`
+			}
+			if data, err := readZipFile(f); err == nil && json.Unmarshal(data, &version) == nil {
+				info.MinecraftVersion = version.ID
+			}
+		case "install.properties":
+			// Fabric's server launcher records the game version it installs
+			if data, err := readZipFile(f); err == nil {
+				for _, line := range strings.Split(string(data), "\n") {
+					if key, value, ok := strings.Cut(strings.TrimSpace(line), "="); ok && key == "game-version" {
+						info.MinecraftVersion = value
+					}
+				}
+			}
+		case "META-INF/versions.list":
+			if data, err := readZipFile(f); err == nil {
+				for _, line := range strings.Split(string(data), "\n") {
+					fields := strings.Fields(line)
+					if len(fields) < 3 {
+						continue
+					}
+					if m := bundledJarPattern.FindStringSubmatch(path.Base(fields[2])); m != nil {
+						if info.Type == "" && m[1] != "server" {
+							info.Type = m[1]
+						}
+						if info.MinecraftVersion == "" {
+							info.MinecraftVersion = m[2]
+						}
+					}
+				}
+			}
+		}
+	}
+
+	if brand, ok := brandTypes[strings.ToLower(manifest["Brand-Id"])]; ok {
+		info.Type = brand
+	}
+
+	if info.Type == "" {
+		title := strings.ToLower(manifest["Implementation-Title"])
+		mainClass := manifest["Main-Class"]
+		switch {
+		case strings.Contains(title, "velocity"):
+			info.Type = "velocity"
+		case strings.Contains(title, "waterfall"):
+			info.Type = "waterfall"
+		case strings.Contains(title, "bungeecord"):
+			info.Type = "bungeecord"
+		case mainClass == "net.fabricmc.installer.ServerLauncher":
+			info.Type = "fabric"
+		case strings.HasPrefix(mainClass, "org.quiltmc."):
+			info.Type = "quilt"
+		case mainClass == "org.bukkit.craftbukkit.Main" || strings.HasPrefix(mainClass, "org.bukkit.craftbukkit.bootstrap"):
+			info.Type = "spigot"
+		case mainClass == "net.minecraft.server.Main" || mainClass == "net.minecraft.bundler.Main":
+			info.Type = "vanilla"
+		}
+	}
+
+	if info.Type == "" && info.MinecraftVersion == "" {
+		return nil, fmt.Errorf("could not recognize %s as a server jar", jarPath)
+	}
+
+	return info, nil
+}
+
+// readManifest parses a jar manifest, joining continuation lines
+func readManifest(f *zip.File) (map[string]string, error) {
+	data, err := readZipFile(f)
+	if err != nil {
+		return nil, err
+	}
+
+	attrs := make(map[string]string)
+	var lastKey string
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.HasPrefix(line, " ") && lastKey != "" {
+			attrs[lastKey] += line[1:]
+			continue
+		}
+		if line == "" {
+			break // Only the main section is needed
+		}
+		if key, value, ok := strings.Cut(line, ":"); ok {
+			lastKey = strings.TrimSpace(key)
+			attrs[lastKey] = strings.TrimSpace(value)
+		}
+	}
+
+	return attrs, scanner.Err()
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	reader, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return io.ReadAll(reader)
+}
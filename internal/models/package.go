@@ -2,6 +2,7 @@ package models
 
 import (
 	"os"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
@@ -13,33 +14,117 @@ type Package struct {
 	Plugins         []Plugin          `yaml:"plugins"`
 	Scripts         map[string]string `yaml:"scripts,omitempty"`
 	StartupCommands []string          `yaml:"startup_commands,omitempty"`
+	Properties      map[string]string `yaml:"properties,omitempty"` // merged into server.properties before mpm serve
+	Endpoints       Endpoints         `yaml:"endpoints,omitempty"`
 }
 
 type ServerConfig struct {
-	Type             string `yaml:"type"`                        // paper, purpur, folia, spigot, bukkit, sponge, velocity, waterfall
-	MinecraftVersion string `yaml:"minecraft_version"`           // 1.20.1, etc.
-	Build            string `yaml:"build,omitempty"`             // latest or specific build number
-	StartCommand     string `yaml:"start_command,omitempty"`     // custom server start command
+	Type             string            `yaml:"type"`                    // vanilla, paper, purpur, folia, pufferfish, leaf, leaves, canvas, spigot, bukkit, sponge, velocity, waterfall, bungeecord, fabric, quilt, forge, neoforge
+	MinecraftVersion string            `yaml:"minecraft_version"`       // 1.20.1, etc. (vanilla also accepts latest, release, snapshot)
+	Build            string            `yaml:"build,omitempty"`         // latest or specific build number (loader version for mod loaders)
+	Jar              string            `yaml:"jar,omitempty"`           // file the server is started from (default: the one mpm installs)
+	Java             string            `yaml:"java,omitempty"`          // java executable (default: the installed Java matching minecraft_version)
+	MinMemory        string            `yaml:"min_memory,omitempty"`    // -Xms, e.g. 2G
+	MaxMemory        string            `yaml:"max_memory,omitempty"`    // -Xmx, e.g. 4G
+	JVMFlags         string            `yaml:"jvm_flags,omitempty"`     // flag preset: aikar, velocity or none
+	JVMArgs          []string          `yaml:"jvm_args,omitempty"`      // extra JVM arguments
+	ServerArgs       []string          `yaml:"server_args,omitempty"`   // arguments after the jar (default: nogui)
+	Env              map[string]string `yaml:"env,omitempty"`           // extra environment variables for the server
+	StartCommand     string            `yaml:"start_command,omitempty"` // raw shell command; overrides jar to server_args
+	Eula             bool              `yaml:"eula,omitempty"`          // accept the Minecraft EULA (https://aka.ms/MinecraftEULA)
+}
+
+// HasJVMSettings reports whether any of the settings start_command overrides are set
+func (s ServerConfig) HasJVMSettings() bool {
+	return s.Jar != "" || s.Java != "" || s.MinMemory != "" || s.MaxMemory != "" || s.JVMFlags != "" ||
+		len(s.JVMArgs) > 0 || s.ServerArgs != nil
+}
+
+// modLoaders are the server types that load mods from mods/ instead of plugins from plugins/
+var modLoaders = []string{"fabric", "quilt", "forge", "neoforge"}
+
+// IsModLoader reports whether a server type runs mods rather than plugins
+func IsModLoader(serverType string) bool {
+	for _, loader := range modLoaders {
+		if strings.EqualFold(serverType, loader) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsModLoader reports whether the server runs mods rather than plugins
+func (s ServerConfig) IsModLoader() bool {
+	return IsModLoader(s.Type)
+}
+
+// proxies are the server types that proxy other servers instead of running a world;
+// their minecraft_version is the proxy's own version
+var proxies = []string{"velocity", "waterfall", "bungeecord"}
+
+// IsProxy reports whether a server type is a proxy
+func IsProxy(serverType string) bool {
+	for _, proxy := range proxies {
+		if strings.EqualFold(serverType, proxy) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsProxy reports whether the server is a proxy
+func (s ServerConfig) IsProxy() bool {
+	return IsProxy(s.Type)
+}
+
+// ContentDir returns the directory the server loads plugins or mods from
+func (s ServerConfig) ContentDir() string {
+	if s.IsModLoader() {
+		return "mods"
+	}
+	return "plugins"
+}
+
+// Endpoints overrides the API base URLs used by mpm.
+// Each entry is a list of mirrors tried in order until one responds.
+type Endpoints struct {
+	Modrinth   []string `yaml:"modrinth,omitempty"`
+	Hangar     []string `yaml:"hangar,omitempty"`
+	PaperMC    []string `yaml:"papermc,omitempty"`
+	Purpur     []string `yaml:"purpur,omitempty"`
+	BuildTools []string `yaml:"buildtools,omitempty"`
+	Sponge     []string `yaml:"sponge,omitempty"`
+	Fabric     []string `yaml:"fabric,omitempty"`
+	Quilt      []string `yaml:"quilt,omitempty"`
+	Forge      []string `yaml:"forge,omitempty"`
+	NeoForge   []string `yaml:"neoforge,omitempty"`
+	Mojang     []string `yaml:"mojang,omitempty"`
+	Pufferfish []string `yaml:"pufferfish,omitempty"`
+	GitHub     []string `yaml:"github,omitempty"`
+	BungeeCord []string `yaml:"bungeecord,omitempty"`
+	Adoptium   []string `yaml:"adoptium,omitempty"`
 }
 
 type Plugin struct {
 	Name         string   `yaml:"name"`
-	Version      string   `yaml:"version"`     // Version específica requerida
+	Version      string   `yaml:"version"`               // Version específica requerida
 	ModrinthID   string   `yaml:"modrinth_id,omitempty"` // ID o Slug de Modrinth
 	HangarID     string   `yaml:"hangar_id,omitempty"`   // owner/slug for Hangar (e.g., "PaperMC/Geyser")
+	File         string   `yaml:"file,omitempty"`        // local jar in plugins/, not downloaded
 	Optional     bool     `yaml:"optional,omitempty"`
 	Dependencies []string `yaml:"dependencies,omitempty"`
 }
 
 // PackageLock stores checksums and resolved versions
 type PackageLock struct {
-	Plugins map[string]PluginLock `yaml:"plugins"` // Key is ModrinthID or HangarID
+	Plugins map[string]PluginLock `yaml:"plugins"` // Key is ModrinthID, HangarID or local File
 }
 
 type PluginLock struct {
 	Name    string `yaml:"name"`
 	Version string `yaml:"version"`
-	Hash    string `yaml:"hash"` // SHA512 hash
+	Hash    string `yaml:"hash"`           // SHA512 (Modrinth) or SHA256 (Hangar) hash
+	File    string `yaml:"file,omitempty"` // jar filename inside the plugins directory
 }
 
 // LoadPackageFromFile carga un package.yml desde archivo
@@ -0,0 +1,174 @@
+package registry
+
+import (
+	"crypto/sha1"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MetadataFile is the name of the per-project metadata file inside a registry directory
+const MetadataFile = "project.yml"
+
+// Registry is an in-memory catalog of approved plugins loaded from disk.
+//
+// Layout:
+//
+//	<root>/<slug>/project.yml
+//	<root>/<slug>/<jar files referenced by project.yml>
+type Registry struct {
+	Root     string
+	Projects []*Project
+}
+
+// Project describes a plugin in the registry (project.yml)
+type Project struct {
+	Slug        string    `yaml:"slug"`
+	Title       string    `yaml:"title"`
+	Description string    `yaml:"description"`
+	Author      string    `yaml:"author"`
+	Categories  []string  `yaml:"categories"` // paper, spigot, velocity...
+	Versions    []Version `yaml:"versions"`   // newest first
+
+	dir string
+}
+
+// Version is a single release of a project
+type Version struct {
+	ID            string   `yaml:"id,omitempty"`
+	Name          string   `yaml:"name,omitempty"`
+	VersionNumber string   `yaml:"version_number"`
+	GameVersions  []string `yaml:"game_versions"`
+	Loaders       []string `yaml:"loaders"`
+	File          string   `yaml:"file"` // jar filename relative to the project directory
+
+	sha1   string
+	sha512 string
+	size   int64
+}
+
+// Load reads every project in root and hashes the referenced jars
+func Load(root string) (*Registry, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+
+	reg := &Registry{Root: root}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		dir := filepath.Join(root, entry.Name())
+		project, err := loadProject(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue // Not a project directory
+			}
+			return nil, fmt.Errorf("error loading %s: %w", dir, err)
+		}
+
+		reg.Projects = append(reg.Projects, project)
+	}
+
+	sort.Slice(reg.Projects, func(i, j int) bool {
+		return reg.Projects[i].Slug < reg.Projects[j].Slug
+	})
+
+	return reg, nil
+}
+
+func loadProject(dir string) (*Project, error) {
+	data, err := os.ReadFile(filepath.Join(dir, MetadataFile))
+	if err != nil {
+		return nil, err
+	}
+
+	var project Project
+	if err := yaml.Unmarshal(data, &project); err != nil {
+		return nil, err
+	}
+
+	project.dir = dir
+	if project.Slug == "" {
+		project.Slug = filepath.Base(dir)
+	}
+	if project.Title == "" {
+		project.Title = project.Slug
+	}
+
+	for i := range project.Versions {
+		v := &project.Versions[i]
+		if v.File == "" {
+			return nil, fmt.Errorf("version %s has no file", v.VersionNumber)
+		}
+		if v.ID == "" {
+			v.ID = fmt.Sprintf("%s-%s", project.Slug, v.VersionNumber)
+		}
+		if v.Name == "" {
+			v.Name = v.VersionNumber
+		}
+		if err := v.hash(filepath.Join(dir, v.File)); err != nil {
+			return nil, fmt.Errorf("error hashing %s: %w", v.File, err)
+		}
+	}
+
+	return &project, nil
+}
+
+func (v *Version) hash(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	sha1Hasher := sha1.New()
+	sha512Hasher := sha512.New()
+	size, err := io.Copy(io.MultiWriter(sha1Hasher, sha512Hasher), file)
+	if err != nil {
+		return err
+	}
+
+	v.sha1 = hex.EncodeToString(sha1Hasher.Sum(nil))
+	v.sha512 = hex.EncodeToString(sha512Hasher.Sum(nil))
+	v.size = size
+	return nil
+}
+
+// FindProject looks up a project by slug (case-insensitive)
+func (r *Registry) FindProject(slug string) *Project {
+	for _, p := range r.Projects {
+		if strings.EqualFold(p.Slug, slug) {
+			return p
+		}
+	}
+	return nil
+}
+
+// FindVersionByHash looks up the version that contains a file with the given
+// sha1 or sha512 hash
+func (r *Registry) FindVersionByHash(hash, algorithm string) (*Project, *Version) {
+	for _, p := range r.Projects {
+		for i := range p.Versions {
+			v := &p.Versions[i]
+			if (algorithm == "sha1" && strings.EqualFold(v.sha1, hash)) || (algorithm == "sha512" && strings.EqualFold(v.sha512, hash)) {
+				return p, v
+			}
+		}
+	}
+	return nil, nil
+}
+
+// FilePath returns the on-disk path of a version's jar
+func (p *Project) FilePath(v *Version) string {
+	return filepath.Join(p.dir, v.File)
+}
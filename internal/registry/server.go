@@ -0,0 +1,374 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/storrealbac/mpm/internal/sources"
+)
+
+// Server exposes a registry through a Modrinth v2 compatible subset:
+//
+//	GET /v2/search
+//	GET /v2/project/{id}
+//	GET /v2/project/{id}/version
+//	GET /v2/projects
+//	GET /v2/version_file/{hash}
+//	POST /v2/version_files
+//	GET /files/{slug}/{file}
+type Server struct {
+	Registry *Registry
+	// PublicURL is the externally reachable base URL used in download links.
+	// When empty, it is derived from the incoming request.
+	PublicURL string
+}
+
+// Handler returns the HTTP handler for the registry API
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v2/search", s.handleSearch)
+	mux.HandleFunc("GET /v2/project/{id}", s.handleProject)
+	mux.HandleFunc("GET /v2/project/{id}/version", s.handleVersions)
+	mux.HandleFunc("GET /v2/projects", s.handleProjects)
+	mux.HandleFunc("GET /v2/version_file/{hash}", s.handleVersionFile)
+	mux.HandleFunc("POST /v2/version_files", s.handleVersionFiles)
+	mux.HandleFunc("GET /files/{slug}/{file}", s.handleFile)
+	return mux
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	query := strings.ToLower(r.URL.Query().Get("query"))
+
+	var facets [][]string
+	if raw := r.URL.Query().Get("facets"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &facets); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid facets")
+			return
+		}
+	}
+
+	offset := 0
+	if raw := r.URL.Query().Get("offset"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid offset")
+			return
+		}
+		offset = max(n, 0)
+	}
+	limit := 10
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		limit = min(n, 100)
+	}
+
+	hits := []sources.ModrinthProject{}
+	for _, p := range s.Registry.Projects {
+		if query != "" && !strings.Contains(strings.ToLower(p.Slug), query) &&
+			!strings.Contains(strings.ToLower(p.Title), query) &&
+			!strings.Contains(strings.ToLower(p.Description), query) {
+			continue
+		}
+		if !matchesFacets(p, facets) {
+			continue
+		}
+		hits = append(hits, toModrinthProject(p))
+	}
+
+	total := len(hits)
+	if offset > total {
+		offset = total
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+
+	writeJSON(w, sources.ModrinthSearchResponse{
+		Hits:      hits[offset:end],
+		Offset:    offset,
+		Limit:     limit,
+		TotalHits: total,
+	})
+}
+
+func (s *Server) handleProject(w http.ResponseWriter, r *http.Request) {
+	project := s.Registry.FindProject(r.PathValue("id"))
+	if project == nil {
+		writeError(w, http.StatusNotFound, "project not found")
+		return
+	}
+
+	writeJSON(w, toModrinthProject(project))
+}
+
+func (s *Server) handleVersions(w http.ResponseWriter, r *http.Request) {
+	project := s.Registry.FindProject(r.PathValue("id"))
+	if project == nil {
+		writeError(w, http.StatusNotFound, "project not found")
+		return
+	}
+
+	gameVersions, err := parseListParam(r.URL.Query().Get("game_versions"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid game_versions")
+		return
+	}
+	loaders, err := parseListParam(r.URL.Query().Get("loaders"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid loaders")
+		return
+	}
+
+	baseURL := s.baseURL(r)
+	versions := []sources.ModrinthVersion{}
+	for i := range project.Versions {
+		v := &project.Versions[i]
+		if len(gameVersions) > 0 && !containsAny(v.GameVersions, gameVersions) {
+			continue
+		}
+		if len(loaders) > 0 && !containsAny(v.Loaders, loaders) {
+			continue
+		}
+		versions = append(versions, toModrinthVersion(project, v, baseURL))
+	}
+
+	writeJSON(w, versions)
+}
+
+func (s *Server) handleProjects(w http.ResponseWriter, r *http.Request) {
+	ids, err := parseListParam(r.URL.Query().Get("ids"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid ids")
+		return
+	}
+
+	projects := []sources.ModrinthProject{}
+	for _, id := range ids {
+		if project := s.Registry.FindProject(id); project != nil {
+			projects = append(projects, toModrinthProject(project))
+		}
+	}
+
+	writeJSON(w, projects)
+}
+
+func (s *Server) handleVersionFile(w http.ResponseWriter, r *http.Request) {
+	algorithm := r.URL.Query().Get("algorithm")
+	if algorithm == "" {
+		algorithm = "sha1"
+	}
+
+	project, version := s.Registry.FindVersionByHash(r.PathValue("hash"), algorithm)
+	if version == nil {
+		writeError(w, http.StatusNotFound, "file not found")
+		return
+	}
+
+	writeJSON(w, toModrinthVersion(project, version, s.baseURL(r)))
+}
+
+func (s *Server) handleVersionFiles(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Hashes    []string `json:"hashes"`
+		Algorithm string   `json:"algorithm"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Algorithm == "" {
+		req.Algorithm = "sha1"
+	}
+
+	baseURL := s.baseURL(r)
+	versions := make(map[string]sources.ModrinthVersion)
+	for _, hash := range req.Hashes {
+		if project, version := s.Registry.FindVersionByHash(hash, req.Algorithm); version != nil {
+			versions[hash] = toModrinthVersion(project, version, baseURL)
+		}
+	}
+
+	writeJSON(w, versions)
+}
+
+func (s *Server) handleFile(w http.ResponseWriter, r *http.Request) {
+	project := s.Registry.FindProject(r.PathValue("slug"))
+	if project == nil {
+		writeError(w, http.StatusNotFound, "project not found")
+		return
+	}
+
+	// Only serve files declared in project.yml
+	fileName := r.PathValue("file")
+	for i := range project.Versions {
+		v := &project.Versions[i]
+		if v.File == fileName {
+			w.Header().Set("Content-Type", "application/java-archive")
+			http.ServeFile(w, r, project.FilePath(v))
+			return
+		}
+	}
+
+	writeError(w, http.StatusNotFound, "file not found")
+}
+
+func (s *Server) baseURL(r *http.Request) string {
+	if s.PublicURL != "" {
+		return strings.TrimRight(s.PublicURL, "/")
+	}
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s", scheme, r.Host)
+}
+
+// matchesFacets applies Modrinth facet semantics: outer list is AND, inner lists are OR
+func matchesFacets(p *Project, facets [][]string) bool {
+	for _, group := range facets {
+		matched := false
+		for _, facet := range group {
+			// Numeric facets (downloads>=N, ...) use other operators; the
+			// registry has no counts to compare them with
+			key, value, ok := strings.Cut(facet, ":")
+			if !ok {
+				matched = true
+				break
+			}
+			switch key {
+			case "categories":
+				matched = containsFold(p.Categories, value)
+			case "versions":
+				matched = containsFold(projectGameVersions(p), value)
+			case "project_id":
+				matched = strings.EqualFold(p.Slug, value)
+			default:
+				matched = true // Unknown facets don't filter
+			}
+			if matched {
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+func toModrinthProject(p *Project) sources.ModrinthProject {
+	versionIDs := make([]string, 0, len(p.Versions))
+	for _, v := range p.Versions {
+		versionIDs = append(versionIDs, v.ID)
+	}
+
+	var loaders []string
+	for _, v := range p.Versions {
+		for _, loader := range v.Loaders {
+			if !containsFold(loaders, loader) {
+				loaders = append(loaders, loader)
+			}
+		}
+	}
+
+	var gameVersions []string
+	for _, gv := range projectGameVersions(p) {
+		if !containsFold(gameVersions, gv) {
+			gameVersions = append(gameVersions, gv)
+		}
+	}
+
+	return sources.ModrinthProject{
+		Slug:         p.Slug,
+		Title:        p.Title,
+		Description:  p.Description,
+		Categories:   p.Categories,
+		ClientSide:   "unsupported",
+		ServerSide:   "required",
+		ProjectID:    p.Slug,
+		Author:       p.Author,
+		Versions:     versionIDs,
+		ID:           p.Slug,
+		Loaders:      loaders,
+		GameVersions: gameVersions,
+	}
+}
+
+func toModrinthVersion(p *Project, v *Version, baseURL string) sources.ModrinthVersion {
+	return sources.ModrinthVersion{
+		ID:            v.ID,
+		ProjectID:     p.Slug,
+		AuthorID:      p.Author,
+		Name:          v.Name,
+		VersionNumber: v.VersionNumber,
+		GameVersions:  v.GameVersions,
+		Loaders:       v.Loaders,
+		Files: []sources.ModrinthFile{{
+			Hashes:   map[string]string{"sha1": v.sha1, "sha512": v.sha512},
+			URL:      fmt.Sprintf("%s/files/%s/%s", baseURL, url.PathEscape(p.Slug), url.PathEscape(v.File)),
+			Filename: v.File,
+			Primary:  true,
+			Size:     int(v.size),
+		}},
+	}
+}
+
+func projectGameVersions(p *Project) []string {
+	var all []string
+	for _, v := range p.Versions {
+		all = append(all, v.GameVersions...)
+	}
+	return all
+}
+
+// parseListParam parses Modrinth's JSON array query parameters (e.g. ["1.20.4"])
+func parseListParam(raw string) ([]string, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	var values []string
+	err := json.Unmarshal([]byte(raw), &values)
+	return values, err
+}
+
+func containsFold(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(list, values []string) bool {
+	for _, value := range values {
+		if containsFold(list, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, description string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{
+		"error":       http.StatusText(status),
+		"description": description,
+	})
+}
@@ -0,0 +1,83 @@
+package registry
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/storrealbac/mpm/internal/sources"
+)
+
+func testServer() *Server {
+	return &Server{Registry: &Registry{Projects: []*Project{
+		{Slug: "luckperms", Title: "LuckPerms", Description: "permissions", Categories: []string{"paper", "velocity"},
+			Versions: []Version{{VersionNumber: "5.4", GameVersions: []string{"1.20.4", "1.21"}}}},
+		{Slug: "essentialsx", Title: "EssentialsX", Description: "essentials", Categories: []string{"paper"},
+			Versions: []Version{{VersionNumber: "2.20", GameVersions: []string{"1.20.4"}}}},
+		{Slug: "lithium", Title: "Lithium", Description: "optimization", Categories: []string{"fabric"},
+			Versions: []Version{{VersionNumber: "0.12", GameVersions: []string{"1.21"}}}},
+	}}}
+}
+
+func TestHandleSearch(t *testing.T) {
+	tests := []struct {
+		name   string
+		params url.Values
+		status int
+		slugs  []string
+		total  int
+	}{
+		{"all", url.Values{}, http.StatusOK, []string{"luckperms", "essentialsx", "lithium"}, 3},
+		{"query", url.Values{"query": {"PERM"}}, http.StatusOK, []string{"luckperms"}, 1},
+		{"category facet", url.Values{"facets": {`[["categories:paper"]]`}}, http.StatusOK, []string{"luckperms", "essentialsx"}, 2},
+		{"facets are and-ed", url.Values{"facets": {`[["categories:paper"],["versions:1.21"]]`}}, http.StatusOK, []string{"luckperms"}, 1},
+		{"facet groups are or-ed", url.Values{"facets": {`[["categories:velocity","categories:fabric"]]`}}, http.StatusOK, []string{"luckperms", "lithium"}, 2},
+		{"numeric facets don't filter", url.Values{"facets": {`[["downloads>=1000"]]`}}, http.StatusOK, []string{"luckperms", "essentialsx", "lithium"}, 3},
+		{"offset and limit", url.Values{"offset": {"1"}, "limit": {"1"}}, http.StatusOK, []string{"essentialsx"}, 3},
+		{"negative offset", url.Values{"offset": {"-5"}, "limit": {"1"}}, http.StatusOK, []string{"luckperms"}, 3},
+		{"offset past the end", url.Values{"offset": {"10"}}, http.StatusOK, []string{}, 3},
+		{"limit is capped", url.Values{"limit": {"1000"}}, http.StatusOK, []string{"luckperms", "essentialsx", "lithium"}, 3},
+		{"invalid offset", url.Values{"offset": {"x"}}, http.StatusBadRequest, nil, 0},
+		{"zero limit", url.Values{"limit": {"0"}}, http.StatusBadRequest, nil, 0},
+		{"negative limit", url.Values{"limit": {"-1"}}, http.StatusBadRequest, nil, 0},
+		{"invalid facets", url.Values{"facets": {`categories:paper`}}, http.StatusBadRequest, nil, 0},
+	}
+
+	handler := testServer().Handler()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v2/search?"+tt.params.Encode(), nil)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tt.status, rec.Body.String())
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			var resp sources.ModrinthSearchResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("invalid response: %v", err)
+			}
+			if resp.TotalHits != tt.total {
+				t.Errorf("total_hits = %d, want %d", resp.TotalHits, tt.total)
+			}
+			var slugs []string
+			for _, hit := range resp.Hits {
+				slugs = append(slugs, hit.Slug)
+			}
+			if len(slugs) != len(tt.slugs) {
+				t.Fatalf("hits = %v, want %v", slugs, tt.slugs)
+			}
+			for i := range slugs {
+				if slugs[i] != tt.slugs[i] {
+					t.Fatalf("hits = %v, want %v", slugs, tt.slugs)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package server
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/storrealbac/mpm/internal/ui"
+)
+
+// BuildToolsURL is the default Jenkins job that publishes BuildTools.jar
+const BuildToolsURL = "https://hub.spigotmc.org/jenkins/job/BuildTools"
+
+// buildToolsLogLines is how much of the build log is shown when BuildTools fails
+const buildToolsLogLines = 30
+
+// --- BuildTools Implementation (Spigot, CraftBukkit) ---
+
+// BuildToolsDownloader builds Spigot or CraftBukkit with SpigotMC's BuildTools.
+// Built jars are cached by version, so a version is only compiled once.
+type BuildToolsDownloader struct {
+	Compile  string   // spigot or craftbukkit
+	BaseURLs []string // BuildTools Jenkins job mirrors, tried in order
+	Java     string   // java executable that runs BuildTools (default: JavaBinary)
+}
+
+func (b *BuildToolsDownloader) Download(version, build string, outputDir string) (string, error) {
+	rev := version
+	if rev == "" {
+		rev = "latest"
+	}
+
+	cacheDir, err := CacheDir()
+	if err != nil {
+		return "", err
+	}
+	buildDir := filepath.Join(cacheDir, "buildtools")
+	jarsDir := filepath.Join(buildDir, "jars")
+	workDir := filepath.Join(buildDir, "work")
+	for _, dir := range []string{jarsDir, workDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", err
+		}
+	}
+
+	// "latest" moves, so it is always rebuilt
+	cached := filepath.Join(jarsDir, fmt.Sprintf("%s-%s.jar", b.Compile, rev))
+	if rev == "latest" || !fileExists(cached) {
+		if err := b.build(rev, workDir, jarsDir, cached); err != nil {
+			return "", err
+		}
+	} else {
+		fmt.Fprintf(ui.Out, "Using cached %s %s (%s)\n", b.Compile, rev, cached)
+	}
+
+	destPath := filepath.Join(outputDir, "server.jar")
+	if err := copyFile(cached, destPath); err != nil {
+		return "", err
+	}
+	return destPath, nil
+}
+
+// build runs BuildTools for rev and moves the result to cached
+func (b *BuildToolsDownloader) build(rev, workDir, jarsDir, cached string) error {
+	buildTools, err := downloadFromMirrors(b.BaseURLs, "/lastSuccessfulBuild/artifact/target/BuildTools.jar", workDir, "BuildTools.jar")
+	if err != nil {
+		return fmt.Errorf("error downloading BuildTools: %w", err)
+	}
+
+	// BuildTools is very verbose; keep its output in a log and only show it on failure
+	logPath := filepath.Join(workDir, fmt.Sprintf("%s-%s.log", b.Compile, rev))
+	logFile, err := os.Create(logPath)
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+
+	outDir, err := os.MkdirTemp(jarsDir, "build-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(outDir)
+
+	fmt.Fprintf(ui.Out, "Building %s %s with BuildTools (this can take several minutes)...\n", b.Compile, rev)
+	cmd := exec.Command(javaOrDefault(b.Java), "-jar", buildTools, "--rev", rev, "--compile", b.Compile, "--output-dir", outDir)
+	cmd.Dir = workDir
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	if err := cmd.Run(); err != nil {
+		printLogTail(logPath, buildToolsLogLines)
+		return fmt.Errorf("BuildTools failed (is Java installed?): %w; full log: %s", err, logPath)
+	}
+
+	jars, _ := filepath.Glob(filepath.Join(outDir, b.Compile+"-*.jar"))
+	if len(jars) == 0 {
+		printLogTail(logPath, buildToolsLogLines)
+		return fmt.Errorf("BuildTools finished but no %s jar was produced; full log: %s", b.Compile, logPath)
+	}
+
+	return os.Rename(jars[0], cached)
+}
+
+// printLogTail prints the last lines of a log file
+func printLogTail(path string, lines int) {
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	var tail []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		tail = append(tail, scanner.Text())
+		if len(tail) > lines {
+			tail = tail[1:]
+		}
+	}
+
+	fmt.Fprintf(ui.Out, "Last %d lines of %s:\n", len(tail), path)
+	fmt.Fprintln(ui.Out, "  "+strings.Join(tail, "\n  "))
+}
+
+// JavaBinary returns the java executable used to run installers and BuildTools:
+// $MPM_JAVA, then $JAVA_HOME/bin/java, then java from the PATH, then the newest
+// JDK installed by mpm
+func JavaBinary() string {
+	if java := os.Getenv("MPM_JAVA"); java != "" {
+		return java
+	}
+	if home := os.Getenv("JAVA_HOME"); home != "" {
+		return filepath.Join(home, "bin", javaExecutable())
+	}
+	if _, err := exec.LookPath("java"); err != nil {
+		if installs := InstalledJDKs(); len(installs) > 0 {
+			return installs[0].Path
+		}
+	}
+	return "java"
+}
+
+// javaOrDefault returns java, or JavaBinary() when it is empty
+func javaOrDefault(java string) string {
+	if java == "" {
+		return JavaBinary()
+	}
+	return java
+}
+
+// CacheDir returns mpm's cache directory ($MPM_CACHE_DIR, or mpm/ in the user cache directory)
+func CacheDir() (string, error) {
+	if dir := os.Getenv("MPM_CACHE_DIR"); dir != "" {
+		return dir, nil
+	}
+
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "mpm"), nil
+}
@@ -1,36 +1,70 @@
 package server
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"hash"
 	"io"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 
+	"github.com/storrealbac/mpm/internal/models"
 	"github.com/storrealbac/mpm/internal/ui"
 )
 
+// Default API endpoints for server jar downloads
+const (
+	PaperMCBaseURL = "https://fill.papermc.io/v3"
+	PurpurBaseURL  = "https://api.purpurmc.org/v2"
+	SpongeAPIURL   = "https://dl-api.spongepowered.org/v2"
+)
+
 // Downloader define la interfaz para descargar jars de servidor
 type Downloader interface {
 	Download(version, build string, outputDir string) (string, error)
 }
 
-func GetDownloader(serverType string) (Downloader, error) {
+// GetDownloader returns the downloader for a server type.
+// endpoints provides the base URLs (mirrors) for each API; empty lists fall back to the defaults.
+// java runs the installers and BuildTools of the types that need them ("" for JavaBinary).
+func GetDownloader(serverType string, endpoints models.Endpoints, java string) (Downloader, error) {
 	switch strings.ToLower(serverType) {
-	case "paper", "velocity", "waterfall":
-		return &PaperDownloader{Project: strings.ToLower(serverType)}, nil
+	case "paper", "folia", "velocity", "waterfall":
+		return &PaperDownloader{Project: strings.ToLower(serverType), BaseURLs: withDefault(endpoints.PaperMC, PaperMCBaseURL)}, nil
 	case "purpur":
-		return &PurpurDownloader{}, nil
-	case "folia":
-		return &FoliaDownloader{}, nil
+		return &PurpurDownloader{BaseURLs: withDefault(endpoints.Purpur, PurpurBaseURL)}, nil
 	case "spigot":
-		return &SpigotDownloader{}, nil
+		return &BuildToolsDownloader{Compile: "spigot", Java: java, BaseURLs: withDefault(endpoints.BuildTools, BuildToolsURL)}, nil
 	case "bukkit":
-		return &BukkitDownloader{}, nil
+		return &BuildToolsDownloader{Compile: "craftbukkit", Java: java, BaseURLs: withDefault(endpoints.BuildTools, BuildToolsURL)}, nil
 	case "sponge":
-		return &SpongeDownloader{}, nil
+		return &SpongeDownloader{BaseURLs: withDefault(endpoints.Sponge, SpongeAPIURL)}, nil
+	case "bungeecord":
+		return &BungeeCordDownloader{BaseURLs: withDefault(endpoints.BungeeCord, BungeeCordJenkinsURL)}, nil
+	case "pufferfish":
+		return &PufferfishDownloader{BaseURLs: withDefault(endpoints.Pufferfish, PufferfishJenkinsURL)}, nil
+	case "leaf":
+		return &GitHubReleaseDownloader{Name: "Leaf", Repo: "Winds-Studio/Leaf", BaseURLs: withDefault(endpoints.GitHub, GitHubAPIURL)}, nil
+	case "leaves":
+		return &GitHubReleaseDownloader{Name: "Leaves", Repo: "LeavesMC/Leaves", BaseURLs: withDefault(endpoints.GitHub, GitHubAPIURL)}, nil
+	case "canvas":
+		return &GitHubReleaseDownloader{Name: "Canvas", Repo: "CraftCanvasMC/Canvas", BaseURLs: withDefault(endpoints.GitHub, GitHubAPIURL)}, nil
+	case "vanilla":
+		return &VanillaDownloader{BaseURLs: withDefault(endpoints.Mojang, MojangMetaURL)}, nil
+	case "fabric":
+		return &FabricDownloader{BaseURLs: withDefault(endpoints.Fabric, FabricMetaURL)}, nil
+	case "quilt":
+		return &QuiltDownloader{Java: java, BaseURLs: withDefault(endpoints.Quilt, QuiltMavenURL)}, nil
+	case "forge":
+		return &ForgeDownloader{Java: java, BaseURLs: withDefault(endpoints.Forge, ForgeMavenURL)}, nil
+	case "neoforge":
+		return &NeoForgeDownloader{Java: java, BaseURLs: withDefault(endpoints.NeoForge, NeoForgeMavenURL)}, nil
 	default:
 		return nil, fmt.Errorf("tipo de servidor no soportado: %s", serverType)
 	}
@@ -38,238 +72,272 @@ func GetDownloader(serverType string) (Downloader, error) {
 
 // --- PaperMC Implementation ---
 
+// PaperDownloader downloads any PaperMC project (paper, folia, velocity, waterfall)
+// from the Fill v3 API.
+// build is a build number, "latest" for the newest build of any channel,
+// or "stable" for the newest stable build only.
 type PaperDownloader struct {
-	Project string // paper, velocity, waterfall
+	Project  string   // paper, folia, velocity, waterfall
+	BaseURLs []string // PaperMC Fill API mirrors, tried in order
 }
 
-func (p *PaperDownloader) Download(version, build string, outputDir string) (string, error) {
-	// 1. Si build es "latest", obtener el último build
-	if build == "" || build == "latest" {
-		latestBuild, err := p.getLatestBuild(version)
-		if err != nil {
-			return "", err
-		}
-		build = latestBuild
-	}
+// paperBuild is a build in the Fill v3 API
+type paperBuild struct {
+	ID        int                      `json:"id"`
+	Channel   string                   `json:"channel"` // ALPHA, BETA, STABLE, RECOMMENDED
+	Downloads map[string]paperDownload `json:"downloads"`
+}
 
-	// 2. Construir URL
-	// https://api.papermc.io/v2/projects/{project}/versions/{version}/builds/{build}/downloads/{download}
-	fileName := fmt.Sprintf("%s-%s-%s.jar", p.Project, version, build)
-	url := fmt.Sprintf("https://api.papermc.io/v2/projects/%s/versions/%s/builds/%s/downloads/%s", p.Project, version, build, fileName)
+type paperDownload struct {
+	Name      string            `json:"name"`
+	Checksums map[string]string `json:"checksums"`
+	URL       string            `json:"url"`
+}
 
-	// 3. Descargar
-	return downloadFile(url, outputDir, "server.jar") // Siempre guardamos como server.jar para consistencia? O conservamos nombre?
-	// El usuario pidió "descargue tambien el .jar del servidor en la carpeta".
-	// Usualmente se renombra a server.jar para que los scripts de inicio no cambien.
+func (b paperBuild) stable() bool {
+	return b.Channel == "STABLE" || b.Channel == "RECOMMENDED"
 }
 
-func (p *PaperDownloader) getLatestBuild(version string) (string, error) {
-	url := fmt.Sprintf("https://api.papermc.io/v2/projects/%s/versions/%s/builds", p.Project, version)
-	resp, err := http.Get(url)
+func (p *PaperDownloader) Download(version, build string, outputDir string) (string, error) {
+	selected, err := p.getBuild(version, build)
 	if err != nil {
 		return "", err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != 200 {
-		return "", fmt.Errorf("error API PaperMC: %d", resp.StatusCode)
+	download, ok := selected.Downloads["server:default"]
+	if !ok || download.URL == "" {
+		return "", fmt.Errorf("%s %s build %d has no server download", p.Project, version, selected.ID)
 	}
-
-	var result struct {
-		Builds []struct {
-			Build int `json:"build"`
-		} `json:"builds"`
+	if !selected.stable() {
+		fmt.Fprintf(ui.Out, "Warning: %s %s build %d is an experimental (%s) build\n", p.Project, version, selected.ID, selected.Channel)
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", err
+	fmt.Fprintf(ui.Out, "Selected %s\n", download.Name)
+
+	return downloadVerified(download.URL, outputDir, sha256.New, download.Checksums["sha256"])
+}
+
+// getBuild resolves build to a build of the project version
+func (p *PaperDownloader) getBuild(version, build string) (*paperBuild, error) {
+	base := fmt.Sprintf("/projects/%s/versions/%s/builds", p.Project, version)
+
+	if build != "" && build != "latest" && build != "stable" {
+		var selected paperBuild
+		if err := getJSONFromMirrors(p.BaseURLs, base+"/"+build, &selected); err != nil {
+			return nil, fmt.Errorf("error API PaperMC: build %s of %s %s: %w", build, p.Project, version, err)
+		}
+		return &selected, nil
 	}
 
-	if len(result.Builds) == 0 {
-		return "", fmt.Errorf("no se encontraron builds para %s %s", p.Project, version)
+	var builds []paperBuild
+	if err := getJSONFromMirrors(p.BaseURLs, base, &builds); err != nil {
+		return nil, fmt.Errorf("error API PaperMC: %w", err)
+	}
+	if len(builds) == 0 {
+		return nil, fmt.Errorf("no se encontraron builds para %s %s", p.Project, version)
 	}
 
-	// El último en la lista es el más reciente
-	return fmt.Sprintf("%d", result.Builds[len(result.Builds)-1].Build), nil
+	// Don't rely on the response order
+	sort.Slice(builds, func(i, j int) bool { return builds[i].ID > builds[j].ID })
+
+	if build != "stable" {
+		return &builds[0], nil
+	}
+	for i := range builds {
+		if builds[i].stable() {
+			return &builds[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no stable builds for %s %s yet (use build: latest to accept experimental builds)", p.Project, version)
 }
 
 // --- Purpur Implementation ---
 
-type PurpurDownloader struct{}
+type PurpurDownloader struct {
+	BaseURLs []string // Purpur API mirrors, tried in order
+}
 
 func (p *PurpurDownloader) Download(version, build string, outputDir string) (string, error) {
 	if build == "" || build == "latest" {
 		build = "latest"
 	}
 
-	// https://api.purpurmc.org/v2/purpur/{version}/{build}/download
-	url := fmt.Sprintf("https://api.purpurmc.org/v2/purpur/%s/%s/download", version, build)
+	// {base}/purpur/{version}/{build}/download
+	path := fmt.Sprintf("/purpur/%s/%s/download", version, build)
 
-	return downloadFile(url, outputDir, "server.jar")
+	return downloadFromMirrors(p.BaseURLs, path, outputDir, "server.jar")
 }
 
 // --- Helper ---
 
-func downloadFile(url, outputDir, fileName string) (string, error) {
-	destPath := filepath.Join(outputDir, fileName)
-	fmt.Printf("Downloading server from %s...\n", url)
+// withDefault returns urls, or a single-element list with fallback when urls is empty
+func withDefault(urls []string, fallback string) []string {
+	if len(urls) == 0 {
+		return []string{fallback}
+	}
+	return urls
+}
+
+// userAgent identifies mpm to the download APIs; PaperMC's Fill API rejects requests without one
+const userAgent = "mpm (https://github.com/storrealbac/mpm)"
 
-	resp, err := http.Get(url)
+// httpGet is http.Get with mpm's User-Agent
+func httpGet(url string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	defer resp.Body.Close()
+	req.Header.Set("User-Agent", userAgent)
+	return http.DefaultClient.Do(req)
+}
 
-	if resp.StatusCode != 200 {
-		return "", fmt.Errorf("download error: %d", resp.StatusCode)
+// hashFile returns the hex digest of a file
+func hashFile(path string, newHash func() hash.Hash) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
 	}
+	defer f.Close()
 
-	if resp.ContentLength <= 0 {
-		fmt.Println("Warning: Content length unknown, progress bar might not work.")
+	hasher := newHash()
+	if _, err := io.Copy(hasher, f); err != nil {
+		return "", err
 	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
 
-	out, err := os.Create(destPath)
+// downloadVerified downloads a server jar next to the current one and only replaces
+// server.jar once it matches the expected hex digest (skipped when expected is empty)
+func downloadVerified(downloadURL, outputDir string, newHash func() hash.Hash, expected string) (string, error) {
+	tmpPath, err := downloadFile(downloadURL, outputDir, "server.jar.download")
 	if err != nil {
 		return "", err
 	}
-	defer out.Close()
 
-	// Progress bar
-	counter := &ui.WriteCounter{Total: uint64(resp.ContentLength)}
-	if _, err = io.Copy(out, io.TeeReader(resp.Body, counter)); err != nil {
-		return "", err
+	if expected != "" {
+		sum, err := hashFile(tmpPath, newHash)
+		if err != nil {
+			os.Remove(tmpPath)
+			return "", err
+		}
+		if !strings.EqualFold(sum, expected) {
+			os.Remove(tmpPath)
+			return "", fmt.Errorf("checksum mismatch for %s: expected %s, got %s", path.Base(downloadURL), expected, sum)
+		}
 	}
 
-	// Force 100% if total was unknown or just to be sure
-	if resp.ContentLength <= 0 {
-		fmt.Printf("\r[%s] 100.00%%", strings.Repeat("=", 40))
+	// Siempre guardamos como server.jar para que los scripts de inicio no cambien
+	destPath := filepath.Join(outputDir, "server.jar")
+	if err := os.Rename(tmpPath, destPath); err != nil {
+		os.Remove(tmpPath)
+		return "", err
 	}
-	fmt.Println() // New line after progress bar
-
 	return destPath, nil
 }
 
-// --- Folia Implementation ---
+// getJSONFromMirrors fetches path from each base URL in order and decodes the first successful response into v
+func getJSONFromMirrors(baseURLs []string, path string, v interface{}) error {
+	var lastErr error
+	for _, base := range baseURLs {
+		resp, err := httpGet(strings.TrimRight(base, "/") + path)
+		if err != nil {
+			lastErr = err
+			continue
+		}
 
-type FoliaDownloader struct{}
+		if resp.StatusCode != 200 {
+			resp.Body.Close()
+			lastErr = fmt.Errorf("status %d from %s", resp.StatusCode, base)
+			continue
+		}
 
-func (f *FoliaDownloader) Download(version, build string, outputDir string) (string, error) {
-	// Folia uses PaperMC API similar to Paper
-	// 1. If build is "latest", get the latest build
-	if build == "" || build == "latest" {
-		latestBuild, err := f.getLatestBuild(version)
+		err = json.NewDecoder(resp.Body).Decode(v)
+		resp.Body.Close()
 		if err != nil {
-			return "", err
+			lastErr = err
+			continue
 		}
-		build = latestBuild
+		return nil
 	}
 
-	// 2. Build URL
-	fileName := fmt.Sprintf("folia-%s-%s.jar", version, build)
-	url := fmt.Sprintf("https://api.papermc.io/v2/projects/folia/versions/%s/builds/%s/downloads/%s", version, build, fileName)
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no API endpoints configured")
+	}
+	return lastErr
+}
 
-	// 3. Download
-	return downloadFile(url, outputDir, "server.jar")
+// downloadFromMirrors downloads path from the first base URL that serves it
+func downloadFromMirrors(baseURLs []string, path, outputDir, fileName string) (string, error) {
+	var lastErr error
+	for _, base := range baseURLs {
+		destPath, err := downloadFile(strings.TrimRight(base, "/")+path, outputDir, fileName)
+		if err == nil {
+			return destPath, nil
+		}
+		lastErr = err
+	}
+
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no download endpoints configured")
+	}
+	return "", lastErr
 }
 
-func (f *FoliaDownloader) getLatestBuild(version string) (string, error) {
-	url := fmt.Sprintf("https://api.papermc.io/v2/projects/folia/versions/%s/builds", version)
-	resp, err := http.Get(url)
+func downloadFile(url, outputDir, fileName string) (string, error) {
+	destPath := filepath.Join(outputDir, fileName)
+	fmt.Fprintf(ui.Out, "Downloading %s...\n", url)
+
+	resp, err := httpGet(url)
 	if err != nil {
 		return "", err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return "", fmt.Errorf("error API PaperMC (Folia): %d", resp.StatusCode)
+		return "", fmt.Errorf("download error: %d", resp.StatusCode)
 	}
 
-	var result struct {
-		Builds []struct {
-			Build int `json:"build"`
-		} `json:"builds"`
+	if resp.ContentLength <= 0 {
+		fmt.Fprintln(ui.Out, "Warning: Content length unknown, progress bar might not work.")
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+
+	out, err := os.Create(destPath)
+	if err != nil {
 		return "", err
 	}
+	defer out.Close()
 
-	if len(result.Builds) == 0 {
-		return "", fmt.Errorf("no se encontraron builds para Folia %s", version)
+	// Progress bar
+	counter := &ui.WriteCounter{Total: uint64(resp.ContentLength)}
+	if _, err = io.Copy(out, io.TeeReader(resp.Body, counter)); err != nil {
+		return "", err
 	}
 
-	// Last in the list is the most recent
-	return fmt.Sprintf("%d", result.Builds[len(result.Builds)-1].Build), nil
-}
-
-// --- Spigot Implementation ---
-
-type SpigotDownloader struct{}
-
-func (s *SpigotDownloader) Download(version, build string, outputDir string) (string, error) {
-	// Spigot doesn't have an official API for downloading pre-built jars
-	// Users typically need to use BuildTools
-	// However, we can use the GetBukkit.org API which provides pre-built Spigot jars
-	url := fmt.Sprintf("https://download.getbukkit.org/spigot/spigot-%s.jar", version)
+	// Force 100% if total was unknown or just to be sure
+	if resp.ContentLength <= 0 {
+		fmt.Fprintf(ui.Out, "\r[%s] 100.00%%", strings.Repeat("=", 40))
+	}
+	fmt.Fprintln(ui.Out) // New line after progress bar
 
-	return downloadFile(url, outputDir, "server.jar")
+	return destPath, nil
 }
 
-// --- Bukkit Implementation ---
+// --- BungeeCord Implementation ---
 
-type BukkitDownloader struct{}
+// BungeeCordJenkinsURL is the default Jenkins job that publishes BungeeCord
+const BungeeCordJenkinsURL = "https://ci.md-5.net/job/BungeeCord"
 
-func (b *BukkitDownloader) Download(version, build string, outputDir string) (string, error) {
-	// Bukkit/CraftBukkit can be downloaded from GetBukkit.org
-	url := fmt.Sprintf("https://download.getbukkit.org/craftbukkit/craftbukkit-%s.jar", version)
-
-	return downloadFile(url, outputDir, "server.jar")
+// BungeeCordDownloader downloads BungeeCord from md_5's Jenkins. A single build
+// supports every Minecraft version, so version is ignored; build is a Jenkins
+// build number or latest.
+type BungeeCordDownloader struct {
+	BaseURLs []string // BungeeCord Jenkins job mirrors, tried in order
 }
 
-// --- Sponge Implementation ---
-
-type SpongeDownloader struct{}
-
-func (s *SpongeDownloader) Download(version, build string, outputDir string) (string, error) {
-	// SpongeVanilla or SpongeForge download
-	// Sponge has different versions based on Minecraft version
-	// We'll use the SpongeVanilla API
-	// Note: This is a simplified implementation
-	// For production, you'd want to query the Sponge API to get the correct build
-
+func (b *BungeeCordDownloader) Download(version, build string, outputDir string) (string, error) {
 	if build == "" || build == "latest" {
-		// Get latest recommended build
-		latestBuild, err := s.getLatestBuild(version)
-		if err != nil {
-			return "", err
-		}
-		build = latestBuild
-	}
-
-	url := fmt.Sprintf("https://repo.spongepowered.org/repository/maven-releases/org/spongepowered/spongevanilla/%s/spongevanilla-%s.jar", build, build)
-
-	return downloadFile(url, outputDir, "server.jar")
-}
-
-func (s *SpongeDownloader) getLatestBuild(version string) (string, error) {
-	// For Sponge, we need to determine the API version based on Minecraft version
-	// This is a simplified mapping - in production you'd query their API
-	// Common mappings:
-	// MC 1.16.5 -> API 8
-	// MC 1.18.2 -> API 9
-	// MC 1.19.4 -> API 10
-	// MC 1.20.x -> API 11
-
-	apiVersion := "11.0.0" // Default to latest API
-
-	// Basic version mapping
-	if strings.HasPrefix(version, "1.16") {
-		apiVersion = "8.2.0"
-	} else if strings.HasPrefix(version, "1.18") {
-		apiVersion = "9.0.0"
-	} else if strings.HasPrefix(version, "1.19") {
-		apiVersion = "10.0.0"
-	} else if strings.HasPrefix(version, "1.20") || strings.HasPrefix(version, "1.21") {
-		apiVersion = "11.0.0"
+		build = "lastSuccessfulBuild"
 	}
 
-	return apiVersion, nil
+	path := fmt.Sprintf("/%s/artifact/bootstrap/target/BungeeCord.jar", build)
+	return downloadFromMirrors(b.BaseURLs, path, outputDir, "server.jar")
 }
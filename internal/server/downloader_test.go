@@ -0,0 +1,118 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPaperDownloader(t *testing.T) {
+	jarOf := func(id int) string { return fmt.Sprintf("paper build %d", id) }
+	sha256Of := func(id int) string {
+		sum := sha256.Sum256([]byte(jarOf(id)))
+		return hex.EncodeToString(sum[:])
+	}
+
+	tests := []struct {
+		name    string
+		version string
+		build   string
+		want    int // build downloaded
+		err     string
+	}{
+		{name: "latest is the newest build of any channel", version: "1.21", build: "latest", want: 13},
+		{name: "no build means latest", version: "1.21", want: 13},
+		{name: "stable skips experimental builds", version: "1.21", build: "stable", want: 12},
+		{name: "recommended counts as stable", version: "1.20.6", build: "stable", want: 3},
+		{name: "pinned build", version: "1.21", build: "10", want: 10},
+		{name: "no stable build yet", version: "1.22", build: "stable", err: "no stable builds for paper 1.22"},
+		{name: "no builds", version: "1.23", err: "no se encontraron builds"},
+		{name: "unknown build", version: "1.21", build: "99", err: "build 99 of paper 1.21"},
+		{name: "checksum mismatch", version: "1.21", build: "11", err: "checksum mismatch"},
+		{name: "no server download", version: "1.21", build: "9", err: "has no server download"},
+	}
+
+	var server *httptest.Server
+	build := func(id int, channel string) map[string]any {
+		checksum := sha256Of(id)
+		if id == 11 {
+			checksum = strings.Repeat("0", 64)
+		}
+		downloads := map[string]any{"server:default": map[string]any{
+			"name":      fmt.Sprintf("paper-%d.jar", id),
+			"checksums": map[string]string{"sha256": checksum},
+			"url":       fmt.Sprintf("%s/jars/%d", server.URL, id),
+		}}
+		if id == 9 {
+			downloads = map[string]any{}
+		}
+		return map[string]any{"id": id, "channel": channel, "downloads": downloads}
+	}
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") == "" {
+			w.WriteHeader(http.StatusForbidden) // like Fill
+			return
+		}
+		// Builds out of order: the downloader must not rely on it
+		builds := map[string][]map[string]any{
+			"/projects/paper/versions/1.21/builds":   {build(12, "STABLE"), build(9, "STABLE"), build(13, "ALPHA"), build(10, "STABLE"), build(11, "BETA")},
+			"/projects/paper/versions/1.20.6/builds": {build(3, "RECOMMENDED"), build(4, "BETA")},
+			"/projects/paper/versions/1.22/builds":   {build(1, "ALPHA"), build(2, "BETA")},
+			"/projects/paper/versions/1.23/builds":   {},
+		}
+		if list, ok := builds[r.URL.Path]; ok {
+			json.NewEncoder(w).Encode(list)
+			return
+		}
+		var id int
+		if _, err := fmt.Sscanf(r.URL.Path, "/projects/paper/versions/1.21/builds/%d", &id); err == nil && id >= 9 && id <= 13 {
+			json.NewEncoder(w).Encode(build(id, "STABLE"))
+			return
+		}
+		if _, err := fmt.Sscanf(r.URL.Path, "/jars/%d", &id); err == nil {
+			w.Write([]byte(jarOf(id)))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			downloader := &PaperDownloader{Project: "paper", BaseURLs: []string{server.URL}}
+
+			path, err := downloader.Download(tt.version, tt.build, dir)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("err = %v, want %q", err, tt.err)
+				}
+				if entries, _ := os.ReadDir(dir); len(entries) > 0 {
+					t.Errorf("a failed download left %s behind", entries[0].Name())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if path != filepath.Join(dir, "server.jar") {
+				t.Errorf("Download() = %s, want server.jar", path)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != jarOf(tt.want) {
+				t.Errorf("downloaded %q, want build %d", data, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// EULAURL is the Minecraft End User License Agreement servers must accept
+const EULAURL = "https://aka.ms/MinecraftEULA"
+
+// EULAFile is the file the server reads the EULA acceptance from
+const EULAFile = "eula.txt"
+
+// EULAAccepted reports whether dir has an eula.txt with eula=true
+func EULAAccepted(dir string) bool {
+	file, err := os.Open(filepath.Join(dir, EULAFile))
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if ok && strings.TrimSpace(key) == "eula" {
+			return strings.EqualFold(strings.TrimSpace(value), "true")
+		}
+	}
+	return false
+}
+
+// WriteEULA writes an eula.txt accepting the EULA into dir, in the format the server writes it
+func WriteEULA(dir string) error {
+	content := fmt.Sprintf("#By changing the setting below to TRUE you are indicating your agreement to our EULA (%s).\n#%s\neula=true\n",
+		EULAURL, time.Now().Format("Mon Jan 02 15:04:05 MST 2006"))
+	if err := os.WriteFile(filepath.Join(dir, EULAFile), []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", EULAFile, err)
+	}
+	return nil
+}
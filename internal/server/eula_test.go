@@ -0,0 +1,49 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEULAAccepted(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string // "" for no eula.txt
+		want    bool
+	}{
+		{"no file", "", false},
+		{"accepted", "#By changing the setting below to TRUE...\n#Mon Jan 01 00:00:00 UTC 2024\neula=true\n", true},
+		{"not accepted", "eula=false\n", false},
+		{"upper case", "eula=TRUE\n", true},
+		{"spaces", "  eula = true  \n", true},
+		{"commented out", "#eula=true\n", false},
+		{"other keys only", "foo=true\n", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.content != "" {
+				if err := os.WriteFile(filepath.Join(dir, EULAFile), []byte(tt.content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := EULAAccepted(dir); got != tt.want {
+				t.Errorf("EULAAccepted() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteEULA(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, EULAFile), []byte("eula=false\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteEULA(dir); err != nil {
+		t.Fatal(err)
+	}
+	if !EULAAccepted(dir) {
+		t.Error("WriteEULA did not accept the EULA")
+	}
+}
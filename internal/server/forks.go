@@ -0,0 +1,185 @@
+package server
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/storrealbac/mpm/internal/ui"
+)
+
+// Default API endpoints for Paper forks
+const (
+	PufferfishJenkinsURL = "https://ci.pufferfish.host"
+	GitHubAPIURL         = "https://api.github.com"
+)
+
+// --- Pufferfish Implementation ---
+
+// PufferfishDownloader downloads Pufferfish from its Jenkins. There is one job per
+// Minecraft minor version (Pufferfish-1.20, Pufferfish-1.21) that builds its newest patch.
+// build is a Jenkins build number or latest.
+type PufferfishDownloader struct {
+	BaseURLs []string // Pufferfish Jenkins mirrors, tried in order
+}
+
+func (p *PufferfishDownloader) Download(version, build string, outputDir string) (string, error) {
+	parts := strings.Split(version, ".")
+	if len(parts) < 2 {
+		return "", fmt.Errorf("invalid Minecraft version for Pufferfish: %s", version)
+	}
+	job := fmt.Sprintf("/job/Pufferfish-%s.%s", parts[0], parts[1])
+
+	if build == "" || build == "latest" {
+		build = "lastSuccessfulBuild"
+	}
+
+	var result struct {
+		Number    int `json:"number"`
+		Artifacts []struct {
+			FileName     string `json:"fileName"`
+			RelativePath string `json:"relativePath"`
+		} `json:"artifacts"`
+	}
+	if err := getJSONFromMirrors(p.BaseURLs, fmt.Sprintf("%s/%s/api/json", job, build), &result); err != nil {
+		return "", fmt.Errorf("error Pufferfish Jenkins: %w", err)
+	}
+
+	// The job only builds the newest patch of its minor version
+	var found []string
+	for _, artifact := range result.Artifacts {
+		if !strings.HasSuffix(artifact.FileName, ".jar") {
+			continue
+		}
+		if containsMCVersion(artifact.FileName, version) {
+			fmt.Fprintf(ui.Out, "Selected %s (build %d)\n", artifact.FileName, result.Number)
+			path := fmt.Sprintf("%s/%d/artifact/%s", job, result.Number, artifact.RelativePath)
+			return downloadFromMirrors(p.BaseURLs, path, outputDir, "server.jar")
+		}
+		found = append(found, artifact.FileName)
+	}
+
+	if len(found) > 0 {
+		return "", fmt.Errorf("Pufferfish build %d is not for Minecraft %s (found %s)", result.Number, version, strings.Join(found, ", "))
+	}
+	return "", fmt.Errorf("Pufferfish build %d has no server jar", result.Number)
+}
+
+// --- GitHub Releases Implementation (Leaf, Leaves, Canvas) ---
+
+// GitHubReleaseDownloader downloads a server jar attached to a GitHub release.
+// The newest release whose tag, name or jar names the Minecraft version is used;
+// build is a release tag or latest.
+type GitHubReleaseDownloader struct {
+	Name     string   // display name (Leaf, Leaves, Canvas)
+	Repo     string   // owner/repo
+	BaseURLs []string // GitHub API mirrors, tried in order
+}
+
+// gitHubRelease is a release in the GitHub REST API
+type gitHubRelease struct {
+	TagName    string        `json:"tag_name"`
+	Name       string        `json:"name"`
+	Draft      bool          `json:"draft"`
+	Prerelease bool          `json:"prerelease"`
+	Assets     []gitHubAsset `json:"assets"`
+}
+
+type gitHubAsset struct {
+	Name               string `json:"name"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+	Digest             string `json:"digest"` // sha256:<hex>, on recent releases
+}
+
+func (g *GitHubReleaseDownloader) Download(version, build string, outputDir string) (string, error) {
+	var releases []gitHubRelease
+	if build == "" || build == "latest" {
+		path := fmt.Sprintf("/repos/%s/releases?per_page=100", g.Repo)
+		if err := getJSONFromMirrors(g.BaseURLs, path, &releases); err != nil {
+			return "", fmt.Errorf("error GitHub API (%s): %w", g.Repo, err)
+		}
+	} else {
+		var release gitHubRelease
+		path := fmt.Sprintf("/repos/%s/releases/tags/%s", g.Repo, url.PathEscape(build))
+		if err := getJSONFromMirrors(g.BaseURLs, path, &release); err != nil {
+			return "", fmt.Errorf("error GitHub API (%s): release %s: %w", g.Repo, build, err)
+		}
+		releases = []gitHubRelease{release}
+	}
+
+	// Releases are listed newest first
+	for _, release := range releases {
+		if release.Draft {
+			continue
+		}
+		asset := serverAsset(release, version)
+		if asset == nil {
+			continue
+		}
+
+		if release.Prerelease {
+			fmt.Fprintf(ui.Out, "Warning: %s %s is a pre-release\n", g.Name, release.TagName)
+		}
+		fmt.Fprintf(ui.Out, "Selected %s (%s)\n", asset.Name, release.TagName)
+		checksum := ""
+		if digest, ok := strings.CutPrefix(asset.Digest, "sha256:"); ok {
+			checksum = digest
+		}
+		return downloadVerified(asset.BrowserDownloadURL, outputDir, sha256.New, checksum)
+	}
+
+	return "", fmt.Errorf("no %s release found for Minecraft %s", g.Name, version)
+}
+
+// serverAsset picks the server jar of a release for a Minecraft version
+func serverAsset(release gitHubRelease, version string) *gitHubAsset {
+	releaseMatches := containsMCVersion(release.TagName, version) || containsMCVersion(release.Name, version)
+
+	var fallback *gitHubAsset
+	for i := range release.Assets {
+		asset := &release.Assets[i]
+		name := strings.ToLower(asset.Name)
+		if !strings.HasSuffix(name, ".jar") || strings.Contains(name, "-sources") || strings.Contains(name, "-javadoc") || strings.Contains(name, "-api") {
+			continue
+		}
+		if containsMCVersion(asset.Name, version) {
+			return asset
+		}
+		if releaseMatches && fallback == nil {
+			fallback = asset
+		}
+	}
+	return fallback
+}
+
+// containsMCVersion reports whether s names the Minecraft version exactly,
+// so that 1.21 doesn't match 1.21.4
+func containsMCVersion(s, version string) bool {
+	if version == "" {
+		return false
+	}
+	for offset := 0; ; {
+		i := strings.Index(s[offset:], version)
+		if i < 0 {
+			return false
+		}
+		end := offset + i + len(version)
+		start := offset + i
+
+		before := start == 0 || !isVersionChar(s[start-1])
+		after := end == len(s) || !(isDigit(s[end]) || (s[end] == '.' && end+1 < len(s) && isDigit(s[end+1])))
+		if before && after {
+			return true
+		}
+		offset = start + 1
+	}
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isVersionChar(c byte) bool {
+	return isDigit(c) || c == '.'
+}
@@ -0,0 +1,236 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestContainsMCVersion(t *testing.T) {
+	tests := []struct {
+		s, version string
+		want       bool
+	}{
+		{"leaf-1.21.jar", "1.21", true},
+		{"leaf-1.21.1.jar", "1.21", false},
+		{"leaf-1.21.1.jar", "1.21.1", true},
+		{"leaf-1.21.10.jar", "1.21.1", false},
+		{"pufferfish-paperclip-1.20.4-R0.1-SNAPSHOT-mojmap.jar", "1.20.4", true},
+		{"pufferfish-paperclip-1.20.4-R0.1-SNAPSHOT-mojmap.jar", "1.20", false},
+		{"ver/1.21.4", "1.21.4", true},
+		{"1.21", "1.21", true},
+		{"11.21", "1.21", false},
+		{"v1.21", "1.21", true},
+		{"1.21.x", "1.21", true},
+		{"1.20.6 and 1.21", "1.21", true},
+		{"canvas-1.21.4-95.jar", "1.21.4", true},
+		{"anything", "", false},
+	}
+	for _, tt := range tests {
+		if got := containsMCVersion(tt.s, tt.version); got != tt.want {
+			t.Errorf("containsMCVersion(%q, %q) = %v, want %v", tt.s, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestServerAsset(t *testing.T) {
+	tests := []struct {
+		name    string
+		release gitHubRelease
+		version string
+		want    string // asset name, "" for none
+	}{
+		{
+			name:    "jar names the version",
+			release: gitHubRelease{TagName: "build-95", Assets: []gitHubAsset{{Name: "leaves-1.21.3.jar"}, {Name: "leaves-1.21.4.jar"}}},
+			version: "1.21.4",
+			want:    "leaves-1.21.4.jar",
+		},
+		{
+			name:    "tag names the version",
+			release: gitHubRelease{TagName: "ver-1.21.4", Assets: []gitHubAsset{{Name: "leaf-sources.jar"}, {Name: "leaf.jar"}}},
+			version: "1.21.4",
+			want:    "leaf.jar",
+		},
+		{
+			name:    "release name names the version",
+			release: gitHubRelease{TagName: "95", Name: "Canvas 1.21.4", Assets: []gitHubAsset{{Name: "canvas-api.jar"}, {Name: "canvas-server.jar"}}},
+			version: "1.21.4",
+			want:    "canvas-server.jar",
+		},
+		{
+			name:    "patch release doesn't match the minor version",
+			release: gitHubRelease{TagName: "ver-1.21.1", Assets: []gitHubAsset{{Name: "leaf-1.21.1.jar"}}},
+			version: "1.21",
+		},
+		{
+			name:    "only sources and javadoc",
+			release: gitHubRelease{TagName: "ver-1.21.4", Assets: []gitHubAsset{{Name: "leaf-1.21.4-sources.jar"}, {Name: "leaf-1.21.4-javadoc.jar"}, {Name: "leaf-1.21.4.zip"}}},
+			version: "1.21.4",
+		},
+		{
+			name:    "no assets",
+			release: gitHubRelease{TagName: "ver-1.21.4"},
+			version: "1.21.4",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ""
+			if asset := serverAsset(tt.release, tt.version); asset != nil {
+				got = asset.Name
+			}
+			if got != tt.want {
+				t.Errorf("serverAsset() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGitHubReleaseDownloader(t *testing.T) {
+	digest := func(content string) string {
+		sum := sha256.Sum256([]byte(content))
+		return "sha256:" + hex.EncodeToString(sum[:])
+	}
+
+	var server *httptest.Server
+	asset := func(name, digest string) gitHubAsset {
+		return gitHubAsset{Name: name, BrowserDownloadURL: server.URL + "/download/" + name, Digest: digest}
+	}
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Newest first, like the GitHub API
+		releases := []gitHubRelease{
+			{TagName: "ver-1.21.5", Draft: true, Assets: []gitHubAsset{asset("leaf-1.21.5.jar", "")}},
+			{TagName: "ver-1.21.4-beta", Prerelease: true, Assets: []gitHubAsset{asset("leaf-1.21.4-beta.jar", digest("leaf-1.21.4-beta.jar"))}},
+			{TagName: "ver-1.21.3", Assets: []gitHubAsset{asset("leaf-1.21.3.jar", "")}},
+			{TagName: "ver-1.21.1", Assets: []gitHubAsset{asset("leaf-1.21.1.jar", digest("something else"))}},
+			{TagName: "ver-1.21", Assets: []gitHubAsset{asset("leaf-1.21.jar", digest("leaf-1.21.jar"))}},
+		}
+		switch {
+		case r.URL.Path == "/repos/Winds-Studio/Leaf/releases":
+			json.NewEncoder(w).Encode(releases)
+		case strings.HasPrefix(r.URL.Path, "/repos/Winds-Studio/Leaf/releases/tags/"):
+			tag := strings.TrimPrefix(r.URL.Path, "/repos/Winds-Studio/Leaf/releases/tags/")
+			for _, release := range releases {
+				if release.TagName == tag {
+					json.NewEncoder(w).Encode(release)
+					return
+				}
+			}
+			http.NotFound(w, r)
+		case strings.HasPrefix(r.URL.Path, "/download/"):
+			w.Write([]byte(strings.TrimPrefix(r.URL.Path, "/download/")))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		version string
+		build   string
+		want    string // content of server.jar
+		err     string
+	}{
+		{name: "pre-release", version: "1.21.4", want: "leaf-1.21.4-beta.jar"},
+		{name: "release without a digest", version: "1.21.3", want: "leaf-1.21.3.jar"},
+		{name: "minor version isn't a patch release", version: "1.21", want: "leaf-1.21.jar"},
+		{name: "pinned tag", version: "1.21.3", build: "ver-1.21.3", want: "leaf-1.21.3.jar"},
+		{name: "drafts are skipped", version: "1.21.5", err: "no Leaf release found for Minecraft 1.21.5"},
+		{name: "no release for the version", version: "1.20.6", err: "no Leaf release found for Minecraft 1.20.6"},
+		{name: "pinned tag for another version", version: "1.21.4", build: "ver-1.21.3", err: "no Leaf release found"},
+		{name: "unknown tag", version: "1.21.4", build: "ver-9", err: "release ver-9"},
+		{name: "digest mismatch", version: "1.21.1", err: "checksum mismatch"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			downloader := &GitHubReleaseDownloader{Name: "Leaf", Repo: "Winds-Studio/Leaf", BaseURLs: []string{server.URL}}
+
+			path, err := downloader.Download(tt.version, tt.build, dir)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("err = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if data, _ := os.ReadFile(path); string(data) != tt.want {
+				t.Errorf("server.jar = %q, want %q", data, tt.want)
+			}
+		})
+	}
+}
+
+func TestPufferfishDownloader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		type artifact struct {
+			FileName     string `json:"fileName"`
+			RelativePath string `json:"relativePath"`
+		}
+		builds := map[string]any{
+			"/job/Pufferfish-1.21/lastSuccessfulBuild/api/json": map[string]any{"number": 30, "artifacts": []artifact{
+				{"build.log", "build.log"},
+				{"pufferfish-paperclip-1.21.3-R0.1-SNAPSHOT-mojmap.jar", "build/libs/pufferfish-paperclip-1.21.3-R0.1-SNAPSHOT-mojmap.jar"},
+			}},
+			"/job/Pufferfish-1.21/12/api/json": map[string]any{"number": 12, "artifacts": []artifact{
+				{"pufferfish-paperclip-1.21.1-R0.1-SNAPSHOT-mojmap.jar", "build/libs/pufferfish-paperclip-1.21.1-R0.1-SNAPSHOT-mojmap.jar"},
+			}},
+			"/job/Pufferfish-1.20/lastSuccessfulBuild/api/json": map[string]any{"number": 5, "artifacts": []artifact{{"build.log", "build.log"}}},
+		}
+		if build, ok := builds[r.URL.Path]; ok {
+			json.NewEncoder(w).Encode(build)
+			return
+		}
+		if strings.Contains(r.URL.Path, "/artifact/") {
+			w.Write([]byte(r.URL.Path))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		version string
+		build   string
+		want    string // path server.jar was downloaded from
+		err     string
+	}{
+		{name: "newest build", version: "1.21.3", want: "/job/Pufferfish-1.21/30/artifact/build/libs/pufferfish-paperclip-1.21.3-R0.1-SNAPSHOT-mojmap.jar"},
+		{name: "pinned build", version: "1.21.1", build: "12", want: "/job/Pufferfish-1.21/12/artifact/build/libs/pufferfish-paperclip-1.21.1-R0.1-SNAPSHOT-mojmap.jar"},
+		{name: "older patch", version: "1.21.1", err: "Pufferfish build 30 is not for Minecraft 1.21.1 (found pufferfish-paperclip-1.21.3"},
+		{name: "minor version isn't the patch built", version: "1.21", err: "is not for Minecraft 1.21 "},
+		{name: "no jar", version: "1.20.4", err: "Pufferfish build 5 has no server jar"},
+		{name: "no job", version: "1.19.4", err: "error Pufferfish Jenkins"},
+		{name: "invalid version", version: "1", err: "invalid Minecraft version"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			downloader := &PufferfishDownloader{BaseURLs: []string{server.URL}}
+			path, err := downloader.Download(tt.version, tt.build, t.TempDir())
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("err = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if data, _ := os.ReadFile(path); string(data) != tt.want {
+				t.Errorf("server.jar = %q, want %q", data, tt.want)
+			}
+		})
+	}
+}
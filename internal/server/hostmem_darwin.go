@@ -0,0 +1,17 @@
+package server
+
+import (
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// TotalMemory returns the machine's physical memory in bytes, or 0 if unknown
+func TotalMemory() uint64 {
+	out, err := exec.Command("sysctl", "-n", "hw.memsize").Output()
+	if err != nil {
+		return 0
+	}
+	total, _ := strconv.ParseUint(strings.TrimSpace(string(out)), 10, 64)
+	return total
+}
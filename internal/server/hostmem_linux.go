@@ -0,0 +1,29 @@
+package server
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// TotalMemory returns the machine's physical memory in bytes, or 0 if unknown
+func TotalMemory() uint64 {
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// MemTotal:       16318256 kB
+		if rest, ok := strings.CutPrefix(scanner.Text(), "MemTotal:"); ok {
+			var kb uint64
+			if _, err := fmt.Sscanf(strings.TrimSpace(rest), "%d kB", &kb); err == nil {
+				return kb << 10
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin && !windows
+
+package server
+
+// TotalMemory returns the machine's physical memory in bytes, or 0 if unknown
+func TotalMemory() uint64 {
+	return 0
+}
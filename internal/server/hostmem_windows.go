@@ -0,0 +1,31 @@
+package server
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// memoryStatusEx is MEMORYSTATUSEX from the Windows API
+type memoryStatusEx struct {
+	Length               uint32
+	MemoryLoad           uint32
+	TotalPhys            uint64
+	AvailPhys            uint64
+	TotalPageFile        uint64
+	AvailPageFile        uint64
+	TotalVirtual         uint64
+	AvailVirtual         uint64
+	AvailExtendedVirtual uint64
+}
+
+// TotalMemory returns the machine's physical memory in bytes, or 0 if unknown
+func TotalMemory() uint64 {
+	status := memoryStatusEx{}
+	status.Length = uint32(unsafe.Sizeof(status))
+
+	proc := syscall.NewLazyDLL("kernel32.dll").NewProc("GlobalMemoryStatusEx")
+	if ok, _, _ := proc.Call(uintptr(unsafe.Pointer(&status))); ok == 0 {
+		return 0
+	}
+	return status.TotalPhys
+}
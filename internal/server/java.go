@@ -0,0 +1,483 @@
+package server
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/storrealbac/mpm/internal/utils"
+
+	"github.com/storrealbac/mpm/internal/ui"
+)
+
+// AdoptiumAPIURL is the default Adoptium API used to download Temurin JDKs
+const AdoptiumAPIURL = "https://api.adoptium.net/v3"
+
+// RequiredJava returns the Java major version a Minecraft version needs,
+// or 0 when it isn't known (snapshots, proxies' own versions)
+func RequiredJava(mcVersion string) int {
+	if !releaseVersion.MatchString(mcVersion) {
+		return 0
+	}
+	switch {
+	case utils.CompareVersions(mcVersion, "1.17") < 0:
+		return 8
+	case utils.CompareVersions(mcVersion, "1.18") < 0:
+		return 16
+	case utils.CompareVersions(mcVersion, "1.20.5") < 0:
+		return 17
+	case utils.CompareVersions(mcVersion, "26.1") < 0:
+		return 21
+	default:
+		return 25
+	}
+}
+
+// releaseVersion matches release versions such as 1.20.4 and 26.1, not snapshots like 24w10a
+var releaseVersion = regexp.MustCompile(`^\d+\.\d+(\.\d+)?$`)
+
+// JavaInstall is a Java runtime found on this machine
+type JavaInstall struct {
+	Path    string // java executable
+	Version string // full version, e.g. 21.0.5 or 1.8.0_392
+	Major   int    // 8, 17, 21...
+	Source  string // MPM_JAVA, JAVA_HOME, PATH, mpm or system
+}
+
+// javaVersionLine matches the version in `java -version` output:
+// openjdk version "21.0.5" 2024-10-15, java version "1.8.0_392"
+var javaVersionLine = regexp.MustCompile(`version "([^"]+)"`)
+
+// ProbeJava runs `java -version` and parses the version it reports
+func ProbeJava(path string) (*JavaInstall, error) {
+	out, err := exec.Command(path, "-version").CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("%s -version: %w", path, err)
+	}
+
+	m := javaVersionLine.FindSubmatch(out)
+	if m == nil {
+		return nil, fmt.Errorf("could not read the version of %s", path)
+	}
+	version := string(m[1])
+	return &JavaInstall{Path: path, Version: version, Major: javaMajor(version)}, nil
+}
+
+// javaMajor returns the major version: 1.8.0_392 is 8, 21.0.5 is 21
+func javaMajor(version string) int {
+	version = strings.TrimPrefix(version, "1.")
+	end := 0
+	for end < len(version) && isDigit(version[end]) {
+		end++
+	}
+	major, _ := strconv.Atoi(version[:end])
+	return major
+}
+
+// DetectJava finds the Java runtimes on this machine: $MPM_JAVA, $JAVA_HOME,
+// java on the PATH, the JDKs mpm installed and the usual system locations.
+// Each executable is listed once, in that order.
+func DetectJava() []JavaInstall {
+	type candidate struct{ path, source string }
+	var candidates []candidate
+
+	if java := os.Getenv("MPM_JAVA"); java != "" {
+		candidates = append(candidates, candidate{java, "MPM_JAVA"})
+	}
+	if home := os.Getenv("JAVA_HOME"); home != "" {
+		candidates = append(candidates, candidate{filepath.Join(home, "bin", javaExecutable()), "JAVA_HOME"})
+	}
+	if path, err := exec.LookPath("java"); err == nil {
+		candidates = append(candidates, candidate{path, "PATH"})
+	}
+	if dir, err := JDKDir(); err == nil {
+		for _, java := range javaHomes(dir) {
+			candidates = append(candidates, candidate{java, "mpm"})
+		}
+	}
+	for _, dir := range systemJavaDirs() {
+		for _, java := range javaHomes(dir) {
+			candidates = append(candidates, candidate{java, "system"})
+		}
+	}
+
+	seen := map[string]bool{}
+	var installs []JavaInstall
+	for _, c := range candidates {
+		key := c.path
+		if resolved, err := filepath.EvalSymlinks(c.path); err == nil {
+			key = resolved
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		install, err := ProbeJava(c.path)
+		if err != nil {
+			continue
+		}
+		install.Source = c.source
+		installs = append(installs, *install)
+	}
+	return installs
+}
+
+// SelectJava picks the runtime for a server that needs Java required (0 if unknown).
+// $MPM_JAVA always wins; otherwise the same major version is preferred, then the
+// closest newer one, then the default java. It returns nil if no Java was found.
+func SelectJava(installs []JavaInstall, required int) *JavaInstall {
+	if len(installs) == 0 {
+		return nil
+	}
+	if installs[0].Source == "MPM_JAVA" || required == 0 {
+		return &installs[0]
+	}
+
+	var best *JavaInstall
+	for i := range installs {
+		install := &installs[i]
+		if install.Major == required {
+			return install
+		}
+		if install.Major > required && (best == nil || install.Major < best.Major) {
+			best = install
+		}
+	}
+	if best != nil {
+		return best
+	}
+	return &installs[0]
+}
+
+// javaHomes returns the java executables of the JDKs directly inside dir
+func javaHomes(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var found []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if java := javaInHome(filepath.Join(dir, entry.Name())); java != "" {
+			found = append(found, java)
+		}
+	}
+	return found
+}
+
+// javaInHome returns the java executable of a JDK directory ("" if there is none).
+// macOS JDKs keep it under Contents/Home.
+func javaInHome(home string) string {
+	for _, java := range []string{
+		filepath.Join(home, "bin", javaExecutable()),
+		filepath.Join(home, "Contents", "Home", "bin", javaExecutable()),
+	} {
+		if fileExists(java) {
+			return java
+		}
+	}
+	return ""
+}
+
+func javaExecutable() string {
+	if runtime.GOOS == "windows" {
+		return "java.exe"
+	}
+	return "java"
+}
+
+// systemJavaDirs are the directories JDK packages install into
+func systemJavaDirs() []string {
+	switch runtime.GOOS {
+	case "darwin":
+		return []string{"/Library/Java/JavaVirtualMachines"}
+	case "windows":
+		return []string{
+			`C:\Program Files\Eclipse Adoptium`,
+			`C:\Program Files\Java`,
+			`C:\Program Files\Microsoft`,
+		}
+	default:
+		return []string{"/usr/lib/jvm", "/usr/java", "/opt/java"}
+	}
+}
+
+// JDKDir is where mpm installs JDKs (jdks/ in the cache directory)
+func JDKDir() (string, error) {
+	dir, err := CacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "jdks"), nil
+}
+
+// --- Adoptium Implementation ---
+
+// adoptiumAsset is a release in the Adoptium assets/latest API
+type adoptiumAsset struct {
+	ReleaseName string `json:"release_name"` // jdk-21.0.5+11
+	Binary      struct {
+		Package struct {
+			Name     string `json:"name"`
+			Link     string `json:"link"`
+			Checksum string `json:"checksum"` // sha256
+		} `json:"package"`
+	} `json:"binary"`
+}
+
+// InstallJDK downloads the latest Temurin JDK of a major version for this OS and
+// architecture into JDKDir, and returns it. A JDK that is already installed is reused.
+func InstallJDK(major int, baseURLs []string) (*JavaInstall, error) {
+	osName, arch, err := adoptiumPlatform()
+	if err != nil {
+		return nil, err
+	}
+
+	var assets []adoptiumAsset
+	path := fmt.Sprintf("/assets/latest/%d/hotspot?os=%s&architecture=%s&image_type=jdk&vendor=eclipse", major, osName, arch)
+	if err := getJSONFromMirrors(baseURLs, path, &assets); err != nil {
+		return nil, fmt.Errorf("error Adoptium API: %w", err)
+	}
+	if len(assets) == 0 {
+		return nil, fmt.Errorf("Adoptium has no Java %d JDK for %s/%s", major, osName, arch)
+	}
+	asset := assets[0]
+
+	jdkDir, err := JDKDir()
+	if err != nil {
+		return nil, err
+	}
+	home := filepath.Join(jdkDir, asset.ReleaseName)
+	if java := javaInHome(home); java != "" {
+		fmt.Fprintf(ui.Out, "%s is already installed\n", asset.ReleaseName)
+		install, err := ProbeJava(java)
+		if err != nil {
+			return nil, err
+		}
+		install.Source = "mpm"
+		return install, nil
+	}
+
+	// A JDK runs everything mpm starts, so it is never installed unverified
+	if asset.Binary.Package.Checksum == "" {
+		return nil, fmt.Errorf("no checksum published for %s", asset.Binary.Package.Name)
+	}
+
+	if err := os.MkdirAll(jdkDir, 0755); err != nil {
+		return nil, err
+	}
+	archive, err := downloadFile(asset.Binary.Package.Link, jdkDir, asset.Binary.Package.Name)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(archive)
+
+	if sum, err := hashFile(archive, sha256.New); err != nil {
+		return nil, err
+	} else if !strings.EqualFold(sum, asset.Binary.Package.Checksum) {
+		return nil, fmt.Errorf("checksum mismatch for %s: expected %s, got %s", asset.Binary.Package.Name, asset.Binary.Package.Checksum, sum)
+	}
+
+	// Extract next to the final directory so a failed extraction leaves nothing behind
+	tmpHome, err := os.MkdirTemp(jdkDir, "extract-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpHome)
+
+	fmt.Fprintf(ui.Out, "Extracting %s...\n", asset.Binary.Package.Name)
+	if strings.HasSuffix(archive, ".zip") {
+		err = extractZip(archive, tmpHome)
+	} else {
+		err = extractTarGz(archive, tmpHome)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error extracting %s: %w", asset.Binary.Package.Name, err)
+	}
+	if err := os.Chmod(tmpHome, 0755); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmpHome, home); err != nil {
+		return nil, err
+	}
+
+	java := javaInHome(home)
+	if java == "" {
+		return nil, fmt.Errorf("%s has no java executable", asset.Binary.Package.Name)
+	}
+	install, err := ProbeJava(java)
+	if err != nil {
+		return nil, err
+	}
+	install.Source = "mpm"
+	return install, nil
+}
+
+// adoptiumPlatform maps GOOS/GOARCH to Adoptium's os and architecture names
+func adoptiumPlatform() (string, string, error) {
+	osNames := map[string]string{"linux": "linux", "darwin": "mac", "windows": "windows"}
+	arches := map[string]string{"amd64": "x64", "arm64": "aarch64", "386": "x32", "arm": "arm", "ppc64le": "ppc64le", "s390x": "s390x"}
+
+	osName, ok := osNames[runtime.GOOS]
+	if !ok {
+		return "", "", fmt.Errorf("Adoptium has no JDKs for %s", runtime.GOOS)
+	}
+	arch, ok := arches[runtime.GOARCH]
+	if !ok {
+		return "", "", fmt.Errorf("Adoptium has no JDKs for %s", runtime.GOARCH)
+	}
+	return osName, arch, nil
+}
+
+// archivePath maps an archive entry to a path in dest, dropping the archive's
+// top-level directory (jdk-21.0.5+11/bin/java becomes dest/bin/java)
+func archivePath(dest, name string) (string, bool) {
+	name = filepath.ToSlash(name)
+	_, rest, found := strings.Cut(strings.TrimPrefix(name, "./"), "/")
+	if !found || rest == "" {
+		return "", false
+	}
+
+	target := filepath.Join(dest, filepath.FromSlash(rest))
+	if !strings.HasPrefix(target, filepath.Clean(dest)+string(os.PathSeparator)) {
+		return "", false // Entries must stay inside dest
+	}
+	return target, true
+}
+
+// linkInside reports whether a symlink at target pointing to linkname stays inside dest
+func linkInside(dest, target, linkname string) bool {
+	if linkname == "" || filepath.IsAbs(linkname) || filepath.VolumeName(linkname) != "" {
+		return false
+	}
+	resolved := filepath.Join(filepath.Dir(target), filepath.FromSlash(linkname))
+	dest = filepath.Clean(dest)
+	return resolved == dest || strings.HasPrefix(resolved, dest+string(os.PathSeparator))
+}
+
+func extractTarGz(archive, dest string) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target, ok := archivePath(dest, header.Name)
+		if !ok {
+			continue
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(target, 0755)
+		case tar.TypeReg:
+			err = writeArchiveFile(target, tr, os.FileMode(header.Mode).Perm())
+		case tar.TypeSymlink:
+			// A link out of dest would let later entries be written through it
+			if !linkInside(dest, target, header.Linkname) {
+				return fmt.Errorf("archive entry %s links outside the JDK directory (%s)", header.Name, header.Linkname)
+			}
+			if err = os.MkdirAll(filepath.Dir(target), 0755); err == nil {
+				err = os.Symlink(header.Linkname, target)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func extractZip(archive, dest string) error {
+	zr, err := zip.OpenReader(archive)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	for _, file := range zr.File {
+		target, ok := archivePath(dest, file.Name)
+		if !ok {
+			continue
+		}
+		if file.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+
+		rc, err := file.Open()
+		if err != nil {
+			return err
+		}
+		err = writeArchiveFile(target, rc, file.Mode().Perm())
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeArchiveFile(target string, r io.Reader, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm|0200)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// InstalledJDKs lists the JDKs in JDKDir, newest major version first
+func InstalledJDKs() []JavaInstall {
+	dir, err := JDKDir()
+	if err != nil {
+		return nil
+	}
+
+	var installs []JavaInstall
+	for _, java := range javaHomes(dir) {
+		if install, err := ProbeJava(java); err == nil {
+			install.Source = "mpm"
+			installs = append(installs, *install)
+		}
+	}
+	sort.Slice(installs, func(i, j int) bool { return installs[i].Major > installs[j].Major })
+	return installs
+}
@@ -0,0 +1,297 @@
+package server
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestRequiredJava(t *testing.T) {
+	tests := []struct {
+		mcVersion string
+		want      int
+	}{
+		{"1.8.8", 8},
+		{"1.16.5", 8},
+		{"1.17", 16},
+		{"1.17.1", 16},
+		{"1.18", 17},
+		{"1.20.4", 17},
+		{"1.20.5", 21},
+		{"1.21.4", 21},
+		{"26.1", 25},
+		{"24w10a", 0},
+		{"1.21-pre1", 0},
+		{"3.3.0-SNAPSHOT", 0},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if got := RequiredJava(tt.mcVersion); got != tt.want {
+			t.Errorf("RequiredJava(%q) = %d, want %d", tt.mcVersion, got, tt.want)
+		}
+	}
+}
+
+func TestJavaMajor(t *testing.T) {
+	tests := []struct {
+		version string
+		want    int
+	}{
+		{"1.8.0_392", 8},
+		{"21.0.5", 21},
+		{"17", 17},
+		{"25-ea", 25},
+		{"11.0.2+9", 11},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if got := javaMajor(tt.version); got != tt.want {
+			t.Errorf("javaMajor(%q) = %d, want %d", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestSelectJava(t *testing.T) {
+	java8 := JavaInstall{Path: "/jdk8/bin/java", Major: 8, Source: "PATH"}
+	java17 := JavaInstall{Path: "/jdk17/bin/java", Major: 17, Source: "system"}
+	java21 := JavaInstall{Path: "/jdk21/bin/java", Major: 21, Source: "mpm"}
+	java25 := JavaInstall{Path: "/jdk25/bin/java", Major: 25, Source: "system"}
+	pinned := JavaInstall{Path: "/custom/java", Major: 11, Source: "MPM_JAVA"}
+
+	tests := []struct {
+		name     string
+		installs []JavaInstall
+		required int
+		want     string
+	}{
+		{"nothing installed", nil, 21, ""},
+		{"same major", []JavaInstall{java8, java17, java21}, 17, "/jdk17/bin/java"},
+		{"closest newer", []JavaInstall{java8, java25, java21}, 17, "/jdk21/bin/java"},
+		{"only older", []JavaInstall{java8, java17}, 21, "/jdk8/bin/java"},
+		{"unknown requirement takes the default", []JavaInstall{java17, java21}, 0, "/jdk17/bin/java"},
+		{"MPM_JAVA wins", []JavaInstall{pinned, java21}, 21, "/custom/java"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SelectJava(tt.installs, tt.required)
+			path := ""
+			if got != nil {
+				path = got.Path
+			}
+			if path != tt.want {
+				t.Errorf("SelectJava() = %q, want %q", path, tt.want)
+			}
+		})
+	}
+}
+
+func TestArchivePath(t *testing.T) {
+	dest := filepath.Join(t.TempDir(), "jdk")
+	tests := []struct {
+		name   string
+		want   string
+		wantOK bool
+	}{
+		{"jdk-21.0.5+11/bin/java", filepath.Join(dest, "bin", "java"), true},
+		{"./jdk-21.0.5+11/lib/modules", filepath.Join(dest, "lib", "modules"), true},
+		{"jdk-21.0.5+11/", "", false},
+		{"jdk-21.0.5+11", "", false},
+		{"jdk-21.0.5+11/../../etc/passwd", "", false},
+		{"jdk-21.0.5+11/bin/../../../outside", "", false},
+		{"jdk-21.0.5+11/bin/../release", filepath.Join(dest, "release"), true},
+	}
+	for _, tt := range tests {
+		got, ok := archivePath(dest, tt.name)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("archivePath(%q) = %q, %v, want %q, %v", tt.name, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestLinkInside(t *testing.T) {
+	dest := filepath.Join(t.TempDir(), "jdk")
+	tests := []struct {
+		target   string
+		linkname string
+		want     bool
+	}{
+		{"bin/java", "../lib/java", true},
+		{"lib/libjli.so", "server/libjli.so", true},
+		{"legal/java.base", "..", true},
+		{"legal/java.base", "../..", false},
+		{"bin/java", "/usr/bin/java", false},
+		{"bin/java", "../../outside", false},
+		{"bin/java", "", false},
+	}
+	for _, tt := range tests {
+		target := filepath.Join(dest, filepath.FromSlash(tt.target))
+		if got := linkInside(dest, target, tt.linkname); got != tt.want {
+			t.Errorf("linkInside(%q -> %q) = %v, want %v", tt.target, tt.linkname, got, tt.want)
+		}
+	}
+}
+
+// tarEntry is a file, directory or symlink in a test archive
+type tarEntry struct {
+	name     string
+	typeflag byte
+	body     string
+	linkname string
+}
+
+func writeTarGz(t *testing.T, entries []tarEntry) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "jdk.tar.gz")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		header := &tar.Header{Name: e.name, Typeflag: e.typeflag, Linkname: e.linkname, Mode: 0755, Size: int64(len(e.body))}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(e.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestExtractTarGz(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need privileges on Windows")
+	}
+
+	tests := []struct {
+		name    string
+		entries []tarEntry
+		files   map[string]string // path in dest -> content read through it
+		err     string
+	}{
+		{
+			name: "files and an inner symlink",
+			entries: []tarEntry{
+				{name: "jdk/", typeflag: tar.TypeDir},
+				{name: "jdk/lib/java", typeflag: tar.TypeReg, body: "binary"},
+				{name: "jdk/bin/java", typeflag: tar.TypeSymlink, linkname: "../lib/java"},
+			},
+			files: map[string]string{"lib/java": "binary", "bin/java": "binary"},
+		},
+		{
+			name: "absolute symlink",
+			entries: []tarEntry{
+				{name: "jdk/bin/java", typeflag: tar.TypeSymlink, linkname: "/etc/passwd"},
+			},
+			err: "links outside the JDK directory",
+		},
+		{
+			name: "symlink escaping dest, then a write through it",
+			entries: []tarEntry{
+				{name: "jdk/escape", typeflag: tar.TypeSymlink, linkname: "../.."},
+				{name: "jdk/escape/pwned", typeflag: tar.TypeReg, body: "x"},
+			},
+			err: "links outside the JDK directory",
+		},
+		{
+			name: "path traversal entries are skipped",
+			entries: []tarEntry{
+				{name: "jdk/../../pwned", typeflag: tar.TypeReg, body: "x"},
+				{name: "jdk/release", typeflag: tar.TypeReg, body: "JAVA_VERSION=21"},
+			},
+			files: map[string]string{"release": "JAVA_VERSION=21"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dest := filepath.Join(t.TempDir(), "a", "b", "jdk")
+			err := extractTarGz(writeTarGz(t, tt.entries), dest)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("err = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for name, want := range tt.files {
+				data, err := os.ReadFile(filepath.Join(dest, name))
+				if err != nil {
+					t.Errorf("%s: %v", name, err)
+				} else if string(data) != want {
+					t.Errorf("%s = %q, want %q", name, data, want)
+				}
+			}
+			if _, err := os.Stat(filepath.Join(dest, "..", "..", "pwned")); err == nil {
+				t.Error("an entry was written outside dest")
+			}
+		})
+	}
+}
+
+func TestInstallJDKChecksum(t *testing.T) {
+	archive := "not really a JDK"
+	sum := sha256.Sum256([]byte(archive))
+
+	tests := []struct {
+		name     string
+		checksum string
+		err      string
+	}{
+		{"no checksum published", "", "no checksum published"},
+		{"checksum mismatch", strings.Repeat("0", 64), "checksum mismatch"},
+		{"valid checksum reaches extraction", hex.EncodeToString(sum[:]), "error extracting"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("MPM_CACHE_DIR", t.TempDir())
+			downloads := 0
+
+			var server *httptest.Server
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.HasPrefix(r.URL.Path, "/download/") {
+					downloads++
+					w.Write([]byte(archive))
+					return
+				}
+				var asset adoptiumAsset
+				asset.ReleaseName = "jdk-21.0.5+11"
+				asset.Binary.Package.Name = "OpenJDK21U-jdk.tar.gz"
+				asset.Binary.Package.Link = server.URL + "/download/OpenJDK21U-jdk.tar.gz"
+				asset.Binary.Package.Checksum = tt.checksum
+				json.NewEncoder(w).Encode([]adoptiumAsset{asset})
+			}))
+			defer server.Close()
+
+			_, err := InstallJDK(21, []string{server.URL})
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("err = %v, want %q", err, tt.err)
+			}
+			if tt.checksum == "" && downloads > 0 {
+				t.Error("the JDK was downloaded without a checksum to verify it")
+			}
+		})
+	}
+}
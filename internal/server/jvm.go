@@ -0,0 +1,119 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// aikarsFlags are Aikar's G1 flags for Minecraft servers (https://docs.papermc.io/paper/aikars-flags)
+var aikarsFlags = []string{
+	"-XX:+UseG1GC",
+	"-XX:+ParallelRefProcEnabled",
+	"-XX:MaxGCPauseMillis=200",
+	"-XX:+UnlockExperimentalVMOptions",
+	"-XX:+DisableExplicitGC",
+	"-XX:+AlwaysPreTouch",
+	"-XX:G1NewSizePercent=30",
+	"-XX:G1MaxNewSizePercent=40",
+	"-XX:G1HeapRegionSize=8M",
+	"-XX:G1ReservePercent=20",
+	"-XX:G1HeapWastePercent=5",
+	"-XX:G1MixedGCCountTarget=4",
+	"-XX:InitiatingHeapOccupancyPercent=15",
+	"-XX:G1MixedGCLiveThresholdPercent=90",
+	"-XX:G1RSetUpdatingPauseIntervalPercent=5",
+	"-XX:SurvivorRatio=32",
+	"-XX:+PerfDisableSharedMem",
+	"-XX:MaxTenuringThreshold=1",
+	"-Dusing.aikars.flags=https://mcflags.emc.gs",
+	"-Daikars.new.flags=true",
+}
+
+// aikarsLargeHeap replaces some of Aikar's flags for heaps over 12G
+var aikarsLargeHeap = map[string]string{
+	"-XX:G1NewSizePercent=":               "40",
+	"-XX:G1MaxNewSizePercent=":            "50",
+	"-XX:G1HeapRegionSize=":               "16M",
+	"-XX:G1ReservePercent=":               "15",
+	"-XX:InitiatingHeapOccupancyPercent=": "20",
+}
+
+// velocityFlags are the flags recommended for proxies (https://docs.papermc.io/velocity/tuning)
+var velocityFlags = []string{
+	"-XX:+UseG1GC",
+	"-XX:G1HeapRegionSize=4M",
+	"-XX:+UnlockExperimentalVMOptions",
+	"-XX:+ParallelRefProcEnabled",
+	"-XX:+AlwaysPreTouch",
+	"-XX:MaxInlineLevel=15",
+}
+
+// JVMFlagPresets lists the presets accepted by server.jvm_flags
+var JVMFlagPresets = []string{"aikar", "velocity", "none"}
+
+// JVMFlags returns the JVM flags of a preset for a heap of maxMemory bytes (0 if unknown)
+func JVMFlags(preset string, maxMemory uint64) ([]string, error) {
+	switch strings.ToLower(preset) {
+	case "", "none":
+		return nil, nil
+	case "aikar", "aikars":
+		if maxMemory <= 12<<30 {
+			return aikarsFlags, nil
+		}
+		flags := make([]string, len(aikarsFlags))
+		for i, flag := range aikarsFlags {
+			flags[i] = flag
+			for prefix, value := range aikarsLargeHeap {
+				if strings.HasPrefix(flag, prefix) {
+					flags[i] = prefix + value
+				}
+			}
+		}
+		return flags, nil
+	case "velocity", "proxy":
+		return velocityFlags, nil
+	}
+	return nil, fmt.Errorf("unknown jvm_flags preset %q (use %s)", preset, strings.Join(JVMFlagPresets, ", "))
+}
+
+// memorySize matches JVM memory sizes: 512M, 4G, 2048m, 1048576
+var memorySize = regexp.MustCompile(`^(\d+)([kKmMgGtT]?)$`)
+
+// ParseMemory converts a JVM memory size (as given to -Xms and -Xmx) to bytes
+func ParseMemory(size string) (uint64, error) {
+	m := memorySize.FindStringSubmatch(strings.TrimSpace(size))
+	if m == nil {
+		return 0, fmt.Errorf("invalid memory size %q (use e.g. 512M or 4G)", size)
+	}
+	n, err := strconv.ParseUint(m[1], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid memory size %q: %w", size, err)
+	}
+
+	if n == 0 {
+		return 0, fmt.Errorf("invalid memory size %q: must be more than 0", size)
+	}
+
+	shift := map[string]uint{"": 0, "k": 10, "m": 20, "g": 30, "t": 40}[strings.ToLower(m[2])]
+	if n > math.MaxUint64>>shift {
+		return 0, fmt.Errorf("invalid memory size %q: too large", size)
+	}
+	return n << shift, nil
+}
+
+// FormatMemory renders a size in bytes for messages (4G, 15.6G, 512M)
+func FormatMemory(bytes uint64) string {
+	switch {
+	case bytes >= 1<<30 && bytes%(1<<30) == 0:
+		return fmt.Sprintf("%dG", bytes>>30)
+	case bytes >= 1<<30:
+		return fmt.Sprintf("%.1fG", float64(bytes)/(1<<30))
+	case bytes >= 1<<20:
+		return fmt.Sprintf("%dM", bytes>>20)
+	default:
+		return fmt.Sprintf("%dK", bytes>>10)
+	}
+}
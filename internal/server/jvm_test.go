@@ -0,0 +1,139 @@
+package server
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseMemory(t *testing.T) {
+	tests := []struct {
+		size string
+		want uint64
+		err  string
+	}{
+		{"512M", 512 << 20, ""},
+		{"4G", 4 << 30, ""},
+		{"4g", 4 << 30, ""},
+		{"2048m", 2048 << 20, ""},
+		{"1T", 1 << 40, ""},
+		{"65536k", 64 << 20, ""},
+		{"1048576", 1 << 20, ""},
+		{" 3G ", 3 << 30, ""},
+		{"0G", 0, "must be more than 0"},
+		{"0", 0, "must be more than 0"},
+		{"99999999999T", 0, "too large"},
+		{"16777216T", 0, "too large"},
+		{"16777215T", 16777215 << 40, ""},
+		{"99999999999999999999", 0, "invalid memory size"},
+		{"4GB", 0, "use e.g. 512M or 4G"},
+		{"-1G", 0, "use e.g. 512M or 4G"},
+		{"1.5G", 0, "use e.g. 512M or 4G"},
+		{"", 0, "use e.g. 512M or 4G"},
+	}
+	for _, tt := range tests {
+		got, err := ParseMemory(tt.size)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("ParseMemory(%q) error = %v, want %q", tt.size, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseMemory(%q) = %d, %v, want %d", tt.size, got, err, tt.want)
+		}
+	}
+}
+
+func TestFormatMemory(t *testing.T) {
+	tests := []struct {
+		bytes uint64
+		want  string
+	}{
+		{4 << 30, "4G"},
+		{15<<30 + 600<<20, "15.6G"},
+		{512 << 20, "512M"},
+		{1536 << 20, "1.5G"},
+		{64 << 10, "64K"},
+	}
+	for _, tt := range tests {
+		if got := FormatMemory(tt.bytes); got != tt.want {
+			t.Errorf("FormatMemory(%d) = %q, want %q", tt.bytes, got, tt.want)
+		}
+	}
+}
+
+func TestJVMFlags(t *testing.T) {
+	tests := []struct {
+		name      string
+		preset    string
+		maxMemory uint64
+		contains  []string // flags that must be present
+		excludes  []string // flags that must be absent
+		empty     bool
+		err       bool
+	}{
+		{name: "no preset", preset: "", empty: true},
+		{name: "none", preset: "none", maxMemory: 4 << 30, empty: true},
+		{
+			name: "aikar", preset: "aikar", maxMemory: 10 << 30,
+			contains: []string{"-XX:+UseG1GC", "-XX:G1NewSizePercent=30", "-XX:G1HeapRegionSize=8M", "-XX:InitiatingHeapOccupancyPercent=15"},
+		},
+		{
+			name: "aikar at 12G keeps the small heap flags", preset: "Aikars", maxMemory: 12 << 30,
+			contains: []string{"-XX:G1NewSizePercent=30"},
+		},
+		{
+			name: "aikar over 12G", preset: "aikar", maxMemory: 16 << 30,
+			contains: []string{"-XX:G1NewSizePercent=40", "-XX:G1MaxNewSizePercent=50", "-XX:G1HeapRegionSize=16M", "-XX:G1ReservePercent=15", "-XX:InitiatingHeapOccupancyPercent=20", "-XX:+AlwaysPreTouch"},
+			excludes: []string{"-XX:G1NewSizePercent=30", "-XX:G1HeapRegionSize=8M"},
+		},
+		{
+			name: "aikar with unknown memory", preset: "aikar",
+			contains: []string{"-XX:G1HeapRegionSize=8M"},
+		},
+		{
+			name: "velocity", preset: "proxy", maxMemory: 1 << 30,
+			contains: []string{"-XX:G1HeapRegionSize=4M", "-XX:MaxInlineLevel=15"},
+		},
+		{name: "unknown preset", preset: "zgc", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags, err := JVMFlags(tt.preset, tt.maxMemory)
+			if (err != nil) != tt.err {
+				t.Fatalf("err = %v, want error %v", err, tt.err)
+			}
+			if tt.empty && len(flags) != 0 {
+				t.Errorf("flags = %v, want none", flags)
+			}
+			for _, flag := range tt.contains {
+				if !containsFlag(flags, flag) {
+					t.Errorf("flags = %v, missing %s", flags, flag)
+				}
+			}
+			for _, flag := range tt.excludes {
+				if containsFlag(flags, flag) {
+					t.Errorf("flags = %v, should not have %s", flags, flag)
+				}
+			}
+		})
+	}
+
+	// The large heap variant must not change the shared preset
+	before := append([]string(nil), aikarsFlags...)
+	JVMFlags("aikar", 32<<30)
+	if !reflect.DeepEqual(aikarsFlags, before) {
+		t.Error("JVMFlags modified aikarsFlags")
+	}
+}
+
+func containsFlag(flags []string, flag string) bool {
+	for _, f := range flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,391 @@
+package server
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/storrealbac/mpm/internal/utils"
+
+	"github.com/storrealbac/mpm/internal/ui"
+)
+
+// Default API endpoints for mod loader servers
+const (
+	FabricMetaURL    = "https://meta.fabricmc.net/v2"
+	QuiltMavenURL    = "https://maven.quiltmc.org/repository/release"
+	ForgeMavenURL    = "https://maven.minecraftforge.net"
+	NeoForgeMavenURL = "https://maven.neoforged.net/releases"
+)
+
+// --- Fabric Implementation ---
+
+// FabricDownloader downloads Fabric's executable server launcher from the meta API.
+// build is the loader version; the launcher fetches the vanilla server itself on first start.
+type FabricDownloader struct {
+	BaseURLs []string // Fabric meta API mirrors, tried in order
+}
+
+func (f *FabricDownloader) Download(version, build string, outputDir string) (string, error) {
+	loader := build
+	if loader == "" || loader == "latest" {
+		var loaders []struct {
+			Loader struct {
+				Version string `json:"version"`
+				Stable  bool   `json:"stable"`
+			} `json:"loader"`
+		}
+		if err := getJSONFromMirrors(f.BaseURLs, fmt.Sprintf("/versions/loader/%s", version), &loaders); err != nil {
+			return "", fmt.Errorf("error Fabric meta API: %w", err)
+		}
+		for _, l := range loaders {
+			if l.Loader.Stable {
+				loader = l.Loader.Version
+				break
+			}
+		}
+		if loader == "" || loader == "latest" {
+			return "", fmt.Errorf("no stable Fabric loader found for Minecraft %s", version)
+		}
+	}
+
+	var installers []struct {
+		Version string `json:"version"`
+		Stable  bool   `json:"stable"`
+	}
+	if err := getJSONFromMirrors(f.BaseURLs, "/versions/installer", &installers); err != nil {
+		return "", fmt.Errorf("error Fabric meta API: %w", err)
+	}
+	installer := ""
+	for _, i := range installers {
+		if i.Stable {
+			installer = i.Version
+			break
+		}
+	}
+	if installer == "" {
+		return "", fmt.Errorf("no stable Fabric installer found")
+	}
+
+	// {base}/versions/loader/{game}/{loader}/{installer}/server/jar
+	path := fmt.Sprintf("/versions/loader/%s/%s/%s/server/jar", version, loader, installer)
+	return downloadFromMirrors(f.BaseURLs, path, outputDir, "server.jar")
+}
+
+// --- Quilt Implementation ---
+
+// QuiltDownloader runs the Quilt installer, which creates quilt-server-launch.jar
+// next to the vanilla server.jar. build is the loader version.
+type QuiltDownloader struct {
+	BaseURLs []string // Quilt Maven repository mirrors, tried in order
+	Java     string   // java executable that runs the installer (default: JavaBinary)
+}
+
+func (q *QuiltDownloader) Download(version, build string, outputDir string) (string, error) {
+	loader := build
+	if loader == "" || loader == "latest" {
+		versions, err := getMavenVersions(q.BaseURLs, "org/quiltmc/quilt-loader")
+		if err != nil {
+			return "", fmt.Errorf("error Quilt Maven: %w", err)
+		}
+		loader = latestVersion(versions, "", true)
+		if loader == "" {
+			return "", fmt.Errorf("no Quilt loader release found")
+		}
+	}
+
+	installers, err := getMavenVersions(q.BaseURLs, "org/quiltmc/quilt-installer")
+	if err != nil {
+		return "", fmt.Errorf("error Quilt Maven: %w", err)
+	}
+	installer := latestVersion(installers, "", true)
+	if installer == "" {
+		return "", fmt.Errorf("no Quilt installer release found")
+	}
+
+	path := fmt.Sprintf("/org/quiltmc/quilt-installer/%s/quilt-installer-%s.jar", installer, installer)
+	err = runInstaller(q.BaseURLs, q.Java, path, outputDir,
+		"install", "server", version, loader, "--download-server", "--install-dir=.")
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(outputDir, "quilt-server-launch.jar"), nil
+}
+
+// --- Forge Implementation ---
+
+// ForgeDownloader runs the Forge installer. Since 1.17 Forge has no single server jar
+// and is started with the run.sh/run.bat scripts the installer generates.
+// build is the Forge version (e.g. 47.2.0) or latest.
+type ForgeDownloader struct {
+	BaseURLs []string // Forge Maven repository mirrors, tried in order
+	Java     string   // java executable that runs the installer (default: JavaBinary)
+}
+
+func (f *ForgeDownloader) Download(version, build string, outputDir string) (string, error) {
+	full := build
+	if build == "" || build == "latest" {
+		versions, err := getMavenVersions(f.BaseURLs, "net/minecraftforge/forge")
+		if err != nil {
+			return "", fmt.Errorf("error Forge Maven: %w", err)
+		}
+		full = latestVersion(versions, version+"-", false)
+		if full == "" {
+			return "", fmt.Errorf("no Forge build found for Minecraft %s", version)
+		}
+	} else if !strings.Contains(build, "-") {
+		full = version + "-" + build // Maven versions are <minecraft>-<forge>
+	}
+
+	path := fmt.Sprintf("/net/minecraftforge/forge/%s/forge-%s-installer.jar", full, full)
+	if err := runInstaller(f.BaseURLs, f.Java, path, outputDir, "--installServer"); err != nil {
+		return "", err
+	}
+
+	if script := filepath.Join(outputDir, runScript()); fileExists(script) {
+		return script, nil
+	}
+
+	// Older Forge versions produce a single server jar
+	for _, name := range []string{fmt.Sprintf("forge-%s.jar", full), fmt.Sprintf("forge-%s-universal.jar", full)} {
+		if src := filepath.Join(outputDir, name); fileExists(src) {
+			dest := filepath.Join(outputDir, "server.jar")
+			if err := copyFile(src, dest); err != nil {
+				return "", err
+			}
+			return dest, nil
+		}
+	}
+
+	return "", fmt.Errorf("the Forge installer finished but no server launcher was found")
+}
+
+// --- NeoForge Implementation ---
+
+// NeoForgeDownloader runs the NeoForge installer, which generates run.sh/run.bat.
+// build is the NeoForge version (e.g. 20.4.237) or latest.
+type NeoForgeDownloader struct {
+	BaseURLs []string // NeoForge Maven repository mirrors, tried in order
+	Java     string   // java executable that runs the installer (default: JavaBinary)
+}
+
+func (n *NeoForgeDownloader) Download(version, build string, outputDir string) (string, error) {
+	neoVersion := build
+	if build == "" || build == "latest" {
+		prefix, err := neoForgePrefix(version)
+		if err != nil {
+			return "", err
+		}
+		versions, err := getMavenVersions(n.BaseURLs, "net/neoforged/neoforge")
+		if err != nil {
+			return "", fmt.Errorf("error NeoForge Maven: %w", err)
+		}
+		// Prefer a stable release, but early versions of a Minecraft release are all betas
+		neoVersion = latestVersion(versions, prefix, true)
+		if neoVersion == "" {
+			neoVersion = latestVersion(versions, prefix, false)
+		}
+		if neoVersion == "" {
+			return "", fmt.Errorf("no NeoForge build found for Minecraft %s", version)
+		}
+	}
+
+	path := fmt.Sprintf("/net/neoforged/neoforge/%s/neoforge-%s-installer.jar", neoVersion, neoVersion)
+	if err := runInstaller(n.BaseURLs, n.Java, path, outputDir, "--installServer"); err != nil {
+		return "", err
+	}
+
+	script := filepath.Join(outputDir, runScript())
+	if !fileExists(script) {
+		return "", fmt.Errorf("the NeoForge installer finished but %s was not created", runScript())
+	}
+	return script, nil
+}
+
+// neoForgePrefix maps a Minecraft version to NeoForge's version scheme:
+// 1.20.4 -> "20.4.", 1.21 -> "21.0."
+func neoForgePrefix(mcVersion string) (string, error) {
+	parts := strings.Split(mcVersion, ".")
+	if len(parts) < 2 || parts[0] != "1" {
+		return "", fmt.Errorf("unsupported Minecraft version for NeoForge: %s", mcVersion)
+	}
+	patch := "0"
+	if len(parts) > 2 {
+		patch = parts[2]
+	}
+	return fmt.Sprintf("%s.%s.", parts[1], patch), nil
+}
+
+// --- Installer helpers ---
+
+// LaunchFile returns the file a server type is started from, relative to the server directory
+func LaunchFile(serverType string) string {
+	switch strings.ToLower(serverType) {
+	case "quilt":
+		return "quilt-server-launch.jar"
+	case "forge", "neoforge":
+		return runScript()
+	default:
+		return "server.jar"
+	}
+}
+
+// RunsJava reports whether installing a server type runs Java (an installer or BuildTools)
+func RunsJava(serverType string) bool {
+	switch strings.ToLower(serverType) {
+	case "spigot", "bukkit", "quilt", "forge", "neoforge":
+		return true
+	}
+	return false
+}
+
+// ServerFiles lists the files and directories the downloader of a server type
+// writes into the server directory, the launch file first
+func ServerFiles(serverType string) []string {
+	switch strings.ToLower(serverType) {
+	case "quilt":
+		// The installer also downloads the vanilla server.jar and the loader libraries
+		return []string{"quilt-server-launch.jar", "server.jar", "libraries"}
+	case "forge", "neoforge":
+		// Older Forge versions are copied to server.jar instead of getting run scripts
+		return []string{runScript(), "user_jvm_args.txt", "libraries", "server.jar"}
+	default:
+		return []string{LaunchFile(serverType)}
+	}
+}
+
+// runScript is the start script generated by the Forge and NeoForge installers
+func runScript() string {
+	if runtime.GOOS == "windows" {
+		return "run.bat"
+	}
+	return "run.sh"
+}
+
+// runInstaller downloads an installer jar from the first mirror that serves it and runs it
+// with java ("" for JavaBinary) inside outputDir
+func runInstaller(baseURLs []string, java, path, outputDir string, args ...string) error {
+	tmpDir, err := os.MkdirTemp("", "mpm-installer-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	installer, err := downloadFromMirrors(baseURLs, path, tmpDir, "installer.jar")
+	if err != nil {
+		return fmt.Errorf("error downloading installer: %w", err)
+	}
+
+	absOutput, err := filepath.Abs(outputDir)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(ui.Out, "Running installer %s...\n", filepath.Base(path))
+	cmd := exec.Command(javaOrDefault(java), append([]string{"-jar", installer}, args...)...)
+	cmd.Dir = absOutput
+	cmd.Stdout = ui.Out
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("installer failed (is Java installed?): %w", err)
+	}
+
+	// The installers leave a log next to the server; it's only useful when they fail
+	os.Remove(filepath.Join(absOutput, "installer.jar.log"))
+	return nil
+}
+
+// mavenMetadata is the maven-metadata.xml of an artifact
+type mavenMetadata struct {
+	Versioning struct {
+		Release  string   `xml:"release"`
+		Versions []string `xml:"versions>version"`
+	} `xml:"versioning"`
+}
+
+// getMavenVersions lists the published versions of a Maven artifact (group/artifact path)
+func getMavenVersions(baseURLs []string, artifactPath string) ([]string, error) {
+	var lastErr error
+	for _, base := range baseURLs {
+		resp, err := httpGet(strings.TrimRight(base, "/") + "/" + artifactPath + "/maven-metadata.xml")
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		data, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if resp.StatusCode != 200 {
+			lastErr = fmt.Errorf("status %d from %s", resp.StatusCode, base)
+			continue
+		}
+
+		var metadata mavenMetadata
+		if err := xml.Unmarshal(data, &metadata); err != nil {
+			lastErr = err
+			continue
+		}
+		return metadata.Versioning.Versions, nil
+	}
+
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no API endpoints configured")
+	}
+	return nil, lastErr
+}
+
+// latestVersion returns the highest version starting with prefix.
+// stableOnly skips pre-releases (versions with a -beta, -rc... suffix after the prefix).
+func latestVersion(versions []string, prefix string, stableOnly bool) string {
+	var candidates []string
+	for _, v := range versions {
+		if !strings.HasPrefix(v, prefix) {
+			continue
+		}
+		if stableOnly && strings.Contains(strings.TrimPrefix(v, prefix), "-") {
+			continue
+		}
+		candidates = append(candidates, v)
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return utils.CompareVersions(strings.TrimPrefix(candidates[i], prefix), strings.TrimPrefix(candidates[j], prefix)) < 0
+	})
+	return candidates[len(candidates)-1]
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func copyFile(src, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, in)
+	return err
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNeoForgePrefix(t *testing.T) {
+	tests := []struct {
+		mcVersion string
+		want      string
+		wantErr   bool
+	}{
+		{"1.20.4", "20.4.", false},
+		{"1.21", "21.0.", false},
+		{"1.21.1", "21.1.", false},
+		{"1.20.2", "20.2.", false},
+		{"1", "", true},
+		{"", "", true},
+		{"2.0", "", true},
+	}
+	for _, tt := range tests {
+		got, err := neoForgePrefix(tt.mcVersion)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("neoForgePrefix(%q) error = %v, wantErr %v", tt.mcVersion, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("neoForgePrefix(%q) = %q, want %q", tt.mcVersion, got, tt.want)
+		}
+	}
+}
+
+func TestLatestVersion(t *testing.T) {
+	forge := []string{"1.20.1-47.1.0", "1.20.1-47.2.0", "1.20.1-47.10.1", "1.20.4-49.0.30", "1.20-46.0.14"}
+	neoforge := []string{"20.4.80-beta", "20.4.237", "20.4.190", "21.0.10-beta", "21.0.167"}
+	betasOnly := []string{"21.2.0-beta", "21.2.1-beta"}
+
+	tests := []struct {
+		name       string
+		versions   []string
+		prefix     string
+		stableOnly bool
+		want       string
+	}{
+		{"forge numeric order", forge, "1.20.1-", false, "1.20.1-47.10.1"},
+		{"forge prefix doesn't match a longer version", forge, "1.20-", false, "1.20-46.0.14"},
+		{"neoforge stable", neoforge, "20.4.", true, "20.4.237"},
+		{"neoforge any", neoforge, "21.0.", false, "21.0.167"},
+		{"only betas, stable requested", betasOnly, "21.2.", true, ""},
+		{"only betas", betasOnly, "21.2.", false, "21.2.1-beta"},
+		{"no match", forge, "1.19.2-", false, ""},
+		{"empty list", nil, "1.20.1-", false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := latestVersion(tt.versions, tt.prefix, tt.stableOnly); got != tt.want {
+				t.Errorf("latestVersion() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServerFiles(t *testing.T) {
+	tests := []struct {
+		serverType string
+		want       []string
+	}{
+		{"paper", []string{"server.jar"}},
+		{"fabric", []string{"server.jar"}},
+		{"Quilt", []string{"quilt-server-launch.jar", "server.jar", "libraries"}},
+		{"forge", []string{runScript(), "user_jvm_args.txt", "libraries", "server.jar"}},
+		{"neoforge", []string{runScript(), "user_jvm_args.txt", "libraries", "server.jar"}},
+	}
+	for _, tt := range tests {
+		got := ServerFiles(tt.serverType)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ServerFiles(%q) = %v, want %v", tt.serverType, got, tt.want)
+		}
+		// The launch file comes first
+		if got[0] != LaunchFile(tt.serverType) {
+			t.Errorf("ServerFiles(%q)[0] = %q, want the launch file %q", tt.serverType, got[0], LaunchFile(tt.serverType))
+		}
+	}
+}
@@ -0,0 +1,300 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// PropertiesFile is the file vanilla-based servers read their settings from
+const PropertiesFile = "server.properties"
+
+// Properties is a server.properties file. Comments, blank lines, key order and
+// the lines of keys that aren't changed are kept as they are.
+type Properties struct {
+	lines []propertyLine
+}
+
+type propertyLine struct {
+	raw   string // the line as read, "" for lines added by Set
+	key   string // "" for comments and blank lines
+	value string
+}
+
+// LoadProperties reads a properties file. A missing file is an empty one.
+func LoadProperties(path string) (*Properties, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Properties{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return ParseProperties(string(data)), nil
+}
+
+// ParseProperties parses the contents of a properties file
+func ParseProperties(data string) *Properties {
+	p := &Properties{}
+	lines := strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	for i := 0; i < len(lines); i++ {
+		raw := lines[i]
+		trimmed := strings.TrimLeft(raw, " \t\f")
+		if trimmed == "" || trimmed[0] == '#' || trimmed[0] == '!' {
+			p.lines = append(p.lines, propertyLine{raw: raw})
+			continue
+		}
+
+		// A value ending in an odd number of backslashes continues on the next line
+		logical := trimmed
+		for continues(logical) && i+1 < len(lines) {
+			i++
+			raw += "\n" + lines[i]
+			logical = logical[:len(logical)-1] + strings.TrimLeft(lines[i], " \t\f")
+		}
+
+		key, value := splitProperty(logical)
+		p.lines = append(p.lines, propertyLine{raw: raw, key: key, value: value})
+	}
+	return p
+}
+
+// continues reports whether a line ends in an unescaped backslash
+func continues(line string) bool {
+	n := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+// splitProperty splits a logical line at the first unescaped =, : or whitespace
+func splitProperty(line string) (string, string) {
+	end := len(line)
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.IndexByte("=: \t\f", line[i]) >= 0 {
+			end = i
+			break
+		}
+	}
+	key := line[:end]
+	rest := strings.TrimLeft(line[end:], " \t\f")
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+	return unescapeProperty(key), unescapeProperty(rest)
+}
+
+func unescapeProperty(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			if i+4 < len(s) {
+				if r, err := strconv.ParseUint(s[i+1:i+5], 16, 32); err == nil {
+					b.WriteRune(rune(r))
+					i += 4
+					continue
+				}
+			}
+			b.WriteByte('u')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// escapeProperty escapes a key or value the way Java writes properties files
+func escapeProperty(s string, isKey bool) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case r == ' ' && (isKey || i == 0):
+			b.WriteString(`\ `)
+		case r == '\\' || r == '=' || r == ':' || r == '#' || r == '!':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\f':
+			b.WriteString(`\f`)
+		case r < 0x20:
+			fmt.Fprintf(&b, `\u%04X`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Get returns the value of key, and whether the file sets it
+func (p *Properties) Get(key string) (string, bool) {
+	for i := len(p.lines) - 1; i >= 0; i-- {
+		if p.lines[i].key == key {
+			return p.lines[i].value, true
+		}
+	}
+	return "", false
+}
+
+// Set changes the value of key in place, or appends it if the file doesn't set it.
+// It reports whether the value changed.
+func (p *Properties) Set(key, value string) bool {
+	for i := len(p.lines) - 1; i >= 0; i-- {
+		if p.lines[i].key != key {
+			continue
+		}
+		if p.lines[i].value == value {
+			return false
+		}
+		p.lines[i] = propertyLine{key: key, value: value}
+		return true
+	}
+	p.lines = append(p.lines, propertyLine{key: key, value: value})
+	return true
+}
+
+// String renders the file, with the lines that weren't changed as they were read
+func (p *Properties) String() string {
+	var b strings.Builder
+	for _, line := range p.lines {
+		if line.raw != "" || line.key == "" {
+			b.WriteString(line.raw)
+		} else {
+			b.WriteString(escapeProperty(line.key, true) + "=" + escapeProperty(line.value, false))
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// Save writes the file to path
+func (p *Properties) Save(path string) error {
+	if err := os.WriteFile(path, []byte(p.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// propertyType describes the values a well-known server.properties key accepts
+type propertyType struct {
+	kind     string   // "bool", "int" or "enum"
+	min, max int      // for "int"
+	values   []string // for "enum"
+}
+
+var (
+	propertyBool = propertyType{kind: "bool"}
+	propertyPort = propertyType{kind: "int", min: 1, max: 65535}
+)
+
+// wellKnownProperties are the vanilla keys whose values mpm checks
+var wellKnownProperties = map[string]propertyType{
+	"server-port":                       propertyPort,
+	"query.port":                        propertyPort,
+	"rcon.port":                         propertyPort,
+	"accepts-transfers":                 propertyBool,
+	"allow-flight":                      propertyBool,
+	"allow-nether":                      propertyBool,
+	"broadcast-console-to-ops":          propertyBool,
+	"broadcast-rcon-to-ops":             propertyBool,
+	"enable-command-block":              propertyBool,
+	"enable-jmx-monitoring":             propertyBool,
+	"enable-query":                      propertyBool,
+	"enable-rcon":                       propertyBool,
+	"enable-status":                     propertyBool,
+	"enforce-secure-profile":            propertyBool,
+	"enforce-whitelist":                 propertyBool,
+	"force-gamemode":                    propertyBool,
+	"generate-structures":               propertyBool,
+	"hardcore":                          propertyBool,
+	"hide-online-players":               propertyBool,
+	"log-ips":                           propertyBool,
+	"online-mode":                       propertyBool,
+	"prevent-proxy-connections":         propertyBool,
+	"pvp":                               propertyBool,
+	"require-resource-pack":             propertyBool,
+	"spawn-animals":                     propertyBool,
+	"spawn-monsters":                    propertyBool,
+	"spawn-npcs":                        propertyBool,
+	"sync-chunk-writes":                 propertyBool,
+	"use-native-transport":              propertyBool,
+	"white-list":                        propertyBool,
+	"difficulty":                        {kind: "enum", values: []string{"peaceful", "easy", "normal", "hard"}},
+	"gamemode":                          {kind: "enum", values: []string{"survival", "creative", "adventure", "spectator"}},
+	"view-distance":                     {kind: "int", min: 3, max: 32},
+	"simulation-distance":               {kind: "int", min: 3, max: 32},
+	"max-players":                       {kind: "int", min: 0, max: 2147483647},
+	"spawn-protection":                  {kind: "int", min: 0, max: 2147483647},
+	"op-permission-level":               {kind: "int", min: 0, max: 4},
+	"function-permission-level":         {kind: "int", min: 1, max: 4},
+	"entity-broadcast-range-percentage": {kind: "int", min: 10, max: 1000},
+	"max-world-size":                    {kind: "int", min: 1, max: 29999984},
+	"network-compression-threshold":     {kind: "int", min: -1, max: 2147483647},
+	"player-idle-timeout":               {kind: "int", min: 0, max: 2147483647},
+	"max-tick-time":                     {kind: "int", min: -1, max: 2147483647},
+	"rate-limit":                        {kind: "int", min: 0, max: 2147483647},
+}
+
+// CheckProperty returns what is wrong with the value of a well-known
+// server.properties key, or "" when it is valid or the key isn't checked
+func CheckProperty(key, value string) string {
+	t, ok := wellKnownProperties[key]
+	if !ok {
+		return ""
+	}
+
+	switch t.kind {
+	case "bool":
+		if value != "true" && value != "false" {
+			return fmt.Sprintf("%s must be true or false, got %q", key, value)
+		}
+	case "int":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Sprintf("%s must be a number, got %q", key, value)
+		}
+		if n < t.min || n > t.max {
+			return fmt.Sprintf("%s must be between %d and %d, got %d", key, t.min, t.max, n)
+		}
+	case "enum":
+		for i, v := range t.values {
+			// The old numeric ids (0 for peaceful or survival, ...) are still accepted
+			if value == v || value == strconv.Itoa(i) {
+				return ""
+			}
+		}
+		return fmt.Sprintf("%s must be one of %s, got %q", key, strings.Join(t.values, ", "), value)
+	}
+	return ""
+}
@@ -0,0 +1,181 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseProperties(t *testing.T) {
+	data := strings.Join([]string{
+		"#Minecraft server properties",
+		"! also a comment",
+		"",
+		"server-port=25565",
+		"motd=A Minecraft Server",
+		"level-name : world",
+		"spaced\\ key\\ name = value",
+		"gamemode survival",
+		"empty=",
+		"escaped=line\\nbreak\\tand \\u00e9",
+		"long=first \\",
+		"    second",
+		"  indented=yes",
+		"server-port=25566",
+	}, "\r\n") + "\r\n"
+	p := ParseProperties(data)
+
+	tests := []struct {
+		key   string
+		want  string
+		found bool
+	}{
+		{"server-port", "25566", true}, // the last occurrence wins
+		{"motd", "A Minecraft Server", true},
+		{"level-name", "world", true},
+		{"spaced key name", "value", true},
+		{"gamemode", "survival", true},
+		{"empty", "", true},
+		{"escaped", "line\nbreak\tand é", true},
+		{"long", "first second", true},
+		{"indented", "yes", true},
+		{"#Minecraft", "", false},
+		{"missing", "", false},
+	}
+	for _, tt := range tests {
+		got, found := p.Get(tt.key)
+		if got != tt.want || found != tt.found {
+			t.Errorf("Get(%q) = %q, %v, want %q, %v", tt.key, got, found, tt.want, tt.found)
+		}
+	}
+}
+
+func TestPropertiesSet(t *testing.T) {
+	original := "#Minecraft server properties\n#Mon Jan 01 00:00:00 UTC 2024\nmotd=A Minecraft Server\nserver-port : 25565\npvp=true\n"
+
+	tests := []struct {
+		name    string
+		set     map[string]string
+		changed bool
+		want    string
+	}{
+		{
+			name:    "unchanged file is kept byte for byte",
+			set:     map[string]string{"pvp": "true", "server-port": "25565"},
+			changed: false,
+			want:    original,
+		},
+		{
+			name:    "changed value is rewritten in place",
+			set:     map[string]string{"server-port": "25566"},
+			changed: true,
+			want:    "#Minecraft server properties\n#Mon Jan 01 00:00:00 UTC 2024\nmotd=A Minecraft Server\nserver-port=25566\npvp=true\n",
+		},
+		{
+			name:    "new key is appended",
+			set:     map[string]string{"white-list": "true"},
+			changed: true,
+			want:    original + "white-list=true\n",
+		},
+		{
+			name:    "values are escaped",
+			set:     map[string]string{"motd": " Hello: world #1\\"},
+			changed: true,
+			want:    "#Minecraft server properties\n#Mon Jan 01 00:00:00 UTC 2024\nmotd=\\ Hello\\: world \\#1\\\\\nserver-port : 25565\npvp=true\n",
+		},
+		{
+			name:    "unicode is kept",
+			set:     map[string]string{"motd": "§aWelcome ✓"},
+			changed: true,
+			want:    "#Minecraft server properties\n#Mon Jan 01 00:00:00 UTC 2024\nmotd=§aWelcome ✓\nserver-port : 25565\npvp=true\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := ParseProperties(original)
+			changed := false
+			for key, value := range tt.set {
+				if p.Set(key, value) {
+					changed = true
+				}
+			}
+			if changed != tt.changed {
+				t.Errorf("changed = %v, want %v", changed, tt.changed)
+			}
+			got := p.String()
+			if got != tt.want {
+				t.Errorf("String() =\n%s\nwant\n%s", got, tt.want)
+			}
+
+			// What is written reads back the same
+			reread := ParseProperties(got)
+			for key, value := range tt.set {
+				if v, _ := reread.Get(key); v != value {
+					t.Errorf("%s reads back as %q, want %q", key, v, value)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadProperties(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, PropertiesFile)
+
+	p, err := LoadProperties(path)
+	if err != nil {
+		t.Fatalf("a missing file should be empty: %v", err)
+	}
+	p.Set("server-port", "25570")
+	if err := p.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "server-port=25570\n" {
+		t.Errorf("saved %q", data)
+	}
+	p, err = LoadProperties(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := p.Get("server-port"); v != "25570" {
+		t.Errorf("server-port = %q, want 25570", v)
+	}
+}
+
+func TestCheckProperty(t *testing.T) {
+	tests := []struct {
+		key, value string
+		want       string // substring of the problem, "" if valid
+	}{
+		{"server-port", "25565", ""},
+		{"server-port", "0", "between 1 and 65535"},
+		{"server-port", "70000", "between 1 and 65535"},
+		{"rcon.port", "abc", "must be a number"},
+		{"pvp", "true", ""},
+		{"pvp", "yes", "must be true or false"},
+		{"online-mode", "False", "must be true or false"},
+		{"difficulty", "hard", ""},
+		{"difficulty", "3", ""},
+		{"difficulty", "4", "must be one of peaceful, easy, normal, hard"},
+		{"gamemode", "spectator", ""},
+		{"gamemode", "Creative", "must be one of"},
+		{"view-distance", "2", "between 3 and 32"},
+		{"network-compression-threshold", "-1", ""},
+		{"max-tick-time", "-2", "between -1 and"},
+		{"motd", "anything goes", ""},
+		{"custom-plugin-key", "", ""},
+	}
+	for _, tt := range tests {
+		got := CheckProperty(tt.key, tt.value)
+		if tt.want == "" && got != "" || tt.want != "" && !strings.Contains(got, tt.want) {
+			t.Errorf("CheckProperty(%q, %q) = %q, want %q", tt.key, tt.value, got, tt.want)
+		}
+	}
+}
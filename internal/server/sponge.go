@@ -0,0 +1,178 @@
+package server
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/storrealbac/mpm/internal/utils"
+
+	"github.com/storrealbac/mpm/internal/ui"
+)
+
+// spongeArtifactPath is SpongeVanilla in the Sponge downloads API
+const spongeArtifactPath = "/groups/org.spongepowered/artifacts/spongevanilla"
+
+// --- Sponge Implementation ---
+
+// SpongeDownloader downloads SpongeVanilla from the Sponge downloads API.
+// build is "recommended" (the default) for the newest recommended build, "latest" for
+// the newest build, or a full SpongeVanilla version (e.g. 1.20.4-11.0.0).
+type SpongeDownloader struct {
+	BaseURLs []string // Sponge downloads API mirrors, tried in order
+}
+
+func (s *SpongeDownloader) Download(version, build string, outputDir string) (string, error) {
+	spongeVersion := build
+	if build == "" || build == "latest" || build == "recommended" {
+		latest, err := s.getLatestBuild(version, build != "latest")
+		if err != nil {
+			return "", err
+		}
+		spongeVersion = latest
+	}
+
+	var details struct {
+		Assets []struct {
+			Classifier  string `json:"classifier"`
+			Extension   string `json:"extension"`
+			DownloadURL string `json:"downloadUrl"`
+			SHA1        string `json:"sha1"`
+		} `json:"assets"`
+	}
+	if err := getJSONFromMirrors(s.BaseURLs, spongeArtifactPath+"/versions/"+url.PathEscape(spongeVersion), &details); err != nil {
+		return "", fmt.Errorf("error Sponge API: SpongeVanilla %s: %w", spongeVersion, err)
+	}
+
+	// The runnable server is the "universal" jar
+	downloadURL, checksum := "", ""
+	for _, asset := range details.Assets {
+		if asset.Classifier == "universal" && asset.Extension == "jar" {
+			downloadURL, checksum = asset.DownloadURL, asset.SHA1
+			break
+		}
+	}
+	if downloadURL == "" {
+		return "", fmt.Errorf("SpongeVanilla %s has no universal jar", spongeVersion)
+	}
+	fmt.Fprintf(ui.Out, "Selected SpongeVanilla %s\n", spongeVersion)
+
+	return downloadVerified(downloadURL, outputDir, sha1.New, checksum)
+}
+
+// getLatestBuild returns the newest SpongeVanilla version for a Minecraft version
+func (s *SpongeDownloader) getLatestBuild(mcVersion string, recommendedOnly bool) (string, error) {
+	params := url.Values{}
+	params.Set("tags", "minecraft:"+mcVersion)
+	params.Set("limit", "25")
+	if recommendedOnly {
+		params.Set("recommended", "true")
+	}
+
+	var result struct {
+		Artifacts map[string]struct {
+			Recommended bool `json:"recommended"`
+		} `json:"artifacts"`
+	}
+	if err := getJSONFromMirrors(s.BaseURLs, spongeArtifactPath+"/versions?"+params.Encode(), &result); err != nil {
+		return "", fmt.Errorf("error Sponge API: %w", err)
+	}
+
+	// Artifacts is a JSON object, so its order is lost; pick the newest by version
+	latest := ""
+	for version, artifact := range result.Artifacts {
+		if recommendedOnly && !artifact.Recommended {
+			continue
+		}
+		if latest == "" || compareSpongeVersions(version, latest) > 0 {
+			latest = version
+		}
+	}
+	if latest != "" {
+		return latest, nil
+	}
+
+	// Tell apart an unsupported Minecraft version from one without recommended builds
+	supported, err := s.supportedVersions()
+	if err != nil {
+		return "", fmt.Errorf("error Sponge API: %w", err)
+	}
+	if !containsVersion(supported, mcVersion) {
+		if len(supported) > 0 {
+			return "", fmt.Errorf("SpongeVanilla does not support Minecraft %s (supported: %s)", mcVersion, strings.Join(supported, ", "))
+		}
+		return "", fmt.Errorf("SpongeVanilla does not support Minecraft %s", mcVersion)
+	}
+	return "", fmt.Errorf("no recommended SpongeVanilla build for Minecraft %s yet (use build: latest to accept any build)", mcVersion)
+}
+
+// compareSpongeVersions orders SpongeVanilla versions (<minecraft>-<api>[-RC<n>]):
+// by Minecraft version, then API version, then release after its candidates
+func compareSpongeVersions(a, b string) int {
+	pa, pb := strings.SplitN(a, "-", 3), strings.SplitN(b, "-", 3)
+	for i := 0; i < 2; i++ {
+		var va, vb string
+		if i < len(pa) {
+			va = pa[i]
+		}
+		if i < len(pb) {
+			vb = pb[i]
+		}
+		if c := utils.CompareVersions(va, vb); c != 0 {
+			return c
+		}
+	}
+
+	rcA, rcB := spongeCandidate(pa), spongeCandidate(pb)
+	switch {
+	case rcA != rcB && (rcA < 0 || rcB < 0):
+		// A release (-1) is newer than any of its candidates
+		if rcA < 0 {
+			return 1
+		}
+		return -1
+	case rcA < rcB:
+		return -1
+	case rcA > rcB:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// spongeCandidate returns the RC number of a split SpongeVanilla version, or -1 for a release
+func spongeCandidate(parts []string) int {
+	if len(parts) < 3 {
+		return -1
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(strings.ToUpper(parts[2]), "RC"))
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// supportedVersions lists the Minecraft versions SpongeVanilla has builds for
+func (s *SpongeDownloader) supportedVersions() ([]string, error) {
+	var artifact struct {
+		Tags map[string][]string `json:"tags"`
+	}
+	if err := getJSONFromMirrors(s.BaseURLs, spongeArtifactPath, &artifact); err != nil {
+		return nil, err
+	}
+
+	versions := artifact.Tags["minecraft"]
+	sort.Slice(versions, func(i, j int) bool { return utils.CompareVersions(versions[i], versions[j]) < 0 })
+	return versions, nil
+}
+
+func containsVersion(versions []string, version string) bool {
+	for _, v := range versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCompareSpongeVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.20.4-11.0.0", "1.20.4-11.0.0", 0},
+		{"1.21.1-12.0.0", "1.20.6-11.0.0", 1},
+		{"1.20.4-11.0.1", "1.20.4-11.0.0", 1},
+		{"1.20.4-11.10.0", "1.20.4-11.9.0", 1},
+		{"1.20.4-11.0.0", "1.20.4-11.0.0-RC1500", 1},
+		{"1.20.4-11.0.0-RC1500", "1.20.4-11.0.0-RC1499", 1},
+		{"1.20.4-11.0.0-RC9", "1.20.4-11.0.0-RC10", -1},
+		{"1.20.4-11.0.1-RC1", "1.20.4-11.0.0", 1},
+		{"1.20.4-11.0.0-rc2", "1.20.4-11.0.0-RC1", 1},
+	}
+	for _, tt := range tests {
+		if got := compareSpongeVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareSpongeVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := compareSpongeVersions(tt.b, tt.a); got != -tt.want {
+			t.Errorf("compareSpongeVersions(%q, %q) = %d, want %d", tt.b, tt.a, got, -tt.want)
+		}
+	}
+}
+
+func TestSpongeGetLatestBuild(t *testing.T) {
+	artifacts := map[string]map[string]bool{
+		"1.20.4-11.0.0":        {"recommended": true},
+		"1.20.4-11.0.1":        {"recommended": true},
+		"1.20.4-11.1.0-RC1600": {"recommended": false},
+	}
+
+	tests := []struct {
+		name        string
+		mcVersion   string
+		recommended bool
+		status      int // of the versions endpoint
+		tagsStatus  int // of the artifact endpoint listing the supported versions
+		want        string
+		err         string
+	}{
+		{name: "newest recommended", mcVersion: "1.20.4", recommended: true, want: "1.20.4-11.0.1"},
+		{name: "newest build", mcVersion: "1.20.4", want: "1.20.4-11.1.0-RC1600"},
+		{name: "unsupported version", mcVersion: "1.8.9", recommended: true, err: "does not support Minecraft 1.8.9"},
+		{name: "no recommended build", mcVersion: "1.21", recommended: true, err: "no recommended SpongeVanilla build"},
+		{name: "API error", mcVersion: "1.20.4", recommended: true, status: http.StatusInternalServerError, err: "error Sponge API"},
+		{name: "API error on supported versions", mcVersion: "1.21", recommended: true, tagsStatus: http.StatusBadGateway, err: "error Sponge API"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if !strings.HasSuffix(r.URL.Path, "/versions") {
+					if tt.tagsStatus != 0 {
+						w.WriteHeader(tt.tagsStatus)
+						return
+					}
+					json.NewEncoder(w).Encode(map[string]any{"tags": map[string][]string{"minecraft": {"1.21", "1.20.4"}}})
+					return
+				}
+
+				if tt.status != 0 {
+					w.WriteHeader(tt.status)
+					return
+				}
+				if got := r.URL.Query().Get("recommended"); (got == "true") != tt.recommended {
+					t.Errorf("recommended = %q, want %v", got, tt.recommended)
+				}
+				found := map[string]map[string]bool{}
+				if r.URL.Query().Get("tags") == "minecraft:1.20.4" {
+					for version, artifact := range artifacts {
+						if !tt.recommended || artifact["recommended"] {
+							found[version] = artifact
+						}
+					}
+				}
+				json.NewEncoder(w).Encode(map[string]any{"artifacts": found})
+			}))
+			defer server.Close()
+
+			s := &SpongeDownloader{BaseURLs: []string{server.URL}}
+			got, err := s.getLatestBuild(tt.mcVersion, tt.recommended)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("err = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("getLatestBuild() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/storrealbac/mpm/internal/ui"
+)
+
+// MojangMetaURL is the default host of Mojang's version manifest
+const MojangMetaURL = "https://piston-meta.mojang.com"
+
+// VersionManifest is Mojang's version_manifest_v2.json
+type VersionManifest struct {
+	Latest struct {
+		Release  string `json:"release"`
+		Snapshot string `json:"snapshot"`
+	} `json:"latest"`
+	Versions []ManifestVersion `json:"versions"`
+}
+
+// ManifestVersion is a Minecraft version listed in the manifest
+type ManifestVersion struct {
+	ID   string `json:"id"`
+	Type string `json:"type"` // release, snapshot, old_beta, old_alpha
+	URL  string `json:"url"`  // per-version JSON
+	SHA1 string `json:"sha1"` // checksum of the per-version JSON
+}
+
+// GetVersionManifest downloads the version manifest from the first mirror that serves it
+func GetVersionManifest(baseURLs []string) (*VersionManifest, error) {
+	var manifest VersionManifest
+	if err := getJSONFromMirrors(baseURLs, "/mc/game/version_manifest_v2.json", &manifest); err != nil {
+		return nil, fmt.Errorf("error Mojang version manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+// IsVersionAlias reports whether version is one of the "latest" aliases
+// resolved from the manifest: latest, release or snapshot
+func IsVersionAlias(version string) bool {
+	switch strings.ToLower(version) {
+	case "latest", "release", "snapshot":
+		return true
+	}
+	return false
+}
+
+// Resolve finds a version by ID. latest and release resolve to the newest
+// release, snapshot to the newest snapshot.
+func (m *VersionManifest) Resolve(version string) (*ManifestVersion, error) {
+	id := version
+	switch strings.ToLower(version) {
+	case "latest", "release":
+		id = m.Latest.Release
+	case "snapshot":
+		id = m.Latest.Snapshot
+	}
+
+	for i := range m.Versions {
+		if m.Versions[i].ID == id {
+			return &m.Versions[i], nil
+		}
+	}
+	return nil, fmt.Errorf("unknown Minecraft version: %s", version)
+}
+
+// --- Vanilla Implementation ---
+
+// VanillaDownloader downloads the official server jar listed in Mojang's version manifest
+// and checks it against the published SHA1
+type VanillaDownloader struct {
+	BaseURLs []string // Mojang meta mirrors, tried in order
+}
+
+func (v *VanillaDownloader) Download(version, build string, outputDir string) (string, error) {
+	manifest, err := GetVersionManifest(v.BaseURLs)
+	if err != nil {
+		return "", err
+	}
+
+	entry, err := manifest.Resolve(version)
+	if err != nil {
+		return "", err
+	}
+
+	// The per-version JSON lists the server download and its checksum
+	var details struct {
+		Downloads struct {
+			Server *struct {
+				SHA1 string `json:"sha1"`
+				URL  string `json:"url"`
+			} `json:"server"`
+		} `json:"downloads"`
+	}
+	if err := getJSON(entry.URL, &details); err != nil {
+		return "", fmt.Errorf("error reading version %s: %w", entry.ID, err)
+	}
+	if details.Downloads.Server == nil {
+		return "", fmt.Errorf("Minecraft %s has no server download", entry.ID)
+	}
+	if entry.ID != version {
+		fmt.Fprintf(ui.Out, "Resolved %s to Minecraft %s\n", version, entry.ID)
+	}
+
+	return downloadVerified(details.Downloads.Server.URL, outputDir, sha1.New, details.Downloads.Server.SHA1)
+}
+
+// getJSON fetches an absolute URL and decodes the response into v
+func getJSON(url string, v interface{}) error {
+	resp, err := httpGet(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("status %d from %s", resp.StatusCode, url)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
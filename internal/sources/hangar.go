@@ -15,11 +15,18 @@ const (
 
 type HangarClient struct {
 	httpClient *http.Client
+	baseURLs   []string
 }
 
-func NewHangarClient() *HangarClient {
+// NewHangarClient creates a client for the Hangar API.
+// baseURLs is an optional list of mirrors tried in order; defaults to HangarBaseURL.
+func NewHangarClient(baseURLs ...string) *HangarClient {
+	if len(baseURLs) == 0 {
+		baseURLs = []string{HangarBaseURL}
+	}
 	return &HangarClient{
 		httpClient: &http.Client{},
+		baseURLs:   baseURLs,
 	}
 }
 
@@ -43,6 +50,42 @@ type HangarProject struct {
 	Category           string              `json:"category"`
 	Stats              HangarStats         `json:"stats"`
 	SupportedPlatforms map[string][]string `json:"supportedPlatforms"` // Platform -> versions
+	CreatedAt          string              `json:"createdAt"`
+	LastUpdated        string              `json:"lastUpdated"`
+	Settings           HangarSettings      `json:"settings"`
+}
+
+type HangarSettings struct {
+	Links    []HangarLinkSection `json:"links"`
+	License  HangarLicense       `json:"license"`
+	Keywords []string            `json:"keywords"`
+}
+
+type HangarLinkSection struct {
+	Title string       `json:"title"`
+	Links []HangarLink `json:"links"`
+}
+
+type HangarLink struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+type HangarLicense struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	Type string `json:"type"`
+}
+
+// HangarSearchOptions configures a Hangar project search
+type HangarSearchOptions struct {
+	Query       string
+	ServerType  string // mapped to a Hangar platform
+	GameVersion string // optional Minecraft version filter
+	Category    string // optional Hangar category (admin_tools, chat, economy...)
+	Sort        string // Hangar sort field, e.g. "-downloads", "-updated", "-stars"
+	Offset      int
+	Limit       int
 }
 
 type HangarNamespace struct {
@@ -70,6 +113,13 @@ type HangarVersion struct {
 	Downloads                     map[string]HangarVersionDownload  `json:"downloads"` // Platform -> download info
 	PlatformDependencies          map[string][]string               `json:"platformDependencies"` // Platform -> versions
 	PlatformDependenciesFormatted map[string][]string               `json:"platformDependenciesFormatted"` // Platform -> version ranges
+	PluginDependencies            map[string][]HangarPluginDependency `json:"pluginDependencies"`         // Platform -> plugin dependencies
+}
+
+type HangarPluginDependency struct {
+	Name        string `json:"name"`
+	Required    bool   `json:"required"`
+	ExternalURL string `json:"externalUrl"`
 }
 
 type HangarVersionDownload struct {
@@ -89,27 +139,50 @@ type HangarFileInfo struct {
 // serverType: optional server platform to filter results (paper, velocity, waterfall)
 // limit: maximum number of results to return (default 25)
 func (c *HangarClient) SearchProjects(query string, serverType string, limit int) ([]HangarProject, error) {
-	if limit <= 0 {
-		limit = 25
+	searchResp, err := c.Search(HangarSearchOptions{
+		Query:      query,
+		ServerType: serverType,
+		Limit:      limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return searchResp.Result, nil
+}
+
+// Search runs a project search with filters, sorting and pagination
+func (c *HangarClient) Search(opts HangarSearchOptions) (*HangarSearchResponse, error) {
+	if opts.Limit <= 0 {
+		opts.Limit = 25
 	}
 
 	// Build the URL with query parameters
 	params := url.Values{}
-	params.Add("q", query)
-	params.Add("limit", fmt.Sprintf("%d", limit))
-	params.Add("offset", "0")
+	params.Add("q", opts.Query)
+	params.Add("limit", fmt.Sprintf("%d", opts.Limit))
+	params.Add("offset", fmt.Sprintf("%d", opts.Offset))
 
 	// Hangar uses platform filtering in the query parameter
-	if serverType != "" {
-		platform := mapServerTypeToPlatform(serverType)
+	if opts.ServerType != "" {
+		platform := mapServerTypeToPlatform(opts.ServerType)
 		if platform != "" {
 			params.Add("platform", platform)
 		}
 	}
+	if opts.GameVersion != "" {
+		params.Add("version", opts.GameVersion)
+	}
+	if opts.Category != "" {
+		params.Add("category", opts.Category)
+	}
+	if opts.Sort != "" {
+		params.Add("sort", opts.Sort)
+	}
 
-	reqURL := fmt.Sprintf("%s/projects?%s", HangarBaseURL, params.Encode())
+	reqPath := fmt.Sprintf("/projects?%s", params.Encode())
 
-	resp, err := c.httpClient.Get(reqURL)
+	resp, err := getFromMirrors(c.httpClient, c.baseURLs, reqPath)
 	if err != nil {
 		return nil, err
 	}
@@ -125,14 +198,14 @@ func (c *HangarClient) SearchProjects(query string, serverType string, limit int
 		return nil, err
 	}
 
-	return searchResp.Result, nil
+	return &searchResp, nil
 }
 
 // GetProject retrieves a specific project by owner and slug
 func (c *HangarClient) GetProject(owner, slug string) (*HangarProject, error) {
-	reqURL := fmt.Sprintf("%s/projects/%s/%s", HangarBaseURL, owner, slug)
+	reqPath := fmt.Sprintf("/projects/%s/%s", owner, slug)
 
-	resp, err := c.httpClient.Get(reqURL)
+	resp, err := getFromMirrors(c.httpClient, c.baseURLs, reqPath)
 	if err != nil {
 		return nil, err
 	}
@@ -161,9 +234,9 @@ func (c *HangarClient) GetProjectVersions(owner, slug, gameVersion, platform str
 	params.Add("limit", "25")
 	params.Add("offset", "0")
 
-	reqURL := fmt.Sprintf("%s/projects/%s/%s/versions?%s", HangarBaseURL, owner, slug, params.Encode())
+	reqPath := fmt.Sprintf("/projects/%s/%s/versions?%s", owner, slug, params.Encode())
 
-	resp, err := c.httpClient.Get(reqURL)
+	resp, err := getFromMirrors(c.httpClient, c.baseURLs, reqPath)
 	if err != nil {
 		return nil, err
 	}
@@ -266,8 +339,8 @@ func HangarIsPluginCompatible(project *HangarProject, serverType string) (compat
 		}
 		return false, false
 
-	case "waterfall":
-		// Waterfall is compatible with BungeeCord plugins
+	case "waterfall", "bungeecord":
+		// Hangar lists BungeeCord plugins under Waterfall
 		if _, ok := project.SupportedPlatforms["WATERFALL"]; ok {
 			return true, true
 		}
@@ -291,14 +364,19 @@ func HangarIsPluginCompatible(project *HangarProject, serverType string) (compat
 	return false, false
 }
 
+// HangarPlatform returns the Hangar platform whose builds a server type runs, or "" if none
+func HangarPlatform(serverType string) string {
+	return mapServerTypeToPlatform(serverType)
+}
+
 // mapServerTypeToPlatform converts mpm server types to Hangar platform names
 func mapServerTypeToPlatform(serverType string) string {
 	switch strings.ToLower(serverType) {
-	case "paper", "purpur", "folia", "spigot", "bukkit":
+	case "paper", "purpur", "folia", "pufferfish", "leaf", "leaves", "canvas", "spigot", "bukkit":
 		return "PAPER"
 	case "velocity":
 		return "VELOCITY"
-	case "waterfall":
+	case "waterfall", "bungeecord":
 		return "WATERFALL"
 	default:
 		return ""
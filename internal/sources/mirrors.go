@@ -0,0 +1,55 @@
+package sources
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// getFromMirrors performs a GET request for path against each base URL in order.
+// A mirror is skipped when the request fails or the server answers with a 5xx status,
+// so a dead caching proxy falls back to the next configured endpoint.
+func getFromMirrors(client *http.Client, baseURLs []string, path string) (*http.Response, error) {
+	return requestFromMirrors(client, http.MethodGet, baseURLs, path, nil)
+}
+
+// postJSONToMirrors performs a POST request with a JSON body, with the same fallback as getFromMirrors
+func postJSONToMirrors(client *http.Client, baseURLs []string, path string, body []byte) (*http.Response, error) {
+	return requestFromMirrors(client, http.MethodPost, baseURLs, path, body)
+}
+
+func requestFromMirrors(client *http.Client, method string, baseURLs []string, path string, body []byte) (*http.Response, error) {
+	if len(baseURLs) == 0 {
+		return nil, fmt.Errorf("no API endpoints configured")
+	}
+
+	var lastErr error
+	for _, base := range baseURLs {
+		reqURL := strings.TrimRight(base, "/") + path
+
+		req, err := http.NewRequest(method, reqURL, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		if resp.StatusCode >= http.StatusInternalServerError {
+			resp.Body.Close()
+			lastErr = fmt.Errorf("API error: %d from %s", resp.StatusCode, base)
+			continue
+		}
+
+		return resp, nil
+	}
+
+	return nil, lastErr
+}
@@ -0,0 +1,58 @@
+package sources
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGetFromMirrors(t *testing.T) {
+	mirror := func(status int, body string) string {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+			io.WriteString(w, body+" "+r.URL.Path)
+		}))
+		t.Cleanup(server.Close)
+		return server.URL
+	}
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close() // refuses connections
+
+	tests := []struct {
+		name    string
+		mirrors []string
+		status  int
+		body    string
+		err     string
+	}{
+		{name: "first mirror answers", mirrors: []string{mirror(200, "primary"), mirror(200, "secondary")}, status: 200, body: "primary /v2/search"},
+		{name: "trailing slash", mirrors: []string{mirror(200, "primary") + "/"}, status: 200, body: "primary /v2/search"},
+		{name: "5xx falls back", mirrors: []string{mirror(502, "proxy"), mirror(200, "upstream")}, status: 200, body: "upstream /v2/search"},
+		{name: "unreachable falls back", mirrors: []string{down.URL, mirror(200, "upstream")}, status: 200, body: "upstream /v2/search"},
+		{name: "4xx is an answer", mirrors: []string{mirror(404, "missing"), mirror(200, "upstream")}, status: 404, body: "missing /v2/search"},
+		{name: "every mirror fails", mirrors: []string{mirror(500, "a"), mirror(503, "b")}, err: "API error: 503"},
+		{name: "no mirrors", err: "no API endpoints configured"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := getFromMirrors(http.DefaultClient, tt.mirrors, "/v2/search")
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("err = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != tt.status || string(body) != tt.body {
+				t.Errorf("got %d %q, want %d %q", resp.StatusCode, body, tt.status, tt.body)
+			}
+		})
+	}
+}
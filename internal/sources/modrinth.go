@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
 )
 
 const (
@@ -14,11 +15,18 @@ const (
 
 type ModrinthClient struct {
 	httpClient *http.Client
+	baseURLs   []string
 }
 
-func NewModrinthClient() *ModrinthClient {
+// NewModrinthClient creates a client for the Modrinth API.
+// baseURLs is an optional list of mirrors tried in order; defaults to ModrinthBaseURL.
+func NewModrinthClient(baseURLs ...string) *ModrinthClient {
+	if len(baseURLs) == 0 {
+		baseURLs = []string{ModrinthBaseURL}
+	}
 	return &ModrinthClient{
 		httpClient: &http.Client{},
+		baseURLs:   baseURLs,
 	}
 }
 
@@ -30,15 +38,58 @@ type ModrinthSearchResponse struct {
 }
 
 type ModrinthProject struct {
-	Slug        string   `json:"slug"`
-	Title       string   `json:"title"`
-	Description string   `json:"description"`
-	Categories  []string `json:"categories"`
-	ClientSide  string   `json:"client_side"`
-	ServerSide  string   `json:"server_side"`
-	ProjectID   string   `json:"project_id"`
-	Author      string   `json:"author"`
-	Versions    []string `json:"versions"`
+	Slug         string   `json:"slug"`
+	Title        string   `json:"title"`
+	Description  string   `json:"description"`
+	Categories   []string `json:"categories"`
+	ClientSide   string   `json:"client_side"`
+	ServerSide   string   `json:"server_side"`
+	ProjectID    string   `json:"project_id"`
+	Author       string   `json:"author"`
+	Versions     []string `json:"versions"`
+	Downloads    int      `json:"downloads"`
+	Follows      int      `json:"follows"`       // search hits
+	DateModified string   `json:"date_modified"` // search hits
+
+	// Fields only returned by the project endpoint
+	ID           string           `json:"id,omitempty"`
+	Loaders      []string         `json:"loaders,omitempty"`
+	GameVersions []string         `json:"game_versions,omitempty"`
+	License      *ModrinthLicense `json:"license,omitempty"`
+	Followers    int              `json:"followers,omitempty"`
+	Updated      string           `json:"updated,omitempty"`
+	Published    string           `json:"published,omitempty"`
+	IssuesURL    string           `json:"issues_url,omitempty"`
+	SourceURL    string           `json:"source_url,omitempty"`
+	WikiURL      string           `json:"wiki_url,omitempty"`
+	DiscordURL   string           `json:"discord_url,omitempty"`
+}
+
+type ModrinthLicense struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// ModrinthTeamMember is a member of a project's team
+type ModrinthTeamMember struct {
+	Role string `json:"role"`
+	User struct {
+		Username string `json:"username"`
+	} `json:"user"`
+}
+
+// ModrinthSearchOptions configures a Modrinth project search
+type ModrinthSearchOptions struct {
+	Query        string
+	ServerType   string   // platform used to build category facets
+	Strict       bool     // only exact platform matches
+	GameVersion  string   // optional Minecraft version facet
+	Categories   []string // optional extra categories (all must match)
+	Index        string   // relevance, downloads, follows, newest, updated
+	MinDownloads int      // optional minimum download count
+	Offset       int
+	Limit        int
 }
 
 type ModrinthVersion struct {
@@ -50,6 +101,16 @@ type ModrinthVersion struct {
 	GameVersions  []string `json:"game_versions"`
 	Loaders       []string `json:"loaders"`
 	Files         []ModrinthFile   `json:"files"`
+	DatePublished string               `json:"date_published,omitempty"`
+	VersionType   string               `json:"version_type,omitempty"` // release, beta, alpha
+	Dependencies  []ModrinthDependency `json:"dependencies,omitempty"`
+}
+
+type ModrinthDependency struct {
+	VersionID      string `json:"version_id,omitempty"`
+	ProjectID      string `json:"project_id,omitempty"`
+	FileName       string `json:"file_name,omitempty"`
+	DependencyType string `json:"dependency_type"` // required, optional, incompatible, embedded
 }
 
 type ModrinthFile struct {
@@ -64,14 +125,42 @@ type ModrinthFile struct {
 // serverType: optional server platform to filter results (paper, folia, velocity, etc.)
 // strict: if true, only return plugins that exactly match the server type
 func (c *ModrinthClient) SearchProjects(query string, serverType string, strict bool) ([]ModrinthProject, error) {
+	searchResp, err := c.Search(ModrinthSearchOptions{
+		Query:      query,
+		ServerType: serverType,
+		Strict:     strict,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return searchResp.Hits, nil
+}
+
+// Search runs a project search with filters, sorting and pagination
+func (c *ModrinthClient) Search(opts ModrinthSearchOptions) (*ModrinthSearchResponse, error) {
 	// Build facets based on server type and strict mode
-	facets := buildSearchFacets(serverType, strict)
+	facets, err := buildFacets(opts)
+	if err != nil {
+		return nil, err
+	}
 
-	encodedQuery := url.QueryEscape(query)
-	encodedFacets := url.QueryEscape(facets)
-	url := fmt.Sprintf("%s/search?query=%s&facets=%s", ModrinthBaseURL, encodedQuery, encodedFacets)
+	params := url.Values{}
+	params.Add("query", opts.Query)
+	params.Add("facets", facets)
+	if opts.Index != "" {
+		params.Add("index", opts.Index)
+	}
+	if opts.Offset > 0 {
+		params.Add("offset", fmt.Sprintf("%d", opts.Offset))
+	}
+	if opts.Limit > 0 {
+		params.Add("limit", fmt.Sprintf("%d", opts.Limit))
+	}
 
-	resp, err := c.httpClient.Get(url)
+	path := fmt.Sprintf("/search?%s", params.Encode())
+
+	resp, err := getFromMirrors(c.httpClient, c.baseURLs, path)
 	if err != nil {
 		return nil, err
 	}
@@ -86,7 +175,31 @@ func (c *ModrinthClient) SearchProjects(query string, serverType string, strict
 		return nil, err
 	}
 
-	return searchResp.Hits, nil
+	return &searchResp, nil
+}
+
+// buildFacets combines the platform facets with version and category filters
+func buildFacets(opts ModrinthSearchOptions) (string, error) {
+	var groups [][]string
+	if err := json.Unmarshal([]byte(buildSearchFacets(opts.ServerType, opts.Strict)), &groups); err != nil {
+		return "", err
+	}
+
+	if opts.GameVersion != "" {
+		groups = append(groups, []string{"versions:" + opts.GameVersion})
+	}
+	for _, category := range opts.Categories {
+		groups = append(groups, []string{"categories:" + category})
+	}
+	if opts.MinDownloads > 0 {
+		groups = append(groups, []string{fmt.Sprintf("downloads>=%d", opts.MinDownloads)})
+	}
+
+	data, err := json.Marshal(groups)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
 }
 
 // buildSearchFacets creates Modrinth API facets based on server type
@@ -109,6 +222,13 @@ func buildSearchFacets(serverType string, strict bool) string {
 		// Waterfall is BungeeCord compatible
 		return `[["categories:bungeecord"]]`
 
+	case "bungeecord":
+		if strict {
+			return `[["categories:bungeecord"]]`
+		}
+		// Waterfall plugins are BungeeCord plugins
+		return `[["categories:bungeecord", "categories:waterfall"]]`
+
 	case "folia":
 		if strict {
 			// Only Folia-specific plugins
@@ -131,6 +251,17 @@ func buildSearchFacets(serverType string, strict bool) string {
 		// Purpur is Paper fork, compatible with Paper, Spigot, and Bukkit
 		return `[["categories:purpur", "categories:paper", "categories:spigot", "categories:bukkit"]]`
 
+	case "pufferfish", "leaf", "leaves":
+		if strict {
+			return `[["categories:paper"]]`
+		}
+		// Paper forks run Paper, Spigot and Bukkit plugins
+		return `[["categories:paper", "categories:spigot", "categories:bukkit"]]`
+
+	case "canvas":
+		// Canvas is a Folia fork: only plugins with Folia support load on it
+		return `[["categories:folia"]]`
+
 	case "spigot":
 		if strict {
 			return `[["categories:spigot"]]`
@@ -152,12 +283,66 @@ func buildSearchFacets(serverType string, strict bool) string {
 		// Sponge has its own plugin API, not compatible with others
 		return `[["categories:sponge"]]`
 
+	case "fabric":
+		// Server-side Fabric mods
+		return `[["categories:fabric"], ["project_type:mod"], ["server_side:required", "server_side:optional"]]`
+
+	case "quilt":
+		if strict {
+			return `[["categories:quilt"], ["project_type:mod"], ["server_side:required", "server_side:optional"]]`
+		}
+		// Quilt loads most Fabric mods
+		return `[["categories:quilt", "categories:fabric"], ["project_type:mod"], ["server_side:required", "server_side:optional"]]`
+
+	case "forge":
+		return `[["categories:forge"], ["project_type:mod"], ["server_side:required", "server_side:optional"]]`
+
+	case "neoforge":
+		return `[["categories:neoforge"], ["project_type:mod"], ["server_side:required", "server_side:optional"]]`
+
 	default:
 		// If no server type specified, include all server-side plugins
 		return `[["categories:bukkit", "categories:folia", "categories:paper", "categories:purpur", "categories:spigot", "categories:sponge", "categories:velocity", "categories:bungeecord"]]`
 	}
 }
 
+// ModrinthCompatibleLoaders returns the Modrinth loaders whose plugins run on serverType,
+// ordered from best to worst match
+func ModrinthCompatibleLoaders(serverType string) []string {
+	switch strings.ToLower(serverType) {
+	case "folia":
+		return []string{"folia"}
+	case "paper":
+		return []string{"paper", "spigot", "bukkit"}
+	case "purpur":
+		return []string{"purpur", "paper", "spigot", "bukkit"}
+	case "pufferfish", "leaf", "leaves":
+		return []string{"paper", "spigot", "bukkit"}
+	case "canvas":
+		return []string{"folia"}
+	case "spigot":
+		return []string{"spigot", "bukkit"}
+	case "bukkit":
+		return []string{"bukkit"}
+	case "velocity":
+		return []string{"velocity"}
+	case "waterfall", "bungeecord":
+		return []string{"bungeecord", "waterfall"}
+	case "sponge":
+		return []string{"sponge"}
+	case "fabric":
+		return []string{"fabric"}
+	case "quilt":
+		return []string{"quilt", "fabric"}
+	case "forge":
+		return []string{"forge"}
+	case "neoforge":
+		return []string{"neoforge"}
+	default:
+		return nil
+	}
+}
+
 // IsPluginCompatible checks if a plugin's categories match the server type
 func ModrinthIsPluginCompatible(project *ModrinthProject, serverType string) (compatible bool, exactMatch bool) {
 	if serverType == "" {
@@ -208,6 +393,26 @@ func ModrinthIsPluginCompatible(project *ModrinthProject, serverType string) (co
 			}
 		}
 
+	case "pufferfish", "leaf", "leaves":
+		// Paper forks: Paper plugins are the best match
+		for _, cat := range categories {
+			if cat == "paper" {
+				return true, true
+			}
+			if cat == "spigot" || cat == "bukkit" {
+				return true, false
+			}
+		}
+
+	case "canvas":
+		// Folia fork, needs explicit Folia support
+		for _, cat := range categories {
+			if cat == "folia" {
+				return true, true
+			}
+		}
+		return false, false
+
 	case "spigot":
 		// Spigot is compatible with Spigot and Bukkit
 		for _, cat := range categories {
@@ -243,6 +448,14 @@ func ModrinthIsPluginCompatible(project *ModrinthProject, serverType string) (co
 			}
 		}
 
+	case "bungeecord":
+		// Waterfall plugins usually run on BungeeCord
+		for _, cat := range categories {
+			if cat == "waterfall" {
+				return true, false
+			}
+		}
+
 	case "sponge":
 		// Sponge plugins only
 		for _, cat := range categories {
@@ -250,6 +463,14 @@ func ModrinthIsPluginCompatible(project *ModrinthProject, serverType string) (co
 				return true, true
 			}
 		}
+
+	case "quilt":
+		// Quilt loads most Fabric mods
+		for _, cat := range categories {
+			if cat == "fabric" {
+				return true, false
+			}
+		}
 	}
 
 	return false, false
@@ -257,9 +478,9 @@ func ModrinthIsPluginCompatible(project *ModrinthProject, serverType string) (co
 
 // GetProject retrieves project information by ID or slug
 func (c *ModrinthClient) GetProject(idOrSlug string) (*ModrinthProject, error) {
-	reqUrl := fmt.Sprintf("%s/project/%s", ModrinthBaseURL, idOrSlug)
+	reqPath := fmt.Sprintf("/project/%s", idOrSlug)
 
-	resp, err := c.httpClient.Get(reqUrl)
+	resp, err := getFromMirrors(c.httpClient, c.baseURLs, reqPath)
 	if err != nil {
 		return nil, err
 	}
@@ -277,16 +498,134 @@ func (c *ModrinthClient) GetProject(idOrSlug string) (*ModrinthProject, error) {
 	return &project, nil
 }
 
+// GetProjectMembers retrieves the team members of a project
+func (c *ModrinthClient) GetProjectMembers(idOrSlug string) ([]ModrinthTeamMember, error) {
+	reqPath := fmt.Sprintf("/project/%s/members", idOrSlug)
+
+	resp, err := getFromMirrors(c.httpClient, c.baseURLs, reqPath)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API error: %d", resp.StatusCode)
+	}
+
+	var members []ModrinthTeamMember
+	if err := json.NewDecoder(resp.Body).Decode(&members); err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+// GetProjects retrieves several projects at once by ID or slug
+func (c *ModrinthClient) GetProjects(ids []string) ([]ModrinthProject, error) {
+	encodedIDs, err := json.Marshal(ids)
+	if err != nil {
+		return nil, err
+	}
+	reqPath := fmt.Sprintf("/projects?ids=%s", url.QueryEscape(string(encodedIDs)))
+
+	resp, err := getFromMirrors(c.httpClient, c.baseURLs, reqPath)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API error: %d", resp.StatusCode)
+	}
+
+	var projects []ModrinthProject
+	if err := json.NewDecoder(resp.Body).Decode(&projects); err != nil {
+		return nil, err
+	}
+
+	return projects, nil
+}
+
+// GetVersionFromHash retrieves the version that published a file, by the file's
+// sha1 or sha512 hash. It returns nil if no version has that file.
+func (c *ModrinthClient) GetVersionFromHash(hash, algorithm string) (*ModrinthVersion, error) {
+	reqPath := fmt.Sprintf("/version_file/%s?algorithm=%s", hash, url.QueryEscape(algorithm))
+
+	resp, err := getFromMirrors(c.httpClient, c.baseURLs, reqPath)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API error: %d", resp.StatusCode)
+	}
+
+	var version ModrinthVersion
+	if err := json.NewDecoder(resp.Body).Decode(&version); err != nil {
+		return nil, err
+	}
+
+	return &version, nil
+}
+
+// GetVersionsFromHashes retrieves the versions of several files at once.
+// The result maps each known hash to its version; unknown hashes are left out.
+func (c *ModrinthClient) GetVersionsFromHashes(hashes []string, algorithm string) (map[string]ModrinthVersion, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"hashes":    hashes,
+		"algorithm": algorithm,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := postJSONToMirrors(c.httpClient, c.baseURLs, "/version_files", body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API error: %d", resp.StatusCode)
+	}
+
+	versions := make(map[string]ModrinthVersion)
+	if err := json.NewDecoder(resp.Body).Decode(&versions); err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
 // GetProjectVersions obtiene las versiones de un proyecto, opcionalmente filtrando por versión de juego
 func (c *ModrinthClient) GetProjectVersions(idOrSlug string, gameVersion string) ([]ModrinthVersion, error) {
-	reqUrl := fmt.Sprintf("%s/project/%s/version", ModrinthBaseURL, idOrSlug)
+	return c.GetProjectVersionsForLoaders(idOrSlug, gameVersion, nil)
+}
+
+// GetProjectVersionsForLoaders returns the versions of a project, optionally filtered
+// by game version and by the loaders they were published for (paper, fabric, forge...)
+func (c *ModrinthClient) GetProjectVersionsForLoaders(idOrSlug string, gameVersion string, loaders []string) ([]ModrinthVersion, error) {
+	reqPath := fmt.Sprintf("/project/%s/version", idOrSlug)
+	params := url.Values{}
 	if gameVersion != "" {
 		// game_versions=["1.20.1"]
-		encodedVersion := fmt.Sprintf(`["%s"]`, gameVersion)
-		reqUrl = fmt.Sprintf("%s?game_versions=%s", reqUrl, url.QueryEscape(encodedVersion))
+		encoded, _ := json.Marshal([]string{gameVersion})
+		params.Set("game_versions", string(encoded))
+	}
+	if len(loaders) > 0 {
+		// loaders=["fabric","quilt"]
+		encoded, _ := json.Marshal(loaders)
+		params.Set("loaders", string(encoded))
+	}
+	if len(params) > 0 {
+		reqPath += "?" + params.Encode()
 	}
 
-	resp, err := c.httpClient.Get(reqUrl)
+	resp, err := getFromMirrors(c.httpClient, c.baseURLs, reqPath)
 	if err != nil {
 		return nil, err
 	}
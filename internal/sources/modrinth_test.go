@@ -0,0 +1,54 @@
+package sources
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestBuildFacets(t *testing.T) {
+	tests := []struct {
+		name string
+		opts ModrinthSearchOptions
+		want string
+	}{
+		{
+			name: "platform only",
+			opts: ModrinthSearchOptions{ServerType: "velocity"},
+			want: `[["categories:velocity"]]`,
+		},
+		{
+			name: "strict platform",
+			opts: ModrinthSearchOptions{ServerType: "paper", Strict: true},
+			want: `[["categories:paper"]]`,
+		},
+		{
+			name: "version and categories",
+			opts: ModrinthSearchOptions{ServerType: "velocity", GameVersion: "1.21", Categories: []string{"chat", "utility"}},
+			want: `[["categories:velocity"],["versions:1.21"],["categories:chat"],["categories:utility"]]`,
+		},
+		{
+			name: "minimum downloads",
+			opts: ModrinthSearchOptions{ServerType: "waterfall", MinDownloads: 10000},
+			want: `[["categories:bungeecord"],["downloads>=10000"]]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildFacets(tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Compare decoded, json.Marshal escapes > and <
+			var gotFacets, wantFacets [][]string
+			if err := json.Unmarshal([]byte(got), &gotFacets); err != nil {
+				t.Fatalf("invalid facets %s: %v", got, err)
+			}
+			json.Unmarshal([]byte(tt.want), &wantFacets)
+			if !reflect.DeepEqual(gotFacets, wantFacets) {
+				t.Errorf("buildFacets() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
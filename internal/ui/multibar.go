@@ -29,7 +29,7 @@ var globalMultiBar *MultiProgressBar
 func InitMultiBar() {
 	globalMultiBar = &MultiProgressBar{
 		bars:   make(map[int]*ProgressBar),
-		active: true,
+		active: !IsStructured(),
 	}
 }
 
@@ -54,7 +54,9 @@ func AddBar(name string, total uint64) int {
 	}
 
 	// Print initial line for this bar
-	fmt.Println()
+	if globalMultiBar.active {
+		fmt.Fprintln(Out)
+	}
 
 	return id
 }
@@ -123,9 +125,13 @@ func CloseMultiBar() {
 	globalMultiBar.mutex.Lock()
 	defer globalMultiBar.mutex.Unlock()
 
+	if !globalMultiBar.active {
+		return
+	}
+
 	globalMultiBar.active = false
 	// Move cursor to bottom
-	fmt.Print("\n")
+	fmt.Fprint(Out, "\n")
 }
 
 // render displays all progress bars (caller must hold mutex)
@@ -142,7 +148,7 @@ func (m *MultiProgressBar) render() {
 
 	// Move cursor up by the number of bars
 	for i := 0; i < numBars; i++ {
-		fmt.Print("\033[A") // Move up one line
+		fmt.Fprint(Out, "\033[A") // Move up one line
 	}
 
 	// Render each bar
@@ -153,7 +159,7 @@ func (m *MultiProgressBar) render() {
 		}
 
 		// Clear line
-		fmt.Print("\033[2K\r")
+		fmt.Fprint(Out, "\033[2K\r")
 
 		// Calculate percentage
 		percent := float64(0)
@@ -204,7 +210,7 @@ func (m *MultiProgressBar) render() {
 		}
 
 		if bar.Total > 0 {
-			fmt.Printf("%s %-30s [%s] %5.1f%% (%.1f/%.1f MB)\n",
+			fmt.Fprintf(Out, "%s %-30s [%s] %5.1f%% (%.1f/%.1f MB)\n",
 				status,
 				displayName,
 				barStr,
@@ -213,7 +219,7 @@ func (m *MultiProgressBar) render() {
 				mbTotal,
 			)
 		} else {
-			fmt.Printf("%s %-30s [%s] %.1f MB\n",
+			fmt.Fprintf(Out, "%s %-30s [%s] %.1f MB\n",
 				status,
 				displayName,
 				barStr,
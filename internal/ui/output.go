@@ -0,0 +1,67 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats supported by --output
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+	OutputYAML = "yaml"
+)
+
+var outputFormat = OutputText
+
+// Out receives human-readable output: messages, tables, progress and the output
+// of child processes. It is stdout, or stderr with a structured format so that
+// stdout only carries the result document.
+var Out io.Writer = os.Stdout
+
+// SetOutputFormat configures how command results are emitted.
+// For structured formats (json, yaml) human-readable messages are redirected to stderr
+// and progress bars/spinners are disabled, so stdout only carries the document.
+func SetOutputFormat(format string) error {
+	format = strings.ToLower(format)
+	switch format {
+	case "", OutputText:
+		outputFormat = OutputText
+		Out = os.Stdout
+		return nil
+	case OutputJSON, OutputYAML:
+		outputFormat = format
+	default:
+		return fmt.Errorf("unsupported output format: %s (use text, json or yaml)", format)
+	}
+
+	Out = os.Stderr
+	return nil
+}
+
+// IsStructured reports whether results should be printed as JSON or YAML
+func IsStructured() bool {
+	return outputFormat != OutputText
+}
+
+// PrintResult writes v to stdout in the configured structured format
+func PrintResult(v interface{}) error {
+	switch outputFormat {
+	case OutputJSON:
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	case OutputYAML:
+		encoder := yaml.NewEncoder(os.Stdout)
+		encoder.SetIndent(2)
+		defer encoder.Close()
+		return encoder.Encode(v)
+	default:
+		return fmt.Errorf("PrintResult called with text output")
+	}
+}
@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetOutputFormat(t *testing.T) {
+	defer SetOutputFormat(OutputText)
+
+	tests := []struct {
+		format     string
+		structured bool
+		err        bool
+	}{
+		{format: "", structured: false},
+		{format: "text", structured: false},
+		{format: "json", structured: true},
+		{format: "YAML", structured: true},
+		{format: "xml", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			SetOutputFormat(OutputText)
+			err := SetOutputFormat(tt.format)
+			if (err != nil) != tt.err {
+				t.Fatalf("err = %v, want error %v", err, tt.err)
+			}
+			if tt.err {
+				return
+			}
+			if IsStructured() != tt.structured {
+				t.Errorf("IsStructured() = %v, want %v", IsStructured(), tt.structured)
+			}
+			// Messages must stay off stdout when it carries the document
+			wantOut := os.Stdout
+			if tt.structured {
+				wantOut = os.Stderr
+			}
+			if Out != wantOut {
+				t.Errorf("Out is not %s", wantOut.Name())
+			}
+		})
+	}
+}
+
+func TestPrintResult(t *testing.T) {
+	defer SetOutputFormat(OutputText)
+	result := struct {
+		Command string `json:"command" yaml:"command"`
+		Success bool   `json:"success" yaml:"success"`
+	}{"list", true}
+
+	tests := []struct {
+		format string
+		want   string
+		err    bool
+	}{
+		{format: OutputJSON, want: "{\n  \"command\": \"list\",\n  \"success\": true\n}\n"},
+		{format: OutputYAML, want: "command: list\nsuccess: true\n"},
+		{format: OutputText, err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			stdout, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer stdout.Close()
+
+			saved := os.Stdout
+			os.Stdout = stdout
+			SetOutputFormat(tt.format)
+			err = PrintResult(result)
+			os.Stdout = saved
+
+			if (err != nil) != tt.err {
+				t.Fatalf("err = %v, want error %v", err, tt.err)
+			}
+			data, _ := os.ReadFile(stdout.Name())
+			if string(data) != tt.want {
+				t.Errorf("stdout = %q, want %q", data, tt.want)
+			}
+		})
+	}
+}
@@ -21,11 +21,15 @@ func (wc *WriteCounter) Write(p []byte) (int, error) {
 }
 
 func (wc *WriteCounter) PrintProgress() {
+	if IsStructured() {
+		return
+	}
+
 	// Handle unknown content length
 	if wc.Total == 0 || wc.Total > 1024*1024*1024*1024 { // >1TB likely invalid
 		mbRead := float64(wc.Read) / 1024 / 1024
 		status := InfoStyle.Render("↓")
-		fmt.Printf("\r%s Downloading... %.1f MB", status, mbRead)
+		fmt.Fprintf(Out, "\r%s Downloading... %.1f MB", status, mbRead)
 		return
 	}
 
@@ -78,8 +82,8 @@ func (wc *WriteCounter) PrintProgress() {
 
 	// Add newline when complete
 	if percent >= 1.0 {
-		fmt.Printf("\r%s [%s] %s %s\n", status, barStr, coloredPercent, coloredSize)
+		fmt.Fprintf(Out, "\r%s [%s] %s %s\n", status, barStr, coloredPercent, coloredSize)
 	} else {
-		fmt.Printf("\r%s [%s] %s %s", status, barStr, coloredPercent, coloredSize)
+		fmt.Fprintf(Out, "\r%s [%s] %s %s", status, barStr, coloredPercent, coloredSize)
 	}
 }
@@ -25,11 +25,14 @@ func NewSpinner(message string) *Spinner {
 
 // Start begins the spinner animation
 func (s *Spinner) Start() {
+	if IsStructured() {
+		return
+	}
 	s.active = true
 	go func() {
 		for s.active {
 			frame := InfoStyle.Render(s.frames[s.index])
-			fmt.Printf("\r%s %s", frame, s.msg)
+			fmt.Fprintf(Out, "\r%s %s", frame, s.msg)
 			s.index = (s.index + 1) % len(s.frames)
 			time.Sleep(80 * time.Millisecond)
 		}
@@ -38,9 +41,12 @@ func (s *Spinner) Start() {
 
 // Stop halts the spinner and clears the line
 func (s *Spinner) Stop() {
+	if !s.active {
+		return
+	}
 	s.active = false
 	time.Sleep(100 * time.Millisecond) // Give goroutine time to finish
-	fmt.Print("\r\033[K")              // Clear the line
+	fmt.Fprint(Out, "\r\033[K")        // Clear the line
 }
 
 // Success stops the spinner and shows a success message
@@ -102,9 +102,9 @@ func CreateStatusBadge(status string) string {
 	switch status {
 	case "INSTALLED", "OK", "SUCCESS":
 		return SuccessBadge.Render(status)
-	case "MISSING", "ERROR", "FAILED":
+	case "MISSING", "ERROR", "FAILED", "INCOMPATIBLE":
 		return ErrorBadge.Render(status)
-	case "OUTDATED", "WARNING", "PENDING":
+	case "OUTDATED", "WARNING", "PENDING", "UNMANAGED", "CHANGED":
 		return WarningBadge.Render(status)
 	default:
 		return InfoBadge.Render(status)
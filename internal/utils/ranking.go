@@ -0,0 +1,148 @@
+package utils
+
+import (
+	"math"
+	"strings"
+)
+
+// RankInput describes a search candidate to score against a query
+type RankInput struct {
+	Query         string
+	Name          string // display name / title
+	Slug          string // project slug (Modrinth slug or Hangar slug)
+	Downloads     int
+	Compatible    bool // runs on the target platform
+	ExactPlatform bool // built specifically for the target platform
+}
+
+// Score weights
+const (
+	weightSimilarity = 0.5
+	weightExact      = 1.0
+	weightPrefix     = 0.3
+	weightTokens     = 0.2
+	weightPopularity = 0.3
+	weightPlatform   = 0.15
+	weightCompatible = 0.05
+	penaltyPlatform  = 0.3
+)
+
+// RelevanceScore rates how well a candidate matches a query (higher is better).
+// It combines normalized edit distance on name and slug, exact/prefix/token matches,
+// download counts (log scale) and platform compatibility.
+func RelevanceScore(in RankInput) float64 {
+	query := NormalizeName(in.Query)
+	name := NormalizeName(in.Name)
+	slug := NormalizeName(in.Slug)
+
+	score := weightSimilarity * math.Max(Similarity(query, name), Similarity(query, slug))
+
+	if IsExactMatch(in.Query, in.Name, in.Slug) {
+		score += weightExact
+	} else if query != "" && (strings.HasPrefix(name, query) || strings.HasPrefix(slug, query)) {
+		score += weightPrefix
+	}
+
+	if tokensMatch(in.Query, in.Name) || tokensMatch(in.Query, in.Slug) {
+		score += weightTokens
+	}
+
+	// 10M downloads saturates the popularity bonus
+	popularity := math.Log10(float64(in.Downloads)+1) / 7
+	score += weightPopularity * math.Min(popularity, 1)
+
+	switch {
+	case in.ExactPlatform:
+		score += weightPlatform
+	case in.Compatible:
+		score += weightCompatible
+	default:
+		score -= penaltyPlatform
+	}
+
+	return score
+}
+
+// Similarity returns 1 - normalized Levenshtein distance (1 means identical)
+func Similarity(a, b string) float64 {
+	longest := len(a)
+	if len(b) > longest {
+		longest = len(b)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(LevenshteinDistance(a, b))/float64(longest)
+}
+
+// IsExactMatch reports whether query names the candidate exactly (ignoring case and punctuation)
+func IsExactMatch(query, name, slug string) bool {
+	q := NormalizeName(query)
+	return q != "" && (q == NormalizeName(name) || q == NormalizeName(slug))
+}
+
+// NormalizeName lowercases s and keeps only letters and digits,
+// so "Geyser-Spigot", "geyser spigot" and "GeyserSpigot" compare equal
+func NormalizeName(s string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// tokensMatch reports whether every word of the query appears in the candidate
+func tokensMatch(query, candidate string) bool {
+	queryTokens := tokenize(query)
+	if len(queryTokens) == 0 {
+		return false
+	}
+
+	candidateTokens := make(map[string]bool)
+	for _, t := range tokenize(candidate) {
+		candidateTokens[t] = true
+	}
+
+	for _, t := range queryTokens {
+		if !candidateTokens[t] {
+			return false
+		}
+	}
+	return true
+}
+
+// tokenize splits on non-alphanumerics and camelCase boundaries ("ViaVersion" -> via, version)
+func tokenize(s string) []string {
+	var tokens []string
+	var current strings.Builder
+	var prevLower bool
+
+	flush := func() {
+		if current.Len() > 0 {
+			tokens = append(tokens, strings.ToLower(current.String()))
+			current.Reset()
+		}
+	}
+
+	for _, r := range s {
+		isUpper := r >= 'A' && r <= 'Z'
+		isLower := r >= 'a' && r <= 'z'
+		isDigit := r >= '0' && r <= '9'
+
+		if !isUpper && !isLower && !isDigit {
+			flush()
+			prevLower = false
+			continue
+		}
+		if isUpper && prevLower {
+			flush()
+		}
+		current.WriteRune(r)
+		prevLower = isLower || isDigit
+	}
+	flush()
+
+	return tokens
+}
@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Geyser-Spigot", "geyserspigot"},
+		{"geyser spigot", "geyserspigot"},
+		{"GeyserSpigot", "geyserspigot"},
+		{"EssentialsX 2.0", "essentialsx20"},
+		{"---", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeName(tt.in); got != tt.want {
+			t.Errorf("NormalizeName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestIsExactMatch(t *testing.T) {
+	tests := []struct {
+		query, name, slug string
+		want              bool
+	}{
+		{"luckperms", "LuckPerms", "luckperms", true},
+		{"Luck Perms", "LuckPerms", "lp", true},
+		{"luckperms", "LP", "luck-perms", true},
+		{"luck", "LuckPerms", "luckperms", false},
+		{"", "", "", false},
+		{"!!", "", "", false},
+	}
+	for _, tt := range tests {
+		if got := IsExactMatch(tt.query, tt.name, tt.slug); got != tt.want {
+			t.Errorf("IsExactMatch(%q, %q, %q) = %v, want %v", tt.query, tt.name, tt.slug, got, tt.want)
+		}
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"ViaVersion", []string{"via", "version"}},
+		{"world-edit", []string{"world", "edit"}},
+		{"Plan2Go", []string{"plan2", "go"}},
+		{"ABC", []string{"abc"}},
+		{"  ", nil},
+	}
+	for _, tt := range tests {
+		if got := tokenize(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("tokenize(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRelevanceScore(t *testing.T) {
+	// Each case expects better to outrank worse
+	tests := []struct {
+		name          string
+		better, worse RankInput
+	}{
+		{
+			"exact name beats prefix",
+			RankInput{Query: "vault", Name: "Vault", Compatible: true},
+			RankInput{Query: "vault", Name: "VaultUnlocked", Compatible: true},
+		},
+		{
+			"prefix beats unrelated",
+			RankInput{Query: "luck", Name: "LuckPerms", Compatible: true},
+			RankInput{Query: "luck", Name: "PermissionsEx", Compatible: true},
+		},
+		{
+			"popular beats unknown",
+			RankInput{Query: "chat", Name: "ChatControl", Downloads: 1000000, Compatible: true},
+			RankInput{Query: "chat", Name: "ChatControl", Downloads: 10, Compatible: true},
+		},
+		{
+			"exact platform beats compatible",
+			RankInput{Query: "geyser", Name: "Geyser", ExactPlatform: true, Compatible: true},
+			RankInput{Query: "geyser", Name: "Geyser", Compatible: true},
+		},
+		{
+			"compatible beats incompatible",
+			RankInput{Query: "geyser", Name: "Geyser", Compatible: true},
+			RankInput{Query: "geyser", Name: "Geyser"},
+		},
+		{
+			"word match beats a distant name",
+			RankInput{Query: "world edit", Name: "WorldEdit Tools", Compatible: true},
+			RankInput{Query: "world edit", Name: "Worldguard", Compatible: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			better, worse := RelevanceScore(tt.better), RelevanceScore(tt.worse)
+			if better <= worse {
+				t.Errorf("score %.3f should be higher than %.3f", better, worse)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+)
+
+// CompareVersions compares dotted numeric versions such as 1.20.4, returning
+// -1, 0 or 1. Missing components count as 0 and a non-numeric suffix
+// (1.20.4-pre1, 1.21-R0.1) is ignored.
+func CompareVersions(a, b string) int {
+	pa, pb := versionParts(a), versionParts(b)
+	for len(pa) < len(pb) {
+		pa = append(pa, 0)
+	}
+	for len(pb) < len(pa) {
+		pb = append(pb, 0)
+	}
+
+	for i := range pa {
+		if pa[i] < pb[i] {
+			return -1
+		}
+		if pa[i] > pb[i] {
+			return 1
+		}
+	}
+	return 0
+}
+
+func versionParts(version string) []int {
+	var parts []int
+	for _, field := range strings.Split(strings.TrimSpace(version), ".") {
+		end := 0
+		for end < len(field) && field[end] >= '0' && field[end] <= '9' {
+			end++
+		}
+		n, err := strconv.Atoi(field[:end])
+		if err != nil {
+			break
+		}
+		parts = append(parts, n)
+		if end < len(field) {
+			break // Suffix such as -pre1 ends the numeric part
+		}
+	}
+	return parts
+}
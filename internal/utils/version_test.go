@@ -0,0 +1,33 @@
+package utils
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.20.4", "1.20.4", 0},
+		{"1.20", "1.20.0", 0},
+		{"1.20.4", "1.20.10", -1},
+		{"1.21", "1.20.6", 1},
+		{"1.9", "1.10", -1},
+		{"1.20.4-pre1", "1.20.4", 0},
+		{"1.21-R0.1-SNAPSHOT", "1.21", 0},
+		{" 1.20 ", "1.20", 0},
+		{"26.1", "1.21.11", 1},
+		{"1.13", "1", 1},
+		{"", "1.0", -1},
+		{"", "0", 0},
+		{"abc", "", 0},
+	}
+	for _, tt := range tests {
+		if got := CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		// The comparison must be antisymmetric
+		if got := CompareVersions(tt.b, tt.a); got != -tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.b, tt.a, got, -tt.want)
+		}
+	}
+}